@@ -0,0 +1,55 @@
+// api/buildinfo/buildinfo.go
+// Pacote buildinfo identifica a versão do código em execução, exposta em /version.
+package buildinfo
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// Commit e BuildTime podem ser definidos na compilação:
+//
+//	go build -ldflags "-X college_api/buildinfo.Commit=$(git rev-parse HEAD) -X college_api/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Sem eles, são usados os dados de controle de versão gravados pelo go build ou, na Vercel,
+// VERCEL_GIT_COMMIT_SHA.
+var (
+	Commit    string
+	BuildTime string
+)
+
+// unknown é o valor devolvido quando a informação não está disponível.
+const unknown = "unknown"
+
+// Info descreve o código em execução.
+type Info struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get devolve as informações de compilação, com "unknown" nos campos que não puderam ser descobertos.
+func Get() Info {
+	info := Info{Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = os.Getenv("VERCEL_GIT_COMMIT_SHA")
+	}
+	if info.Commit == "" {
+		info.Commit = unknown
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = unknown
+	}
+	return info
+}
@@ -0,0 +1,57 @@
+// api/cache/cache.go
+// Pacote cache oferece o cache de leitura dos serviços: uma interface que pode ser implementada
+// por outros backends, a implementação padrão em memória (LRU com TTL) e a leitura com carga sob demanda.
+package cache
+
+import (
+	"college_api/metrics"
+	"context"
+)
+
+// Cache guarda valores por chave. As implementações devem ser seguras para uso concorrente e podem
+// descartar entradas a qualquer momento (por tamanho ou tempo de vida); Get então devolve false.
+// Os valores guardados não devem ser alterados por quem os lê.
+type Cache interface {
+	Get(ctx context.Context, key string) (any, bool)
+	Set(ctx context.Context, key string, value any)
+	// Generation devolve a geração de key, que muda sempre que key é invalidada (Delete ou Clear).
+	Generation(ctx context.Context, key string) uint64
+	// SetIfGeneration guarda value em key só se a geração de key ainda for gen, isto é, se key não foi
+	// invalidada desde que gen foi lida. Informa se o valor foi guardado.
+	SetIfGeneration(ctx context.Context, key string, value any, gen uint64) bool
+	// Delete descarta a entrada de key.
+	Delete(ctx context.Context, key string)
+	// Clear descarta todas as entradas (usado na invalidação depois de escritas).
+	Clear(ctx context.Context)
+}
+
+// ReadThrough devolve o valor de key em c ou, na falta dele, carrega-o com load e o guarda.
+// Erros não são guardados, nem resultados que load marque como não encontrados (found false),
+// para que um registro criado em seguida apareça imediatamente. name identifica o cache nas métricas
+// de acertos e falhas. Um c nil (cache desabilitado) sempre chama load.
+// Uma carga lenta pode terminar depois de uma escrita ter invalidado o cache; por isso a geração de key
+// é lida antes de load e o valor só é guardado se ela não mudou, para não repor um valor antigo.
+func ReadThrough[V any](ctx context.Context, c Cache, name, key string, load func(context.Context) (V, bool, error)) (v V, found bool, err error) {
+	if c == nil {
+		return load(ctx)
+	}
+	if cached, ok := c.Get(ctx, key); ok {
+		if v, ok := cached.(V); ok {
+			metrics.CacheRequests.WithLabelValues(name, "hit").Inc()
+			return v, true, nil
+		}
+	}
+	metrics.CacheRequests.WithLabelValues(name, "miss").Inc()
+	gen := c.Generation(ctx, key)
+	if v, found, err = load(ctx); err == nil && found {
+		c.SetIfGeneration(ctx, key, v, gen)
+	}
+	return v, found, err
+}
+
+// Invalidate descarta as entradas de c depois de uma escrita. Um c nil é ignorado.
+func Invalidate(ctx context.Context, c Cache) {
+	if c != nil {
+		c.Clear(ctx)
+	}
+}
@@ -0,0 +1,76 @@
+// api/cache/cache_test.go
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// slowLoad devolve uma carga que avisa em started quando começa e só termina quando release é fechado,
+// simulando uma consulta lenta que lê o valor antigo antes de uma escrita.
+func slowLoad(value string, started chan<- struct{}, release <-chan struct{}) func(context.Context) (string, bool, error) {
+	return func(context.Context) (string, bool, error) {
+		close(started)
+		<-release
+		return value, true, nil
+	}
+}
+
+func TestReadThroughSkipsSetAfterInvalidation(t *testing.T) {
+	ctx := context.Background()
+	for name, invalidate := range map[string]func(c *LRU){
+		"Clear":  func(c *LRU) { Invalidate(ctx, c) },
+		"Delete": func(c *LRU) { c.Delete(ctx, "id:1") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := NewLRU(10, time.Minute)
+			started, release := make(chan struct{}), make(chan struct{})
+			done := make(chan string)
+			go func() {
+				v, _, _ := ReadThrough(ctx, c, "test", "id:1", slowLoad("antigo", started, release))
+				done <- v
+			}()
+
+			<-started
+			invalidate(c) // Escrita concluída enquanto a carga ainda está no banco
+			close(release)
+			if v := <-done; v != "antigo" {
+				t.Fatalf("a leitura em andamento deveria devolver o que carregou, veio %q", v)
+			}
+			if v, ok := c.Get(ctx, "id:1"); ok {
+				t.Fatalf("o valor carregado antes da invalidação não deveria ser guardado, veio %v", v)
+			}
+
+			// Uma leitura depois da escrita volta a guardar
+			v, _, _ := ReadThrough(ctx, c, "test", "id:1", func(context.Context) (string, bool, error) { return "novo", true, nil })
+			if cached, ok := c.Get(ctx, "id:1"); !ok || cached != "novo" || v != "novo" {
+				t.Fatalf("esperava %q guardado, veio %v (%v)", "novo", cached, ok)
+			}
+		})
+	}
+}
+
+func TestLRUGenerationAdvancesOnEveryInvalidation(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10, time.Minute)
+	seen := map[uint64]bool{c.Generation(ctx, "a"): true}
+	for _, invalidate := range []func(){
+		func() { c.Delete(ctx, "a") },
+		func() { c.Delete(ctx, "a") },
+		func() { c.Clear(ctx) }, // Zera as contagens por chave sem repetir uma geração já vista
+		func() { c.Delete(ctx, "a") },
+	} {
+		invalidate()
+		gen := c.Generation(ctx, "a")
+		if seen[gen] {
+			t.Fatalf("geração %d repetida depois de uma invalidação", gen)
+		}
+		seen[gen] = true
+	}
+	gen := c.Generation(ctx, "b")
+	c.Delete(ctx, "a")
+	if !c.SetIfGeneration(ctx, "b", 1, gen) {
+		t.Error("invalidar a não deveria afetar a geração de b")
+	}
+}
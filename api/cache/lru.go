@@ -0,0 +1,130 @@
+// api/cache/lru.go
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU é um Cache em memória, do processo, com número máximo de entradas e tempo de vida: ao
+// atingir o limite descarta a entrada usada há mais tempo, e entradas vencidas nunca são devolvidas.
+// Cada instância da API tem o seu, então escritas feitas por outra instância ou pela collegectl só
+// aparecem depois do TTL.
+type LRU struct {
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // Frente: usada mais recentemente
+	entries map[string]*list.Element
+	// A geração de uma chave é epoch + deleted[chave]: Delete incrementa a da chave e Clear avança
+	// epoch além de todas, zerando deleted (que assim só cresce até o próximo Clear).
+	epoch   uint64
+	deleted map[string]uint64
+}
+
+type lruEntry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+// NewLRU cria um LRU com até maxEntries entradas, cada uma válida por ttl.
+func NewLRU(maxEntries int, ttl time.Duration) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		now:        time.Now,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		deleted:    make(map[string]uint64),
+	}
+}
+
+// Get devolve o valor de key, se existir e não tiver vencido, e o marca como usado.
+func (c *LRU) Get(_ context.Context, key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set guarda value em key por ttl, descartando a entrada usada há mais tempo se o limite for atingido.
+func (c *LRU) Set(_ context.Context, key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, value)
+}
+
+// Generation devolve a geração de key.
+func (c *LRU) Generation(_ context.Context, key string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.epoch + c.deleted[key]
+}
+
+// SetIfGeneration guarda value em key como Set, se key não foi invalidada desde a geração gen.
+func (c *LRU) SetIfGeneration(_ context.Context, key string, value any, gen uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.epoch+c.deleted[key] != gen {
+		return false
+	}
+	c.set(key, value)
+	return true
+}
+
+// Delete descarta a entrada de key e avança sua geração.
+func (c *LRU) Delete(_ context.Context, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	c.deleted[key]++
+}
+
+func (c *LRU) set(key string, value any) {
+	expires := c.now().Add(c.ttl)
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value, entry.expires = value, expires
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// Clear descarta todas as entradas e avança a geração de todas as chaves.
+func (c *LRU) Clear(_ context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+	var highest uint64
+	for _, n := range c.deleted {
+		highest = max(highest, n)
+	}
+	c.epoch += highest + 1
+	clear(c.deleted)
+}
+
+func (c *LRU) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry).key)
+}
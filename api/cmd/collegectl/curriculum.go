@@ -0,0 +1,130 @@
+// cmd/collegectl/curriculum.go
+package main
+
+import (
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/services"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// runCurriculum trata "collegectl curriculum sync [--prune] [--dry-run] [--json] ARQUIVO".
+func runCurriculum(args []string) error {
+	if len(args) == 0 || args[0] != "sync" {
+		return errUsage{"uso: collegectl curriculum sync [--prune] [--dry-run] [--json] ARQUIVO"}
+	}
+	fs := flag.NewFlagSet("curriculum sync", flag.ContinueOnError)
+	prune := fs.Bool("prune", false, "exclui as matérias que não estão na grade (recusa se alguma tiver alunos)")
+	dryRun := fs.Bool("dry-run", false, "apenas mostra o diff, sem gravar")
+	asJSON := fs.Bool("json", false, "mostra o diff em JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Uso: collegectl curriculum sync [opções] ARQUIVO (.yaml, .yml ou .json; \"-\" lê da entrada padrão)")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage{"informe exatamente um arquivo"}
+	}
+
+	path := fs.Arg(0)
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	format := "yaml"
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = "json"
+	}
+	curriculum, err := services.ParseCurriculum(data, format)
+	if err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	service := services.NewSubjectService(repositories.NewSubjectRepository(db), nil)
+	report, err := service.SyncCurriculum(ctx, curriculum, services.CurriculumSyncOptions{Prune: *prune, DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printCurriculumDiff(os.Stdout, report)
+	}
+	if len(report.Blocked) > 0 && !*dryRun {
+		return fmt.Errorf("--prune recusado: %d matéria(s) fora da grade ainda têm alunos associados ou estão na grade de cursos; nada foi gravado", len(report.Blocked))
+	}
+	return nil
+}
+
+// printCurriculumDiff mostra o diff no estilo de um diff de texto (+ nova, ~ alterada, - removida).
+func printCurriculumDiff(w io.Writer, report *models.CurriculumSyncReport) {
+	for _, s := range report.Added {
+		fmt.Fprintf(w, "+ %s  %s (ano %d, %d créditos)\n", s.ID, s.Name, s.Year, s.Credits)
+	}
+	for _, s := range report.Restored {
+		fmt.Fprintf(w, "+ %s  %s (ano %d, %d créditos) [restaurada]\n", s.ID, s.Name, s.Year, s.Credits)
+	}
+	for _, c := range report.Changed {
+		fields := make([]string, 0, len(c.Changes))
+		for field := range c.Changes {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			fmt.Fprintf(w, "~ %s  %s: %v -> %v\n", c.ID, field, c.Changes[field].From, c.Changes[field].To)
+		}
+	}
+	blocked := map[string]models.SubjectReference{}
+	for _, b := range report.Blocked {
+		blocked[b.ID] = b
+	}
+	for _, s := range report.Removed {
+		b, isBlocked := blocked[s.ID]
+		switch {
+		case !report.Prune:
+			fmt.Fprintf(w, "? %s  %s (fora da grade; use --prune para excluir)\n", s.ID, s.Name)
+		case isBlocked:
+			var reasons []string
+			if b.Students > 0 {
+				reasons = append(reasons, fmt.Sprintf("com %d aluno(s) associado(s)", b.Students))
+			}
+			if len(b.Programs) > 0 {
+				reasons = append(reasons, "na grade dos cursos "+strings.Join(b.Programs, ", "))
+			}
+			fmt.Fprintf(w, "! %s  %s (fora da grade, mas %s)\n", s.ID, s.Name, strings.Join(reasons, " e "))
+		default:
+			fmt.Fprintf(w, "- %s  %s\n", s.ID, s.Name)
+		}
+	}
+
+	status := "aplicado"
+	if report.DryRun {
+		status = "simulação, nada foi gravado"
+	} else if !report.Applied {
+		status = "não aplicado"
+	}
+	fmt.Fprintf(w, "%d nova(s), %d restaurada(s), %d alterada(s), %d fora da grade, %d sem alteração (%s)\n",
+		len(report.Added), len(report.Restored), len(report.Changed), len(report.Removed), report.Unchanged, status)
+}
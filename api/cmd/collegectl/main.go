@@ -0,0 +1,135 @@
+// cmd/collegectl/main.go
+// collegectl administra a universidade direto pelo banco de dados (sem passar pela API HTTP).
+// Usa a mesma DATABASE_URL da API.
+package main
+
+import (
+	"college_api/config"
+	"college_api/logging"
+	"college_api/repositories"
+	"college_api/requestctx"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+)
+
+const usage = `Uso: collegectl [opções globais] <comando> [opções]
+
+Comandos:
+  students <list|get|create|update|delete|add-subject|remove-subject|status|history>
+  subjects <list|get|create|update|delete>
+  teachers <list|get|create|update|delete>
+  curriculum sync ARQUIVO   Sincroniza o catálogo de matérias com uma grade curricular (YAML ou JSON)
+  rollover <run|list|undo>  Virada de ano letivo (promoção dos alunos), com prévia e desfazer
+  seed [-seed N]            Popula um banco vazio com dados de demonstração reproduzíveis
+  backup [-o ARQUIVO]       Grava um backup portável (JSON lines) de todas as tabelas
+  restore ARQUIVO           Restaura um backup em um banco vazio
+
+Use "collegectl <comando> <subcomando> -h" para ver as opções de cada um.
+Os comandos de consulta e alteração aceitam -format table (padrão), json ou csv.
+A configuração (incluindo a DATABASE_URL) é a mesma da API: variáveis de ambiente, arquivo .env
+ou as opções globais abaixo, que têm precedência.
+
+Opções globais:
+`
+
+// appConfig é a configuração carregada em main; configErr guarda os problemas encontrados, relatados
+// só quando um comando precisa do banco (a ajuda funciona sem configuração).
+var (
+	appConfig *config.Config
+	configErr error
+)
+
+// db é o pool de conexões aberto por cliContext, usado na montagem dos serviços.
+var db *sql.DB
+
+// printUsage escreve o uso, incluindo as opções globais de configuração.
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, usage)
+	config.Usage(w)
+}
+
+func main() {
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		printUsage(os.Stdout)
+		return
+	}
+	if cfg == nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n\n", err)
+		printUsage(os.Stderr)
+		os.Exit(2)
+	}
+	appConfig, configErr = cfg, err
+	if len(args) < 1 {
+		printUsage(os.Stderr)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "students":
+		err = runStudents(args[1:])
+	case "subjects":
+		err = runSubjects(args[1:])
+	case "teachers":
+		err = runTeachers(args[1:])
+	case "curriculum":
+		err = runCurriculum(args[1:])
+	case "rollover":
+		err = runRollover(args[1:])
+	case "seed":
+		err = runSeed(args[1:])
+	case "backup":
+		err = runBackup(args[1:])
+	case "restore":
+		err = runRestore(args[1:])
+	case "help":
+		printUsage(os.Stdout)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "Comando desconhecido: %s\n\n", args[0])
+		printUsage(os.Stderr)
+		os.Exit(2)
+	}
+	var usageErr errUsage
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+	case errors.As(err, &usageErr):
+		fmt.Fprintln(os.Stderr, usageErr.msg)
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, "Erro:", err)
+		os.Exit(1)
+	}
+}
+
+// cliContext configura o log (LOG_FORMAT e LOG_LEVEL, na saída de erro), abre a conexão com o banco e
+// devolve o contexto das operações, identificando o usuário do sistema operacional como ator na auditoria.
+func cliContext() context.Context {
+	if configErr != nil {
+		fmt.Fprintf(os.Stderr, "Configuração inválida:\n%v\n", configErr)
+		os.Exit(2)
+	}
+	logging.Setup(appConfig.Log)
+	var err error
+	if db, err = config.OpenDBWithRetry(appConfig.DB); err != nil {
+		fmt.Fprintln(os.Stderr, "Erro:", err)
+		os.Exit(1)
+	}
+	repositories.SetQueryTimeout(appConfig.DB.QueryTimeout)
+	name := "desconhecido"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	return requestctx.WithPrincipal(context.Background(), requestctx.Principal{ID: name, Kind: "cli"})
+}
+
+// errUsage sinaliza erro de uso (argumentos inválidos); o programa termina com código 2.
+type errUsage struct{ msg string }
+
+func (e errUsage) Error() string { return e.msg }
@@ -0,0 +1,104 @@
+// cmd/collegectl/output.go
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Formatos de saída dos comandos de consulta e alteração.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// addFormatFlag registra a opção -format no FlagSet.
+func addFormatFlag(fs *flag.FlagSet) *string {
+	return fs.String("format", formatTable, "formato de saída: table, json ou csv")
+}
+
+// printRecords mostra registros no formato pedido: v é serializado como está em JSON;
+// columns e rows são usados na tabela e no CSV.
+func printRecords(format string, v interface{}, columns []string, rows [][]string) error {
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case formatCSV:
+		w := csv.NewWriter(os.Stdout)
+		w.Write(columns)
+		w.WriteAll(rows) // WriteAll já faz o Flush
+		return w.Error()
+	case formatTable:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	}
+	return errUsage{fmt.Sprintf("formato de saída inválido: %q (use table, json ou csv)", format)}
+}
+
+// parseArgs interpreta as opções aceitando-as antes ou depois dos argumentos posicionais
+// (ex: "students update ID -name X"), que são devolvidos em ordem, e valida o -format.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	// O formato é conferido antes de qualquer alteração no banco
+	if f := fs.Lookup("format"); f != nil {
+		switch f.Value.String() {
+		case formatTable, formatJSON, formatCSV:
+		default:
+			return nil, errUsage{fmt.Sprintf("formato de saída inválido: %q (use table, json ou csv)", f.Value.String())}
+		}
+	}
+	return positional, nil
+}
+
+// expectArgs confere a quantidade de argumentos posicionais.
+func expectArgs(fs *flag.FlagSet, positional []string, names ...string) error {
+	if len(positional) != len(names) {
+		fs.Usage()
+		return errUsage{fmt.Sprintf("argumentos esperados: %s", strings.Join(names, " "))}
+	}
+	return nil
+}
+
+// flagWasSet informa se a opção foi passada na linha de comando (para atualizações parciais).
+func flagWasSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// newFlagSet cria o FlagSet de um subcomando com a linha de uso informada.
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Uso: collegectl "+usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
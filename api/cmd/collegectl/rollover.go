@@ -0,0 +1,134 @@
+// cmd/collegectl/rollover.go
+package main
+
+import (
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/services"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const rolloverUsage = `uso: collegectl rollover <run|list|undo> [opções]`
+
+// runRollover trata os subcomandos da virada de ano letivo.
+func runRollover(args []string) error {
+	if len(args) == 0 {
+		return errUsage{rolloverUsage}
+	}
+	switch args[0] {
+	case "run":
+		return rolloverRun(args[1:])
+	case "list":
+		return rolloverList(args[1:])
+	case "undo":
+		return rolloverUndo(args[1:])
+	}
+	return errUsage{rolloverUsage}
+}
+
+func newRolloverService(undoWindow time.Duration) *services.RolloverService {
+	return services.NewRolloverService(repositories.NewStudentRepository(db), repositories.NewRolloverRepository(db), undoWindow)
+}
+
+func rolloverRun(args []string) error {
+	fs := newFlagSet("rollover run", "rollover run [-dry-run] [-min-share 0.75] [-final-year 4] [-undo-window 72h]")
+	criteria := services.DefaultRolloverCriteria
+	fs.Float64Var(&criteria.MinCreditShare, "min-share", criteria.MinCreditShare, "fração mínima dos créditos do ano atual para promoção (0 a 1)")
+	fs.IntVar(&criteria.FinalYear, "final-year", criteria.FinalYear, "último ano do curso")
+	dryRun := fs.Bool("dry-run", false, "apenas mostra a prévia, sem gravar")
+	undoWindow := fs.Duration("undo-window", services.DefaultRolloverUndoWindow, "por quanto tempo a virada pode ser desfeita")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	report, err := newRolloverService(*undoWindow).Run(ctx, criteria, *dryRun)
+	if err != nil {
+		return err
+	}
+	if *format == formatJSON {
+		return printRecords(*format, report, nil, nil)
+	}
+	rows := make([][]string, len(report.Students))
+	for i, s := range report.Students {
+		rows[i] = []string{s.Enrollment, s.Name, strconv.Itoa(s.FromYear), strconv.Itoa(s.ToYear),
+			fmt.Sprintf("%d/%d", s.EarnedCredits, s.TotalCredits), s.Outcome, s.Reason}
+	}
+	if err := printRecords(*format, report, []string{"enrollment", "name", "from_year", "to_year", "credits", "outcome", "reason"}, rows); err != nil {
+		return err
+	}
+	if *format == formatTable {
+		fmt.Printf("\n%d promovidos, %d formandos, %d retidos, %d ignorados.\n", report.Promoted, report.Graduating, report.Retained, report.Skipped)
+		if report.DryRun {
+			fmt.Println("Prévia: nada foi gravado.")
+		} else {
+			fmt.Printf("Virada %d gravada; pode ser desfeita até %s com \"collegectl rollover undo %d\".\n",
+				report.ID, report.UndoDeadline.Local().Format(time.RFC3339), report.ID)
+		}
+	}
+	return nil
+}
+
+func rolloverList(args []string) error {
+	fs := newFlagSet("rollover list", "rollover list [-limit 20]")
+	limit := fs.Int("limit", 20, "quantidade máxima de viradas")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	runs, err := newRolloverService(services.DefaultRolloverUndoWindow).ListRuns(ctx, *limit)
+	if err != nil {
+		return err
+	}
+	return printRollovers(*format, runs, runs...)
+}
+
+func rolloverUndo(args []string) error {
+	fs := newFlagSet("rollover undo", "rollover undo ID")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID"); err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(positional[0], 10, 64)
+	if err != nil {
+		return errUsage{fmt.Sprintf("ID de virada inválido: %s", positional[0])}
+	}
+
+	ctx := cliContext()
+	run, err := newRolloverService(services.DefaultRolloverUndoWindow).Undo(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printRollovers(*format, run, *run)
+}
+
+// printRollovers mostra viradas; v é o valor serializado em JSON (uma virada ou a lista).
+func printRollovers(format string, v interface{}, runs ...models.RolloverRun) error {
+	rows := make([][]string, len(runs))
+	for i, run := range runs {
+		undone := ""
+		if run.UndoneAt != nil {
+			undone = run.UndoneAt.Local().Format(time.RFC3339)
+		}
+		rows[i] = []string{strconv.FormatInt(run.ID, 10), run.ExecutedAt.Local().Format(time.RFC3339), run.Actor,
+			strconv.Itoa(run.Changes), run.UndoDeadline.Local().Format(time.RFC3339), undone}
+	}
+	return printRecords(format, v, []string{"id", "executed_at", "actor", "changes", "undo_deadline", "undone_at"}, rows)
+}
@@ -0,0 +1,41 @@
+// cmd/collegectl/seed.go
+package main
+
+import (
+	"college_api/repositories"
+	"college_api/seed"
+	"college_api/services"
+	"fmt"
+)
+
+// runSeed trata "collegectl seed [-seed N] [-students N] [-teachers N]".
+func runSeed(args []string) error {
+	fs := newFlagSet("seed", "seed [-seed N] [-students N] [-teachers N]")
+	var opts seed.Options
+	fs.Int64Var(&opts.Seed, "seed", seed.DefaultSeed, "semente do gerador (a mesma semente gera os mesmos dados)")
+	fs.IntVar(&opts.Students, "students", 200, "quantidade de alunos")
+	fs.IntVar(&opts.TeachersPerDepartment, "teachers", 3, "professores por departamento")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional); err != nil {
+		return err
+	}
+	if opts.Students < 0 || opts.TeachersPerDepartment < 0 {
+		return errUsage{"as quantidades não podem ser negativas"}
+	}
+
+	ctx := cliContext()
+	report, err := seed.Run(ctx, seed.Services{
+		Students:    newStudentService(),
+		Subjects:    newSubjectService(),
+		Teachers:    newTeacherService(),
+		Departments: services.NewDepartmentService(repositories.NewDepartmentRepository(db), repositories.NewTeacherRepository(db), nil),
+	}, opts)
+	if report != nil {
+		fmt.Printf("Semente %d: %d departamentos, %d matérias, %d professores, %d alunos, %d associações.\n",
+			report.Seed, report.Departments, report.Subjects, report.Teachers, report.Students, report.Associations)
+	}
+	return err
+}
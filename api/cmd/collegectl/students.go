@@ -0,0 +1,285 @@
+// cmd/collegectl/students.go
+package main
+
+import (
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/services"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const studentsUsage = `uso: collegectl students <list|get|create|update|delete|add-subject|remove-subject|status|history> [opções]`
+
+// runStudents trata os subcomandos de alunos.
+func runStudents(args []string) error {
+	if len(args) == 0 {
+		return errUsage{studentsUsage}
+	}
+	switch args[0] {
+	case "list":
+		return studentsList(args[1:])
+	case "get":
+		return studentsGet(args[1:])
+	case "create":
+		return studentsCreate(args[1:])
+	case "update":
+		return studentsUpdate(args[1:])
+	case "delete":
+		return studentsDelete(args[1:])
+	case "add-subject", "remove-subject":
+		return studentsSubject(args[0], args[1:])
+	case "status":
+		return studentsStatus(args[1:])
+	case "history":
+		return studentsHistory(args[1:])
+	}
+	return errUsage{studentsUsage}
+}
+
+func newStudentService() *services.StudentService {
+	return services.NewStudentService(repositories.NewStudentRepository(db), repositories.NewSubjectRepository(db), repositories.NewUnitOfWork(db), nil)
+}
+
+func studentsList(args []string) error {
+	fs := newFlagSet("students list", "students list [-name TRECHO] [-shift M|T|N] [-year N] [-subject ID] [-status SITUAÇÃO|all]")
+	var filter models.StudentFilter
+	fs.StringVar(&filter.Name, "name", "", "trecho do nome")
+	fs.StringVar(&filter.Shift, "shift", "", "turno (M, T ou N)")
+	fs.IntVar(&filter.CurrentYear, "year", 0, "ano atual")
+	fs.StringVar(&filter.SubjectID, "subject", "", "apenas alunos associados a esta matéria")
+	fs.StringVar(&filter.Status, "status", "", "situação (padrão: active; all lista todas)")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	students, err := newStudentService().GetAllStudents(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if students == nil {
+		students = []models.Student{}
+	}
+	return printStudents(*format, students, students...)
+}
+
+func studentsGet(args []string) error {
+	fs := newFlagSet("students get", "students get ID")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID"); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	student, err := newStudentService().GetStudentByID(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	if student == nil {
+		return fmt.Errorf("aluno %s não encontrado", positional[0])
+	}
+	return printStudents(*format, student, *student)
+}
+
+func studentsCreate(args []string) error {
+	fs := newFlagSet("students create", "students create -name NOME -shift M|T|N [-year N] [-subjects ID,ID]")
+	var student models.Student
+	fs.StringVar(&student.Name, "name", "", "nome completo")
+	fs.StringVar(&student.Shift, "shift", "", "turno (M, T ou N)")
+	fs.IntVar(&student.CurrentYear, "year", 1, "ano atual")
+	subjects := fs.String("subjects", "", "IDs de matérias separados por vírgula")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional); err != nil {
+		return err
+	}
+	for _, id := range splitList(*subjects) {
+		student.Subjects = append(student.Subjects, models.Subject{ID: id})
+	}
+
+	ctx := cliContext()
+	service := newStudentService()
+	if err := service.CreateStudent(ctx, &student); err != nil {
+		return err
+	}
+	created, err := service.GetStudentByID(ctx, student.ID)
+	if err != nil || created == nil {
+		return printStudents(*format, student, student)
+	}
+	return printStudents(*format, created, *created)
+}
+
+func studentsUpdate(args []string) error {
+	fs := newFlagSet("students update", "students update ID [-name NOME] [-shift M|T|N] [-year N] [-version N]")
+	name := fs.String("name", "", "novo nome")
+	shift := fs.String("shift", "", "novo turno (M, T ou N)")
+	year := fs.Int("year", 0, "novo ano atual")
+	version := fs.Int("version", 0, "só altera se a versão atual for esta (0 dispensa a verificação)")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID"); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	service := newStudentService()
+	student, err := service.GetStudentByID(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	if student == nil {
+		return fmt.Errorf("aluno %s não encontrado", positional[0])
+	}
+	if flagWasSet(fs, "name") {
+		student.Name = *name
+	}
+	if flagWasSet(fs, "shift") {
+		student.Shift = *shift
+	}
+	if flagWasSet(fs, "year") {
+		student.CurrentYear = *year
+	}
+	student.Version = *version
+	if err := service.UpdateStudent(ctx, student); err != nil {
+		return err
+	}
+	return printStudents(*format, student, *student)
+}
+
+func studentsDelete(args []string) error {
+	fs := newFlagSet("students delete", "students delete ID [-version N]")
+	version := fs.Int("version", 0, "só exclui se a versão atual for esta (0 dispensa a verificação)")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID"); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	if err := newStudentService().DeleteStudent(ctx, positional[0], *version); err != nil {
+		return err
+	}
+	fmt.Printf("Aluno %s excluído.\n", positional[0])
+	return nil
+}
+
+// studentsSubject trata add-subject e remove-subject.
+func studentsSubject(command string, args []string) error {
+	fs := newFlagSet("students "+command, "students "+command+" ID_DO_ALUNO ID_DA_MATERIA")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID_DO_ALUNO", "ID_DA_MATERIA"); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	service := newStudentService()
+	if command == "add-subject" {
+		if err := service.AddSubjectToStudent(ctx, positional[0], positional[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Matéria %s associada ao aluno %s.\n", positional[1], positional[0])
+		return nil
+	}
+	if err := service.RemoveSubjectFromStudent(ctx, positional[0], positional[1]); err != nil {
+		return err
+	}
+	fmt.Printf("Matéria %s removida do aluno %s.\n", positional[1], positional[0])
+	return nil
+}
+
+func studentsStatus(args []string) error {
+	fs := newFlagSet("students status", "students status ID -to SITUAÇÃO -reason MOTIVO [-date AAAA-MM-DD] [-version N]")
+	var change models.StudentStatusChange
+	fs.StringVar(&change.Status, "to", "", "nova situação (active, suspended, locked, graduated, withdrawn ou transferred)")
+	fs.StringVar(&change.Reason, "reason", "", "motivo da mudança")
+	fs.StringVar(&change.EffectiveDate, "date", "", "data de efetivação (padrão: hoje)")
+	version := fs.Int("version", 0, "só altera se a versão atual for esta (0 dispensa a verificação)")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID"); err != nil {
+		return err
+	}
+	if change.Status == "" {
+		return errUsage{"informe a nova situação com -to"}
+	}
+
+	ctx := cliContext()
+	student, _, err := newStudentService().ChangeStudentStatus(ctx, positional[0], change, *version)
+	if err != nil {
+		return err
+	}
+	return printStudents(*format, student, *student)
+}
+
+func studentsHistory(args []string) error {
+	fs := newFlagSet("students history", "students history ID")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID"); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	history, err := newStudentService().GetStudentStatusHistory(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(history))
+	for i, t := range history {
+		rows[i] = []string{t.EffectiveDate, t.FromStatus, t.ToStatus, t.Reason, t.Actor, t.RecordedAt.Local().Format(time.RFC3339)}
+	}
+	return printRecords(*format, history, []string{"effective_date", "from", "to", "reason", "actor", "recorded_at"}, rows)
+}
+
+// printStudents mostra alunos; v é o valor serializado em JSON (um aluno ou a lista).
+func printStudents(format string, v interface{}, students ...models.Student) error {
+	rows := make([][]string, len(students))
+	for i, s := range students {
+		ids := make([]string, len(s.Subjects))
+		for j, subject := range s.Subjects {
+			ids[j] = subject.ID
+		}
+		rows[i] = []string{s.ID, s.Enrollment, s.Name, strconv.Itoa(s.CurrentYear), s.Shift, s.Status, strings.Join(ids, ";"), strconv.Itoa(s.Version)}
+	}
+	return printRecords(format, v, []string{"id", "enrollment", "name", "current_year", "shift", "status", "subjects", "version"}, rows)
+}
+
+// splitList separa uma lista de valores por vírgula, ignorando itens vazios.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
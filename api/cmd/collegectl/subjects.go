@@ -0,0 +1,170 @@
+// cmd/collegectl/subjects.go
+package main
+
+import (
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/services"
+	"fmt"
+	"strconv"
+)
+
+const subjectsUsage = `uso: collegectl subjects <list|get|create|update|delete> [opções]`
+
+// runSubjects trata os subcomandos de matérias.
+func runSubjects(args []string) error {
+	if len(args) == 0 {
+		return errUsage{subjectsUsage}
+	}
+	switch args[0] {
+	case "list":
+		return subjectsList(args[1:])
+	case "get":
+		return subjectsGet(args[1:])
+	case "create":
+		return subjectsCreate(args[1:])
+	case "update":
+		return subjectsUpdate(args[1:])
+	case "delete":
+		return subjectsDelete(args[1:])
+	}
+	return errUsage{subjectsUsage}
+}
+
+// newSubjectService cria o serviço sem cache de leitura: cada comando é um processo de vida curta
+// e precisa ver as escritas feitas pela API na hora.
+func newSubjectService() *services.SubjectService {
+	return services.NewSubjectService(repositories.NewSubjectRepository(db), nil)
+}
+
+func subjectsList(args []string) error {
+	fs := newFlagSet("subjects list", "subjects list [-name TRECHO] [-year N]")
+	var filter models.SubjectFilter
+	fs.StringVar(&filter.Name, "name", "", "trecho do nome")
+	fs.IntVar(&filter.Year, "year", 0, "ano em que a matéria é oferecida")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	subjects, err := newSubjectService().GetAllSubjects(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if subjects == nil {
+		subjects = []models.Subject{}
+	}
+	return printSubjects(*format, subjects, subjects...)
+}
+
+func subjectsGet(args []string) error {
+	fs := newFlagSet("subjects get", "subjects get ID")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID"); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	subject, err := newSubjectService().GetSubjectByID(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return printSubjects(*format, subject, *subject)
+}
+
+func subjectsCreate(args []string) error {
+	fs := newFlagSet("subjects create", "subjects create -id ID -name NOME -year N -credits N")
+	var subject models.Subject
+	fs.StringVar(&subject.ID, "id", "", "código da matéria (ex: BSI101)")
+	fs.StringVar(&subject.Name, "name", "", "nome")
+	fs.IntVar(&subject.Year, "year", 0, "ano em que é oferecida")
+	fs.IntVar(&subject.Credits, "credits", 0, "créditos")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	if err := newSubjectService().CreateSubject(ctx, &subject); err != nil {
+		return err
+	}
+	return printSubjects(*format, subject, subject)
+}
+
+func subjectsUpdate(args []string) error {
+	fs := newFlagSet("subjects update", "subjects update ID [-name NOME] [-year N] [-credits N] [-version N]")
+	name := fs.String("name", "", "novo nome")
+	year := fs.Int("year", 0, "novo ano")
+	credits := fs.Int("credits", 0, "novos créditos")
+	version := fs.Int("version", 0, "só altera se a versão atual for esta (0 dispensa a verificação)")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID"); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	service := newSubjectService()
+	subject, err := service.GetSubjectByID(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	if flagWasSet(fs, "name") {
+		subject.Name = *name
+	}
+	if flagWasSet(fs, "year") {
+		subject.Year = *year
+	}
+	if flagWasSet(fs, "credits") {
+		subject.Credits = *credits
+	}
+	subject.Version = *version
+	if err := service.UpdateSubject(ctx, subject); err != nil {
+		return err
+	}
+	return printSubjects(*format, subject, *subject)
+}
+
+func subjectsDelete(args []string) error {
+	fs := newFlagSet("subjects delete", "subjects delete ID [-version N]")
+	version := fs.Int("version", 0, "só exclui se a versão atual for esta (0 dispensa a verificação)")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID"); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	if err := newSubjectService().DeleteSubject(ctx, positional[0], *version); err != nil {
+		return err
+	}
+	fmt.Printf("Matéria %s excluída.\n", positional[0])
+	return nil
+}
+
+// printSubjects mostra matérias; v é o valor serializado em JSON (uma matéria ou a lista).
+func printSubjects(format string, v interface{}, subjects ...models.Subject) error {
+	rows := make([][]string, len(subjects))
+	for i, s := range subjects {
+		rows[i] = []string{s.ID, s.Name, strconv.Itoa(s.Year), strconv.Itoa(s.Credits), strconv.Itoa(s.Version)}
+	}
+	return printRecords(format, v, []string{"id", "name", "year", "credits", "version"}, rows)
+}
@@ -0,0 +1,163 @@
+// cmd/collegectl/teachers.go
+package main
+
+import (
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/services"
+	"fmt"
+	"strconv"
+)
+
+const teachersUsage = `uso: collegectl teachers <list|get|create|update|delete> [opções]`
+
+// runTeachers trata os subcomandos de professores.
+func runTeachers(args []string) error {
+	if len(args) == 0 {
+		return errUsage{teachersUsage}
+	}
+	switch args[0] {
+	case "list":
+		return teachersList(args[1:])
+	case "get":
+		return teachersGet(args[1:])
+	case "create":
+		return teachersCreate(args[1:])
+	case "update":
+		return teachersUpdate(args[1:])
+	case "delete":
+		return teachersDelete(args[1:])
+	}
+	return errUsage{teachersUsage}
+}
+
+func newTeacherService() *services.TeacherService {
+	return services.NewTeacherService(repositories.NewTeacherRepository(db), repositories.NewDepartmentRepository(db), nil)
+}
+
+func teachersList(args []string) error {
+	fs := newFlagSet("teachers list", "teachers list [-name TRECHO] [-department CÓDIGO|NOME]")
+	var filter models.TeacherFilter
+	fs.StringVar(&filter.Name, "name", "", "trecho do nome")
+	fs.StringVar(&filter.Department, "department", "", "código ou nome do departamento")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	teachers, err := newTeacherService().GetAllTeachers(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if teachers == nil {
+		teachers = []models.Teacher{}
+	}
+	return printTeachers(*format, teachers, teachers...)
+}
+
+func teachersGet(args []string) error {
+	fs := newFlagSet("teachers get", "teachers get ID")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID"); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	teacher, err := newTeacherService().GetTeacherByID(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return printTeachers(*format, teacher, *teacher)
+}
+
+func teachersCreate(args []string) error {
+	fs := newFlagSet("teachers create", "teachers create -name NOME -department CÓDIGO|NOME")
+	var teacher models.Teacher
+	fs.StringVar(&teacher.Name, "name", "", "nome completo")
+	fs.StringVar(&teacher.Department, "department", "", "código ou nome do departamento")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	if err := newTeacherService().CreateTeacher(ctx, &teacher); err != nil {
+		return err
+	}
+	return printTeachers(*format, teacher, teacher)
+}
+
+func teachersUpdate(args []string) error {
+	fs := newFlagSet("teachers update", "teachers update ID [-name NOME] [-department CÓDIGO|NOME] [-version N]")
+	name := fs.String("name", "", "novo nome")
+	department := fs.String("department", "", "código ou nome do novo departamento")
+	version := fs.Int("version", 0, "só altera se a versão atual for esta (0 dispensa a verificação)")
+	format := addFormatFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID"); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	service := newTeacherService()
+	teacher, err := service.GetTeacherByID(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	if flagWasSet(fs, "name") {
+		teacher.Name = *name
+	}
+	if flagWasSet(fs, "department") {
+		teacher.DepartmentID = "" // O departamento passa a ser resolvido pelo código ou nome informado
+		teacher.Department = *department
+	}
+	teacher.Version = *version
+	if err := service.UpdateTeacher(ctx, teacher); err != nil {
+		return err
+	}
+	return printTeachers(*format, teacher, *teacher)
+}
+
+func teachersDelete(args []string) error {
+	fs := newFlagSet("teachers delete", "teachers delete ID [-version N]")
+	version := fs.Int("version", 0, "só exclui se a versão atual for esta (0 dispensa a verificação)")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, positional, "ID"); err != nil {
+		return err
+	}
+
+	ctx := cliContext()
+	if err := newTeacherService().DeleteTeacher(ctx, positional[0], *version); err != nil {
+		return err
+	}
+	fmt.Printf("Professor %s excluído.\n", positional[0])
+	return nil
+}
+
+// printTeachers mostra professores; v é o valor serializado em JSON (um professor ou a lista).
+func printTeachers(format string, v interface{}, teachers ...models.Teacher) error {
+	rows := make([][]string, len(teachers))
+	for i, t := range teachers {
+		rows[i] = []string{t.ID, t.Registry, t.Name, t.DepartmentID, t.Department, strconv.Itoa(t.Version)}
+	}
+	return printRecords(format, v, []string{"id", "registry", "name", "department_id", "department", "version"}, rows)
+}
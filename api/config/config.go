@@ -0,0 +1,375 @@
+// api/config/config.go
+package config
+
+import (
+	"college_api/logging"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Config reúne toda a configuração da API e do collegectl. É carregada uma vez na inicialização
+// (ver Load) e repassada a quem precisa, em vez de cada pacote ler variáveis de ambiente.
+type Config struct {
+	DB                 DBConfig
+	Log                logging.Config
+	CORS               CORSConfig
+	Auth               AuthConfig
+	RateLimit          RateLimitConfig
+	Cache              CacheConfig
+	PurgeRetention     time.Duration // Por quanto tempo registros excluídos podem ser restaurados antes do expurgo
+	RolloverUndoWindow time.Duration // Por quanto tempo uma virada de ano letivo pode ser desfeita
+	Features           FeatureConfig
+}
+
+// DBConfig configura a conexão com o PostgreSQL e o pool de conexões.
+type DBConfig struct {
+	URL             string
+	MaxOpenConns    int           // Máximo de conexões abertas (0: sem limite)
+	MaxIdleConns    int           // Máximo de conexões ociosas mantidas no pool
+	ConnMaxLifetime time.Duration // Tempo máximo de vida de uma conexão (0: sem limite)
+	ConnMaxIdleTime time.Duration // Tempo máximo que uma conexão fica ociosa (0: sem limite)
+	QueryTimeout    time.Duration // Limite de cada operação dos repositórios (0: sem limite)
+	ConnectRetries  int           // Novas tentativas de conexão na inicialização da collegectl, com espera crescente
+	ConnectBackoff  time.Duration // Espera antes da primeira nova tentativa (collegectl e Reconnector); dobra a cada falha
+	PoolerMode      bool          // Atrás de um pooler em modo transação (PgBouncer): sem prepared statements
+}
+
+// CORSConfig configura as origens aceitas pelo navegador.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowCredentials bool
+}
+
+// AuthConfig configura a autenticação por chave de API. Sem chaves, a API é aberta.
+type AuthConfig struct {
+	APIKeys []string
+}
+
+// RateLimitConfig configura o limite padrão de requisições por cliente e o limite por IP anterior à autenticação.
+type RateLimitConfig struct {
+	RPS            float64  // Requisições por segundo (0 desabilita)
+	Burst          int      // Rajada máxima
+	IPRPS          float64  // Requisições por segundo por IP, antes da autenticação (0 desabilita)
+	IPBurst        int      // Rajada máxima por IP
+	TrustedProxies []string // IPs ou CIDRs cujo X-Forwarded-For é aceito
+}
+
+// CacheConfig configura o cache de leitura de matérias e professores.
+type CacheConfig struct {
+	Enabled    bool
+	TTL        time.Duration // Tempo de vida de cada entrada
+	MaxEntries int           // Máximo de entradas de cada cache
+}
+
+// FeatureConfig liga e desliga partes opcionais da API.
+type FeatureConfig struct {
+	Metrics     bool // Expõe /metrics
+	AdminRoutes bool // Registra as rotas /admin/*
+}
+
+// minAPIKeyLength é o tamanho mínimo de uma chave de API, para evitar chaves fáceis de adivinhar.
+const minAPIKeyLength = 16
+
+// setting descreve uma opção: a variável de ambiente, a flag equivalente e o valor padrão.
+type setting struct {
+	env   string
+	def   string
+	usage string
+}
+
+// flagName converte o nome da variável de ambiente na flag equivalente (DB_MAX_OPEN_CONNS vira -db-max-open-conns).
+func (s setting) flagName() string {
+	return strings.ReplaceAll(strings.ToLower(s.env), "_", "-")
+}
+
+// settings lista todas as opções reconhecidas, na ordem em que aparecem na ajuda.
+var settings = []setting{
+	{"DATABASE_URL", "", "URL de conexão com o PostgreSQL (obrigatória)"},
+	{"DB_MAX_OPEN_CONNS", "10", "máximo de conexões abertas por instância (0: sem limite)"},
+	{"DB_MAX_IDLE_CONNS", "2", "máximo de conexões ociosas no pool"},
+	{"DB_CONN_MAX_LIFETIME", "30m", "tempo máximo de vida de uma conexão (0: sem limite)"},
+	{"DB_CONN_MAX_IDLE_TIME", "5m", "tempo máximo de uma conexão ociosa (0: sem limite)"},
+	{"DB_QUERY_TIMEOUT", "30s", "limite de cada operação dos repositórios (0: sem limite)"},
+	{"DB_CONNECT_RETRIES", "3", "novas tentativas de conexão na inicialização da collegectl (a API tenta uma vez e retoma pelas requisições seguintes)"},
+	{"DB_CONNECT_BACKOFF", "500ms", "espera antes da primeira nova tentativa de conexão (dobra a cada falha)"},
+	{"DB_POOLER_MODE", "false", "compatibilidade com poolers em modo transação, como o PgBouncer"},
+	{"LOG_FORMAT", logging.FormatText, "formato do log: text ou json"},
+	{"LOG_LEVEL", "info", "nível mínimo do log: debug, info, warn ou error"},
+	{"CORS_ALLOWED_ORIGINS", "*", "origens aceitas pelo CORS, separadas por vírgula"},
+	{"CORS_ALLOW_CREDENTIALS", "true", "permite credenciais nas requisições CORS"},
+	{"API_KEYS", "", "chaves de API aceitas, separadas por vírgula (vazio: API aberta)"},
+	{"RATE_LIMIT_RPS", "10", "requisições por segundo por cliente (0 desabilita)"},
+	{"RATE_LIMIT_BURST", "20", "rajada máxima de requisições por cliente"},
+	{"RATE_LIMIT_IP_RPS", "20", "requisições por segundo por IP, antes da autenticação, inclusive as recusadas (0 desabilita)"},
+	{"RATE_LIMIT_IP_BURST", "40", "rajada máxima de requisições por IP"},
+	{"TRUSTED_PROXIES", "", "IPs ou CIDRs confiáveis para X-Forwarded-For, separados por vírgula"},
+	{"CACHE_ENABLED", "true", "cache de leitura de matérias e professores"},
+	{"CACHE_TTL", "30s", "tempo de vida das entradas do cache"},
+	{"CACHE_MAX_ENTRIES", "1000", "máximo de entradas de cada cache"},
+	{"PURGE_RETENTION", "2160h", "retenção dos registros excluídos antes do expurgo"},
+	{"ROLLOVER_UNDO_WINDOW", "72h", "janela para desfazer uma virada de ano letivo"},
+	{"ENABLE_METRICS", "true", "expõe as métricas em /metrics"},
+	{"ENABLE_ADMIN_ROUTES", "true", "registra as rotas /admin/*"},
+}
+
+// Load carrega a configuração. Cada opção vem, em ordem crescente de precedência, do valor padrão,
+// do arquivo .env (ENV_FILE ou -env-file; o .env do diretório atual é opcional), das variáveis de
+// ambiente e das flags em args (ex: -database-url, -log-level). Devolve os argumentos que sobram
+// depois das flags.
+// Um erro de sintaxe nas flags devolve cfg nil. Os demais problemas (valores mal formados ou
+// inválidos) são reunidos em um único erro, um por linha, junto com a configuração lida.
+func Load(args []string) (cfg *Config, rest []string, err error) {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	envFile := fs.String("env-file", "", "arquivo .env com variáveis de ambiente (padrão: .env, se existir)")
+	flags := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		flags[s.env] = fs.String(s.flagName(), "", s.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	l := &loader{}
+	path, explicit := *envFile, set["env-file"]
+	if !explicit {
+		path, explicit = os.LookupEnv("ENV_FILE")
+	}
+	if path == "" {
+		path = ".env"
+	}
+	dotenv, readErr := godotenv.Read(path)
+	if readErr != nil && (explicit || !errors.Is(readErr, os.ErrNotExist)) {
+		l.problem("ENV_FILE", "não foi possível ler %s: %v", path, readErr)
+	}
+
+	l.lookup = func(s setting) string {
+		if set[s.flagName()] {
+			return *flags[s.env]
+		}
+		if v, ok := os.LookupEnv(s.env); ok {
+			return v
+		}
+		if v, ok := dotenv[s.env]; ok {
+			return v
+		}
+		return s.def
+	}
+
+	cfg = &Config{
+		DB: DBConfig{
+			URL:             l.str("DATABASE_URL"),
+			MaxOpenConns:    l.int("DB_MAX_OPEN_CONNS"),
+			MaxIdleConns:    l.int("DB_MAX_IDLE_CONNS"),
+			ConnMaxLifetime: l.duration("DB_CONN_MAX_LIFETIME"),
+			ConnMaxIdleTime: l.duration("DB_CONN_MAX_IDLE_TIME"),
+			QueryTimeout:    l.duration("DB_QUERY_TIMEOUT"),
+			ConnectRetries:  l.int("DB_CONNECT_RETRIES"),
+			ConnectBackoff:  l.duration("DB_CONNECT_BACKOFF"),
+			PoolerMode:      l.bool("DB_POOLER_MODE"),
+		},
+		Log: logging.Config{Format: strings.ToLower(l.str("LOG_FORMAT")), Level: l.level("LOG_LEVEL")},
+		CORS: CORSConfig{
+			AllowedOrigins:   l.list("CORS_ALLOWED_ORIGINS"),
+			AllowCredentials: l.bool("CORS_ALLOW_CREDENTIALS"),
+		},
+		Auth: AuthConfig{APIKeys: l.list("API_KEYS")},
+		RateLimit: RateLimitConfig{
+			RPS:            l.float("RATE_LIMIT_RPS"),
+			Burst:          l.int("RATE_LIMIT_BURST"),
+			IPRPS:          l.float("RATE_LIMIT_IP_RPS"),
+			IPBurst:        l.int("RATE_LIMIT_IP_BURST"),
+			TrustedProxies: l.list("TRUSTED_PROXIES"),
+		},
+		Cache: CacheConfig{
+			Enabled:    l.bool("CACHE_ENABLED"),
+			TTL:        l.duration("CACHE_TTL"),
+			MaxEntries: l.int("CACHE_MAX_ENTRIES"),
+		},
+		PurgeRetention:     l.duration("PURGE_RETENTION"),
+		RolloverUndoWindow: l.duration("ROLLOVER_UNDO_WINDOW"),
+		Features: FeatureConfig{
+			Metrics:     l.bool("ENABLE_METRICS"),
+			AdminRoutes: l.bool("ENABLE_ADMIN_ROUTES"),
+		},
+	}
+	cfg.validate(l)
+	return cfg, fs.Args(), errors.Join(l.errs...)
+}
+
+// Usage escreve a lista de flags e variáveis de ambiente aceitas por Load.
+func Usage(w io.Writer) {
+	fmt.Fprintln(w, "  -env-file ARQUIVO (ENV_FILE): arquivo .env com variáveis de ambiente (padrão: .env, se existir)")
+	for _, s := range settings {
+		def := ""
+		if s.def != "" {
+			def = fmt.Sprintf(" (padrão: %s)", s.def)
+		}
+		fmt.Fprintf(w, "  -%s (%s): %s%s\n", s.flagName(), s.env, s.usage, def)
+	}
+}
+
+// validate confere as regras entre as opções, acrescentando cada problema encontrado a l.
+func (c *Config) validate(l *loader) {
+	if strings.TrimSpace(c.DB.URL) == "" {
+		l.problem("DATABASE_URL", "obrigatória")
+	}
+	if c.DB.MaxOpenConns < 0 {
+		l.problem("DB_MAX_OPEN_CONNS", "não pode ser negativo")
+	}
+	if c.DB.MaxIdleConns < 0 {
+		l.problem("DB_MAX_IDLE_CONNS", "não pode ser negativo")
+	}
+	if c.DB.MaxOpenConns > 0 && c.DB.MaxIdleConns > c.DB.MaxOpenConns {
+		l.problem("DB_MAX_IDLE_CONNS", "(%d) não pode ser maior que DB_MAX_OPEN_CONNS (%d)", c.DB.MaxIdleConns, c.DB.MaxOpenConns)
+	}
+	if c.DB.ConnMaxLifetime < 0 {
+		l.problem("DB_CONN_MAX_LIFETIME", "não pode ser negativo")
+	}
+	if c.DB.ConnMaxIdleTime < 0 {
+		l.problem("DB_CONN_MAX_IDLE_TIME", "não pode ser negativo")
+	}
+	if c.DB.QueryTimeout < 0 {
+		l.problem("DB_QUERY_TIMEOUT", "não pode ser negativo")
+	}
+	if c.DB.ConnectRetries < 0 {
+		l.problem("DB_CONNECT_RETRIES", "não pode ser negativo")
+	}
+	if c.DB.ConnectBackoff <= 0 {
+		l.problem("DB_CONNECT_BACKOFF", "precisa ser positivo")
+	}
+	if c.Log.Format != logging.FormatText && c.Log.Format != logging.FormatJSON {
+		l.problem("LOG_FORMAT", "%q inválido (use text ou json)", c.Log.Format)
+	}
+	if len(c.CORS.AllowedOrigins) == 0 {
+		l.problem("CORS_ALLOWED_ORIGINS", "informe ao menos uma origem (ou *)")
+	}
+	for i, key := range c.Auth.APIKeys {
+		if len(key) < minAPIKeyLength {
+			l.problem("API_KEYS", "a chave %d tem menos de %d caracteres", i+1, minAPIKeyLength)
+		}
+	}
+	if c.RateLimit.RPS < 0 {
+		l.problem("RATE_LIMIT_RPS", "não pode ser negativo")
+	}
+	if c.RateLimit.RPS > 0 && c.RateLimit.Burst < 1 {
+		l.problem("RATE_LIMIT_BURST", "precisa ser ao menos 1 com o limite habilitado")
+	}
+	if c.RateLimit.IPRPS < 0 {
+		l.problem("RATE_LIMIT_IP_RPS", "não pode ser negativo")
+	}
+	if c.RateLimit.IPRPS > 0 && c.RateLimit.IPBurst < 1 {
+		l.problem("RATE_LIMIT_IP_BURST", "precisa ser ao menos 1 com o limite habilitado")
+	}
+	for _, entry := range c.RateLimit.TrustedProxies {
+		if net.ParseIP(entry) == nil {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				l.problem("TRUSTED_PROXIES", "%q não é um IP nem um CIDR", entry)
+			}
+		}
+	}
+	if c.Cache.Enabled && c.Cache.TTL <= 0 {
+		l.problem("CACHE_TTL", "precisa ser positivo com o cache habilitado")
+	}
+	if c.Cache.Enabled && c.Cache.MaxEntries < 1 {
+		l.problem("CACHE_MAX_ENTRIES", "precisa ser ao menos 1 com o cache habilitado")
+	}
+	if c.PurgeRetention < 0 {
+		l.problem("PURGE_RETENTION", "não pode ser negativa")
+	}
+	if c.RolloverUndoWindow <= 0 {
+		l.problem("ROLLOVER_UNDO_WINDOW", "precisa ser positiva")
+	}
+}
+
+// loader converte os valores das opções, acumulando os problemas em vez de parar no primeiro.
+type loader struct {
+	lookup func(setting) string
+	errs   []error
+}
+
+func (l *loader) problem(env, format string, args ...interface{}) {
+	l.errs = append(l.errs, fmt.Errorf("%s: %s", env, fmt.Sprintf(format, args...)))
+}
+
+// setting devolve a descrição da opção; um nome fora de settings é erro de programação.
+func (l *loader) setting(env string) setting {
+	for _, s := range settings {
+		if s.env == env {
+			return s
+		}
+	}
+	panic("config: opção desconhecida " + env)
+}
+
+func (l *loader) str(env string) string {
+	return strings.TrimSpace(l.lookup(l.setting(env)))
+}
+
+func (l *loader) int(env string) int {
+	v := l.str(env)
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		l.problem(env, "%q não é um número inteiro", v)
+	}
+	return n
+}
+
+func (l *loader) float(env string) float64 {
+	v := l.str(env)
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		l.problem(env, "%q não é um número", v)
+	}
+	return f
+}
+
+func (l *loader) bool(env string) bool {
+	v := l.str(env)
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		l.problem(env, "%q não é um booleano (use true ou false)", v)
+	}
+	return b
+}
+
+func (l *loader) duration(env string) time.Duration {
+	v := l.str(env)
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		l.problem(env, "%q não é uma duração (ex: 30s, 5m, 72h)", v)
+	}
+	return d
+}
+
+func (l *loader) level(env string) slog.Level {
+	v := l.str(env)
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(v)); err != nil {
+		l.problem(env, "%q inválido (use debug, info, warn ou error)", v)
+	}
+	return level
+}
+
+// list separa um valor por vírgulas, descartando os itens vazios.
+func (l *loader) list(env string) []string {
+	var items []string
+	for _, item := range strings.Split(l.str(env), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
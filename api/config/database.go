@@ -2,36 +2,125 @@
 package config
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
-	"os"
+	"net/url"
+	"strings"
+	"time"
 
 	_ "github.com/lib/pq" // Driver PostgreSQL
 )
 
-var DB *sql.DB
+// SchemaVersion é a versão do esquema esperada por este código: o número de etapas de createTables.
+// Incremente-a ao acrescentar uma etapa; /readyz compara-a com a versão gravada em schema_info.
+const SchemaVersion = 12
 
-func InitDB() {
-	var err error
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		log.Fatal("Variável de ambiente DATABASE_URL não definida. Por favor, defina-a.")
+// OpenDB abre o pool de conexões com o tamanho e os tempos de cfg, conecta e aplica as migrações,
+// em uma única tentativa curta (ping limitado a coldStartPingTimeout): na inicialização a frio de uma
+// Serverless Function, esperar o banco atrasaria a primeira requisição. Se a conexão ou as migrações
+// falharem, o pool é devolvido junto com o erro: a API sobe mesmo assim, expõe a falha em /readyz e
+// conclui a inicialização depois, com backoff (ver Reconnector).
+func OpenDB(cfg DBConfig) (*sql.DB, error) {
+	db, err := openPool(cfg)
+	if err != nil {
+		return nil, err
 	}
+	return db, connect(context.Background(), db, coldStartPingTimeout)
+}
 
-	DB, err = sql.Open("postgres", dbURL)
+// OpenDBWithRetry é OpenDB para processos sem Reconnector (a collegectl): se o banco não responder,
+// tenta de novo até cfg.ConnectRetries vezes, dobrando a espera a cada falha (a partir de
+// cfg.ConnectBackoff). Se ainda assim falhar, o pool é devolvido junto com o erro.
+func OpenDBWithRetry(cfg DBConfig) (*sql.DB, error) {
+	db, err := openPool(cfg)
 	if err != nil {
-		log.Fatalf("Erro ao abrir o banco de dados PostgreSQL: %v", err)
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		err = connect(context.Background(), db, pingTimeout)
+		if err == nil || attempt == cfg.ConnectRetries {
+			break
+		}
+		wait := connectBackoff(cfg.ConnectBackoff, attempt)
+		log.Printf("Banco de dados indisponível (tentativa %d de %d), nova tentativa em %s: %v", attempt+1, cfg.ConnectRetries+1, wait, err)
+		time.Sleep(wait)
 	}
+	return db, err
+}
 
-	if err = DB.Ping(); err != nil {
-		log.Fatalf("Erro ao conectar ao banco de dados PostgreSQL: %v", err)
+// openPool abre o pool de conexões com o tamanho e os tempos de cfg, sem conectar.
+func openPool(cfg DBConfig) (*sql.DB, error) {
+	dsn := cfg.URL
+	if cfg.PoolerMode {
+		var err error
+		if dsn, err = poolerDSN(dsn); err != nil {
+			return nil, fmt.Errorf("erro ao abrir o banco de dados PostgreSQL: %w", err)
+		}
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir o banco de dados PostgreSQL: %w", err)
 	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return db, nil
+}
 
+// connect verifica se o banco responde (em até timeout) e aplica as migrações.
+func connect(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		return fmt.Errorf("erro ao conectar ao banco de dados PostgreSQL: %w", err)
+	}
 	log.Println("Conexão com o banco de dados PostgreSQL estabelecida com sucesso!")
-	createTables()
+	return createTables(db)
 }
 
-func createTables() {
+// pingTimeout limita cada tentativa de conexão, para que um banco que não responde conte como
+// falha (e espere o backoff) em vez de segurar a inicialização indefinidamente.
+const pingTimeout = 5 * time.Second
+
+// coldStartPingTimeout limita a única tentativa de OpenDB, que segura a primeira requisição da instância.
+const coldStartPingTimeout = 2 * time.Second
+
+// maxConnectBackoff limita a espera entre as tentativas de conexão.
+const maxConnectBackoff = 30 * time.Second
+
+// connectBackoff devolve a espera antes da tentativa seguinte à falha de número failure (a partir de 0):
+// base, 2*base, 4*base... até maxConnectBackoff.
+func connectBackoff(base time.Duration, failure int) time.Duration {
+	wait := base
+	for i := 0; i < failure && wait < maxConnectBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxConnectBackoff)
+}
+
+// poolerDSN ajusta a URL de conexão para poolers em modo transação (PgBouncer e semelhantes), que
+// trocam a conexão com o servidor entre as mensagens do protocolo. Com binary_parameters, o driver
+// envia preparo, parâmetros e execução de uma vez, com um statement sem nome, em vez de preparar a
+// consulta em uma ida ao servidor e executá-la em outra. Aceita URLs (postgres://) e o formato chave=valor.
+func poolerDSN(dsn string) (string, error) {
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn + " binary_parameters=yes", nil
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("binary_parameters", "yes")
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// createTables cria as tabelas e aplica as migrações, em ordem, e grava SchemaVersion em schema_info.
+func createTables(db *sql.DB) error {
 	// ATUALIZADO: Adicionada a coluna 'shift' e removido 'UNIQUE' de 'enrollment' temporariamente
 	// para permitir a geração de matrículas mais flexíveis antes de definir a unicidade composta.
 	// A unicidade será garantida pela lógica de geração no serviço.
@@ -66,32 +155,215 @@ func createTables() {
         subject_id TEXT NOT NULL,
         PRIMARY KEY (student_id, subject_id),
         FOREIGN KEY (student_id) REFERENCES students(id) ON DELETE CASCADE,
-        FOREIGN KEY (subject_id) REFERENCES subjects(id) ON DELETE CASCADE
+        FOREIGN KEY (subject_id) REFERENCES subjects(id) ON DELETE RESTRICT -- Matérias com alunos associados não podem ser removidas
     );`
 
-	_, err := DB.Exec(createStudentsTableSQL)
-	if err != nil {
-		log.Fatalf("Erro ao criar tabela students: %v", err)
+	// Exclusão lógica: registros com deleted_at preenchido ficam ocultos das consultas, mas podem ser restaurados.
+	// Bancos criados antes desta coluna tinham ON DELETE CASCADE em subject_id, que apagava o histórico
+	// acadêmico dos alunos junto com a matéria; o bloco DO troca a restrição por RESTRICT.
+	migrateSoftDeleteSQL := `
+    ALTER TABLE students ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+    ALTER TABLE subjects ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+    ALTER TABLE teachers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+    DO $$
+    BEGIN
+        IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'student_subjects_subject_id_fkey' AND confdeltype = 'c') THEN
+            ALTER TABLE student_subjects DROP CONSTRAINT student_subjects_subject_id_fkey;
+            ALTER TABLE student_subjects ADD CONSTRAINT student_subjects_subject_id_fkey
+                FOREIGN KEY (subject_id) REFERENCES subjects(id) ON DELETE RESTRICT;
+        END IF;
+    END $$;`
+
+	// Controle de concorrência otimista: cada alteração incrementa version, exposta aos clientes como ETag.
+	migrateVersionSQL := `
+    ALTER TABLE students ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+    ALTER TABLE subjects ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+    ALTER TABLE teachers ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;`
+
+	// Trilha de auditoria: somente inserções. O gatilho abaixo impede UPDATE e DELETE.
+	createAuditLogTableSQL := `
+    CREATE TABLE IF NOT EXISTS audit_log (
+        id BIGSERIAL PRIMARY KEY,
+        occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+        actor TEXT NOT NULL,
+        action TEXT NOT NULL,
+        entity TEXT NOT NULL,
+        entity_id TEXT NOT NULL,
+        before JSONB,
+        after JSONB,
+        diff JSONB,
+        request_id TEXT
+    );
+    CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (entity, entity_id, id DESC);
+    CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+    BEGIN
+        RAISE EXCEPTION 'audit_log é somente inserção';
+    END;
+    $$ LANGUAGE plpgsql;
+    DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+    CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+        FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();`
+
+	// Virada de ano letivo: cada execução guarda o estado anterior dos alunos alterados,
+	// para que possa ser desfeita dentro da janela (undo_deadline).
+	createRolloverTablesSQL := `
+    ALTER TABLE students ADD COLUMN IF NOT EXISTS graduating BOOLEAN NOT NULL DEFAULT FALSE;
+    CREATE TABLE IF NOT EXISTS rollovers (
+        id BIGSERIAL PRIMARY KEY,
+        executed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+        actor TEXT NOT NULL,
+        criteria JSONB NOT NULL,
+        undo_deadline TIMESTAMPTZ NOT NULL,
+        undone_at TIMESTAMPTZ,
+        undone_by TEXT
+    );
+    CREATE TABLE IF NOT EXISTS rollover_changes (
+        rollover_id BIGINT NOT NULL REFERENCES rollovers(id) ON DELETE CASCADE,
+        student_id TEXT NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+        from_year INTEGER NOT NULL,
+        to_year INTEGER NOT NULL,
+        from_graduating BOOLEAN NOT NULL,
+        to_graduating BOOLEAN NOT NULL,
+        version_after INTEGER NOT NULL, -- Versão do aluno logo após a virada; se mudar, o desfazer é recusado
+        PRIMARY KEY (rollover_id, student_id)
+    );`
+
+	// Situação do aluno (máquina de estados nos serviços) e o histórico de cada mudança.
+	createStudentStatusSQL := `
+    ALTER TABLE students ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'suspended', 'locked', 'graduated', 'withdrawn', 'transferred'));
+    CREATE TABLE IF NOT EXISTS student_status_history (
+        id TEXT PRIMARY KEY,
+        student_id TEXT NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+        from_status TEXT NOT NULL,
+        to_status TEXT NOT NULL,
+        reason TEXT NOT NULL,
+        effective_date DATE NOT NULL,
+        actor TEXT NOT NULL,
+        recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
+    CREATE INDEX IF NOT EXISTS student_status_history_student_idx ON student_status_history (student_id, recorded_at);`
+
+	// Cursos: grade de matérias obrigatórias e optativas, créditos mínimos por ano e o vínculo dos alunos.
+	createProgramsSQL := `
+    CREATE TABLE IF NOT EXISTS programs (
+        id TEXT PRIMARY KEY,
+        name TEXT NOT NULL,
+        version INTEGER NOT NULL DEFAULT 1
+    );
+    CREATE TABLE IF NOT EXISTS program_subjects (
+        program_id TEXT NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+        subject_id TEXT NOT NULL REFERENCES subjects(id) ON DELETE RESTRICT,
+        kind TEXT NOT NULL CHECK (kind IN ('required', 'elective')),
+        PRIMARY KEY (program_id, subject_id)
+    );
+    CREATE TABLE IF NOT EXISTS program_year_requirements (
+        program_id TEXT NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+        year INTEGER NOT NULL,
+        min_credits INTEGER NOT NULL,
+        PRIMARY KEY (program_id, year)
+    );
+    ALTER TABLE students ADD COLUMN IF NOT EXISTS program_id TEXT REFERENCES programs(id) ON DELETE RESTRICT;`
+
+	// Departamentos: antes eram texto livre em teachers.department, e o prefixo do registro era derivado
+	// dele. A migração cria um departamento por prefixo de registro existente (o nome mais frequente do
+	// grupo), para que grafias diferentes do mesmo departamento não virem departamentos distintos, e liga
+	// os professores a ele. A coluna department antiga é mantida apenas como histórico.
+	// O chefe é DEFERRABLE porque departamentos e professores se referenciam (ex: na restauração de backup).
+	createDepartmentsSQL := `
+    CREATE TABLE IF NOT EXISTS departments (
+        id TEXT PRIMARY KEY,
+        code TEXT NOT NULL UNIQUE,
+        name TEXT NOT NULL,
+        head_teacher_id TEXT REFERENCES teachers(id) ON DELETE SET NULL DEFERRABLE INITIALLY DEFERRED,
+        version INTEGER NOT NULL DEFAULT 1,
+        deleted_at TIMESTAMPTZ
+    );
+    ALTER TABLE teachers ADD COLUMN IF NOT EXISTS department_id TEXT REFERENCES departments(id);
+    ALTER TABLE teachers ALTER COLUMN department DROP NOT NULL;
+    INSERT INTO departments (id, code, name)
+    SELECT gen_random_uuid()::text, code, name FROM (
+        SELECT split_part(registry, '-', 1) AS code, mode() WITHIN GROUP (ORDER BY department) AS name
+        FROM teachers
+        WHERE department_id IS NULL AND department IS NOT NULL
+        GROUP BY split_part(registry, '-', 1)
+    ) legacy
+    ON CONFLICT (code) DO NOTHING;
+    UPDATE teachers t SET department_id = d.id
+    FROM departments d
+    WHERE t.department_id IS NULL AND d.code = split_part(t.registry, '-', 1);`
+
+	// Nomes de alunos e professores gravados na auditoria antes de serem omitidos (ver
+	// repositories.AuditRedacted). O gatilho de somente inserção é suspenso só enquanto há o que corrigir.
+	redactAuditLogSQL := `
+    DO $$
+    DECLARE
+        personal CONSTANT TEXT := '[omitido]';
+    BEGIN
+        IF EXISTS (
+            SELECT 1 FROM audit_log
+            WHERE entity IN ('students', 'teachers')
+              AND (before->>'name' <> personal OR after->>'name' <> personal
+                   OR diff->'name'->>'from' <> personal OR diff->'name'->>'to' <> personal)
+        ) THEN
+            ALTER TABLE audit_log DISABLE TRIGGER audit_log_append_only;
+            UPDATE audit_log SET
+                before = CASE WHEN before->>'name' <> '' THEN jsonb_set(before, '{name}', to_jsonb(personal)) ELSE before END,
+                after = CASE WHEN after->>'name' <> '' THEN jsonb_set(after, '{name}', to_jsonb(personal)) ELSE after END,
+                diff = CASE WHEN diff ? 'name' THEN jsonb_set(diff, '{name}', jsonb_build_object(
+                    'from', CASE WHEN diff->'name'->>'from' <> '' THEN to_jsonb(personal) ELSE diff->'name'->'from' END,
+                    'to', CASE WHEN diff->'name'->>'to' <> '' THEN to_jsonb(personal) ELSE diff->'name'->'to' END)) ELSE diff END
+            WHERE entity IN ('students', 'teachers')
+              AND (before->>'name' <> personal OR after->>'name' <> personal
+                   OR diff->'name'->>'from' <> personal OR diff->'name'->>'to' <> personal);
+            ALTER TABLE audit_log ENABLE TRIGGER audit_log_append_only;
+        END IF;
+    END $$;`
+
+	// Versão do esquema aplicada, consultada por /readyz. A tabela tem no máximo uma linha.
+	createSchemaInfoSQL := `
+    CREATE TABLE IF NOT EXISTS schema_info (
+        id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+        version INTEGER NOT NULL,
+        applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );`
+
+	steps := []struct {
+		sql  string
+		desc string
+	}{
+		{createStudentsTableSQL, "criar tabela students"},
+		{createSubjectsTableSQL, "criar tabela subjects"},
+		{createTeachersTableSQL, "criar tabela teachers"},
+		{createStudentSubjectsTableSQL, "criar tabela student_subjects"},
+		{migrateSoftDeleteSQL, "aplicar migração de exclusão lógica"},
+		{migrateVersionSQL, "aplicar migração de versionamento"},
+		{createAuditLogTableSQL, "criar tabela audit_log"},
+		{createRolloverTablesSQL, "criar tabelas de virada de ano letivo"},
+		{createStudentStatusSQL, "aplicar migração de situação do aluno"},
+		{createProgramsSQL, "criar tabelas de cursos"},
+		{createDepartmentsSQL, "aplicar migração de departamentos"},
+		{redactAuditLogSQL, "omitir nomes na auditoria"},
 	}
-	_, err = DB.Exec(createSubjectsTableSQL)
-	if err != nil {
-		log.Fatalf("Erro ao criar tabela subjects: %v", err)
+	if len(steps) != SchemaVersion {
+		return fmt.Errorf("SchemaVersion (%d) difere do número de etapas de migração (%d)", SchemaVersion, len(steps))
 	}
-	_, err = DB.Exec(createTeachersTableSQL)
-	if err != nil {
-		log.Fatalf("Erro ao criar tabela teachers: %v", err)
+	for _, step := range steps {
+		if _, err := db.Exec(step.sql); err != nil {
+			return fmt.Errorf("erro ao %s: %w", step.desc, err)
+		}
 	}
-	_, err = DB.Exec(createStudentSubjectsTableSQL)
-	if err != nil {
-		log.Fatalf("Erro ao criar tabela student_subjects: %v", err)
+
+	if _, err := db.Exec(createSchemaInfoSQL); err != nil {
+		return fmt.Errorf("erro ao criar tabela schema_info: %w", err)
+	}
+	recordVersionSQL := `
+    INSERT INTO schema_info (version) VALUES ($1)
+    ON CONFLICT (id) DO UPDATE SET version = EXCLUDED.version, applied_at = NOW()`
+	if _, err := db.Exec(recordVersionSQL, SchemaVersion); err != nil {
+		return fmt.Errorf("erro ao gravar a versão do esquema: %w", err)
 	}
 
 	log.Println("Tabelas verificadas/criadas com sucesso!")
-}
-
-func CloseDB() {
-	if DB != nil {
-		DB.Close()
-		log.Println("Conexão com o banco de dados PostgreSQL fechada.")
-	}
+	return nil
 }
@@ -0,0 +1,73 @@
+// api/config/reconnector.go
+package config
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+// Reconnector conclui a inicialização do banco (conexão e migrações) quando ela falhou em OpenDB.
+// O database/sql já refaz as conexões sozinho; o que fica pendente são as migrações, e enquanto elas
+// não forem aplicadas as requisições não devem chegar aos repositórios.
+// Em Serverless Functions não há processo em segundo plano entre as invocações, por isso a nova
+// tentativa é feita pela própria requisição (ver Ensure), respeitando um backoff exponencial para que
+// um banco fora do ar não seja martelado a cada requisição.
+type Reconnector struct {
+	db      *sql.DB
+	backoff time.Duration
+
+	mu       sync.Mutex
+	ready    bool
+	err      error     // Falha da última tentativa
+	failures int       // Novas tentativas que falharam desde OpenDB
+	retryAt  time.Time // Antes disso, Ensure devolve err sem tentar de novo
+}
+
+// NewReconnector cria um Reconnector para db, a partir do erro devolvido por OpenDB (nil se a
+// inicialização foi concluída).
+func NewReconnector(db *sql.DB, cfg DBConfig, err error) *Reconnector {
+	r := &Reconnector{db: db, backoff: cfg.ConnectBackoff, ready: err == nil, err: err}
+	if err != nil {
+		r.retryAt = time.Now().Add(connectBackoff(r.backoff, 0))
+	}
+	return r
+}
+
+// Ensure devolve nil se o banco está pronto. Caso contrário, se o backoff já passou, tenta conectar
+// e aplicar as migrações de novo; senão devolve a falha anterior sem tocar no banco.
+// Requisições simultâneas esperam a mesma tentativa em vez de repeti-la.
+func (r *Reconnector) Ensure(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ready {
+		return nil
+	}
+	if time.Now().Before(r.retryAt) {
+		return r.err
+	}
+
+	if err := connect(ctx, r.db, pingTimeout); err != nil {
+		r.err = err
+		r.failures++
+		r.retryAt = time.Now().Add(connectBackoff(r.backoff, r.failures))
+		log.Printf("Banco de dados ainda indisponível (%dª nova tentativa), próxima a partir de %s: %v", r.failures, r.retryAt.Format(time.RFC3339), err)
+		return err
+	}
+	log.Printf("Inicialização do banco de dados concluída na %dª nova tentativa", r.failures+1)
+	r.ready, r.err = true, nil
+	return nil
+}
+
+// RetryAfter devolve quanto falta para a próxima tentativa (zero se o banco está pronto ou se a
+// próxima requisição já tentará de novo).
+func (r *Reconnector) RetryAfter() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ready {
+		return 0
+	}
+	return max(time.Until(r.retryAt), 0)
+}
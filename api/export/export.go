@@ -0,0 +1,92 @@
+// export/export.go
+// Pacote export grava planilhas (CSV e XLSX) linha a linha, sem manter o arquivo inteiro em memória.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Formatos suportados.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Writer grava as linhas de uma planilha. Os valores podem ser string ou int
+// (números viram células numéricas no XLSX). Close finaliza o arquivo, mas não fecha o io.Writer.
+type Writer interface {
+	WriteRow(values ...interface{}) error
+	Close() error
+}
+
+// NewWriter cria o Writer do formato pedido ("csv" ou "xlsx"); sheet é o nome da aba no XLSX.
+func NewWriter(format string, w io.Writer, sheet string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w)
+	case FormatXLSX:
+		return NewXLSXWriter(w, sheet)
+	}
+	return nil, fmt.Errorf("formato de exportação não suportado: %q (use csv ou xlsx)", format)
+}
+
+// ContentType devolve o tipo MIME do formato.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// csvWriter grava CSV em UTF-8 com BOM, para que o Excel reconheça os acentos.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVWriter cria um Writer de CSV.
+func NewCSVWriter(w io.Writer) (Writer, error) {
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (c *csvWriter) WriteRow(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case int:
+			record[i] = strconv.Itoa(v)
+		case string:
+			record[i] = escapeFormula(v)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+	// O csv.Writer tem buffer próprio; descarregar a cada linha mantém a resposta fluindo.
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula impede que textos começando com =, +, - ou @ sejam interpretados como fórmulas
+// pelo Excel (injeção de CSV), prefixando-os com apóstrofo.
+func escapeFormula(s string) string {
+	if s != "" {
+		switch s[0] {
+		case '=', '+', '-', '@', '\t', '\r':
+			return "'" + s
+		}
+	}
+	return s
+}
@@ -0,0 +1,51 @@
+// handlers/admin_handler.go
+package handlers
+
+import (
+	"college_api/logging"
+	"college_api/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// AdminHandler gerencia as rotas administrativas.
+type AdminHandler struct {
+	purgeService     *services.PurgeService
+	defaultRetention time.Duration
+}
+
+// NewAdminHandler cria uma nova instância de AdminHandler.
+// defaultRetention é usado quando a requisição não informa ?retention=.
+func NewAdminHandler(ps *services.PurgeService, defaultRetention time.Duration) *AdminHandler {
+	return &AdminHandler{purgeService: ps, defaultRetention: defaultRetention}
+}
+
+// PurgeHandler remove definitivamente os registros excluídos logicamente há mais tempo que a retenção.
+// POST /admin/purge?retention=720h
+func (h *AdminHandler) PurgeHandler(w http.ResponseWriter, r *http.Request) {
+	retention := h.defaultRetention
+	if v := r.URL.Query().Get("retention"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			http.Error(w, "Parâmetro retention inválido: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		retention = d
+	}
+
+	report, err := h.purgeService.Purge(r.Context(), retention)
+	if err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao expurgar registros excluídos", "error", err)
+		http.Error(w, "Erro ao expurgar registros: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,50 @@
+// handlers/audit_handler.go
+package handlers
+
+import (
+	"college_api/logging"
+	"college_api/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// AuditHandler gerencia as requisições HTTP de consulta à auditoria.
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+// NewAuditHandler cria uma nova instância de AuditHandler.
+func NewAuditHandler(s *services.AuditService) *AuditHandler {
+	return &AuditHandler{service: s}
+}
+
+// GetAuditEntriesHandler lida com a consulta da trilha de auditoria.
+// GET /audit?entity=students&id={id}&limit={n}
+func (h *AuditHandler) GetAuditEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 0
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Parâmetro limit inválido: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	entries, err := h.service.ListEntries(r.Context(), query.Get("entity"), query.Get("id"), limit)
+	if err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao buscar auditoria no serviço", "error", err)
+		http.Error(w, "Erro ao buscar auditoria: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
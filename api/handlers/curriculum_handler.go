@@ -0,0 +1,72 @@
+// handlers/curriculum_handler.go
+package handlers
+
+import (
+	"college_api/logging"
+	"college_api/services"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// maxCurriculumBytes limita o tamanho do arquivo de grade curricular.
+const maxCurriculumBytes = 1 << 20
+
+// SyncCurriculumHandler sincroniza o catálogo de matérias com uma grade curricular enviada no corpo,
+// em JSON (application/json) ou YAML (application/yaml). Com ?dry_run=true apenas devolve o diff;
+// com ?prune=true exclui as matérias que não estão na grade, recusando (409) se alguma tiver alunos.
+// POST /subjects/sync?dry_run=&prune=
+func (h *SubjectHandler) SyncCurriculumHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := boolQueryParam(r, "dry_run")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	prune, err := boolQueryParam(r, "prune")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := "yaml"
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		format = "json"
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml", "text/plain", "":
+	default:
+		http.Error(w, "Content-Type não suportado: envie application/json ou application/yaml", http.StatusUnsupportedMediaType)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCurriculumBytes))
+	if err != nil {
+		http.Error(w, "Erro ao ler grade curricular: "+err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	curriculum, err := services.ParseCurriculum(data, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	report, err := h.service.SyncCurriculum(r.Context(), curriculum, services.CurriculumSyncOptions{Prune: prune, DryRun: dryRun})
+	if err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao sincronizar grade curricular no serviço", "error", err)
+		http.Error(w, "Erro ao sincronizar grade curricular: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if len(report.Blocked) > 0 && !dryRun {
+		status = http.StatusConflict
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,134 @@
+// handlers/department_handler.go
+package handlers
+
+import (
+	"college_api/logging"
+	"college_api/models"
+	"college_api/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// DepartmentHandler gerencia as requisições HTTP para departamentos.
+type DepartmentHandler struct {
+	service *services.DepartmentService
+}
+
+// NewDepartmentHandler cria uma nova instância de DepartmentHandler.
+func NewDepartmentHandler(s *services.DepartmentService) *DepartmentHandler {
+	return &DepartmentHandler{service: s}
+}
+
+// CreateDepartmentHandler lida com a criação de um departamento.
+// POST /departments
+func (h *DepartmentHandler) CreateDepartmentHandler(w http.ResponseWriter, r *http.Request) {
+	var department models.Department
+	if err := json.NewDecoder(r.Body).Decode(&department); err != nil {
+		http.Error(w, "Requisição inválida: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.CreateDepartment(r.Context(), &department); err != nil {
+		writeDepartmentError(w, r, err, "criar departamento")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(department.Version))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(department)
+}
+
+// GetAllDepartmentsHandler lista os departamentos ativos.
+// GET /departments
+func (h *DepartmentHandler) GetAllDepartmentsHandler(w http.ResponseWriter, r *http.Request) {
+	departments, err := h.service.GetAllDepartments(r.Context())
+	if err != nil {
+		writeDepartmentError(w, r, err, "buscar departamentos")
+		return
+	}
+	writeListJSON(w, r, departments)
+}
+
+// GetDepartmentByIDHandler busca um departamento pelo ID.
+// GET /departments/{id}
+func (h *DepartmentHandler) GetDepartmentByIDHandler(w http.ResponseWriter, r *http.Request) {
+	department, err := h.service.GetDepartmentByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeDepartmentError(w, r, err, "buscar departamento")
+		return
+	}
+	writeVersionedJSON(w, r, department.Version, department)
+}
+
+// UpdateDepartmentHandler atualiza o nome e o chefe de um departamento.
+// PUT /departments/{id} (exige If-Match com a ETag atual)
+func (h *DepartmentHandler) UpdateDepartmentHandler(w http.ResponseWriter, r *http.Request) {
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	var department models.Department
+	if err := json.NewDecoder(r.Body).Decode(&department); err != nil {
+		http.Error(w, "Requisição inválida: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	department.ID = mux.Vars(r)["id"] // Garante que o ID da URL seja usado
+	department.Version = version
+
+	if err := h.service.UpdateDepartment(r.Context(), &department); err != nil {
+		writeDepartmentError(w, r, err, "atualizar departamento")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(department.Version))
+	json.NewEncoder(w).Encode(department)
+}
+
+// DeleteDepartmentHandler exclui logicamente um departamento sem professores ativos.
+// DELETE /departments/{id} (exige If-Match com a ETag atual)
+func (h *DepartmentHandler) DeleteDepartmentHandler(w http.ResponseWriter, r *http.Request) {
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteDepartment(r.Context(), mux.Vars(r)["id"], version); err != nil {
+		writeDepartmentError(w, r, err, "excluir departamento")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreDepartmentHandler restaura um departamento excluído logicamente.
+// POST /departments/{id}:restore
+func (h *DepartmentHandler) RestoreDepartmentHandler(w http.ResponseWriter, r *http.Request) {
+	department, err := h.service.RestoreDepartment(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeDepartmentError(w, r, err, "restaurar departamento")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(department.Version))
+	json.NewEncoder(w).Encode(department)
+}
+
+// writeDepartmentError traduz os erros do serviço de departamentos em status HTTP.
+func writeDepartmentError(w http.ResponseWriter, r *http.Request, err error, action string) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrValidation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrVersionConflict):
+		http.Error(w, "O departamento foi alterado por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+	default:
+		logging.FromContext(r.Context()).Error("erro ao "+action+" no serviço", "error", err)
+		http.Error(w, "Erro ao "+action+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,96 @@
+// handlers/etag.go
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etagFor formata a versão de um registro como ETag forte (ex: "3").
+func etagFor(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// writeVersionedJSON responde com o registro e sua ETag.
+// Se o If-None-Match da requisição já contém essa ETag, responde 304 sem corpo.
+func writeVersionedJSON(w http.ResponseWriter, r *http.Request, version int, v interface{}) {
+	etag := etagFor(version)
+	w.Header().Set("ETag", etag)
+	if ifNoneMatch(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeListJSON responde com uma coleção usando uma ETag fraca derivada do conteúdo,
+// permitindo GETs condicionais em listagens (que não têm uma versão única).
+func writeListJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(v); err != nil {
+		http.Error(w, "Erro ao serializar resposta: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sum := sha256.Sum256(body.Bytes())
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	if ifNoneMatch(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body.Bytes())
+}
+
+// ifNoneMatch verifica (com comparação fraca, como manda a RFC 9110) se etag está no If-None-Match.
+func ifNoneMatch(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// requireIfMatch extrai a versão esperada do cabeçalho If-Match, obrigatório em PUT, PATCH, DELETE
+// e na mudança de situação do aluno.
+// "*" aceita qualquer versão e é devolvido como 0. Se o cabeçalho estiver ausente ou inválido,
+// a resposta de erro já é escrita e ok é false.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (version int, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		http.Error(w, "Cabeçalho If-Match obrigatório: envie a ETag obtida no GET", http.StatusPreconditionRequired)
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+	if strings.HasPrefix(header, "W/") {
+		// Comparação forte: ETags fracas nunca casam com If-Match
+		http.Error(w, "If-Match não aceita ETags fracas", http.StatusPreconditionFailed)
+		return 0, false
+	}
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		http.Error(w, "If-Match deve conter uma única ETag entre aspas", http.StatusBadRequest)
+		return 0, false
+	}
+	version, err = strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		// ETag que nunca foi emitida por esta API: não pode ser a versão atual
+		http.Error(w, "A ETag informada não corresponde à versão atual do registro", http.StatusPreconditionFailed)
+		return 0, false
+	}
+	return version, true
+}
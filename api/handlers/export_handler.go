@@ -0,0 +1,141 @@
+// handlers/export_handler.go
+package handlers
+
+import (
+	"college_api/export"
+	"college_api/logging"
+	"college_api/models"
+	"college_api/services"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// exportStream escreve uma exportação em streaming. Os cabeçalhos HTTP e a linha de títulos só são
+// enviados na primeira linha (ou no fim, se não houver nenhuma), para que erros de validação
+// e de consulta ainda possam ser respondidos com o status adequado.
+type exportStream struct {
+	w       http.ResponseWriter
+	logger  *slog.Logger // Logger da requisição
+	format  string
+	name    string        // Nome base do arquivo e da aba (ex: "alunos")
+	columns []interface{} // Linha de títulos
+	out     export.Writer
+}
+
+// newExportStream lê ?format= (csv, o padrão, ou xlsx). Se o formato for inválido,
+// a resposta de erro já é escrita e ok é false.
+func newExportStream(w http.ResponseWriter, r *http.Request, name string, columns ...interface{}) (stream *exportStream, ok bool) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if format != export.FormatCSV && format != export.FormatXLSX {
+		http.Error(w, fmt.Sprintf("Formato de exportação inválido: %q (use csv ou xlsx)", format), http.StatusBadRequest)
+		return nil, false
+	}
+	return &exportStream{w: w, logger: logging.FromContext(r.Context()), format: format, name: name, columns: columns}, true
+}
+
+// row grava uma linha, iniciando a resposta se for a primeira.
+func (e *exportStream) row(values ...interface{}) error {
+	if e.out == nil {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	return e.out.WriteRow(values...)
+}
+
+func (e *exportStream) start() error {
+	filename := fmt.Sprintf("%s-%s.%s", e.name, time.Now().Format("20060102"), e.format)
+	e.w.Header().Set("Content-Type", export.ContentType(e.format))
+	e.w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	out, err := export.NewWriter(e.format, e.w, e.name)
+	if err != nil {
+		return err
+	}
+	e.out = out
+	return e.out.WriteRow(e.columns...)
+}
+
+// finish conclui a exportação. Se err ocorreu antes de qualquer byte ser enviado, responde com erro;
+// depois disso só resta registrar no log e interromper o arquivo (que fica incompleto).
+func (e *exportStream) finish(err error) {
+	if err == nil && e.out == nil {
+		err = e.start() // Nenhum registro: devolve só a linha de títulos
+	}
+	if err != nil {
+		if e.out != nil {
+			e.logger.Error("erro durante a exportação (resposta interrompida)", "export", e.name, "error", err)
+			return
+		}
+		if errors.Is(err, services.ErrValidation) {
+			http.Error(e.w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		e.logger.Error("erro ao exportar", "export", e.name, "error", err)
+		http.Error(e.w, "Erro ao exportar "+e.name+": "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := e.out.Close(); err != nil {
+		e.logger.Error("erro ao finalizar exportação", "export", e.name, "error", err)
+	}
+}
+
+// ExportStudentsHandler exporta os alunos em CSV ou XLSX, com as matérias achatadas em duas colunas
+// (IDs e nomes separados por "; "). Aceita os mesmos filtros da listagem.
+// GET /students/export?format=csv|xlsx&name=&shift=&current_year=&subject_id=&status=
+func (h *StudentHandler) ExportStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := studentFilterFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	stream, ok := newExportStream(w, r, "alunos", "id", "enrollment", "name", "current_year", "shift", "status", "subject_ids", "subject_names")
+	if !ok {
+		return
+	}
+	stream.finish(h.service.StreamStudents(r.Context(), filter, func(student models.Student) error {
+		ids := make([]string, len(student.Subjects))
+		names := make([]string, len(student.Subjects))
+		for i, subject := range student.Subjects {
+			ids[i] = subject.ID
+			names[i] = subject.Name
+		}
+		return stream.row(student.ID, student.Enrollment, student.Name, student.CurrentYear, student.Shift, student.Status,
+			strings.Join(ids, "; "), strings.Join(names, "; "))
+	}))
+}
+
+// ExportSubjectsHandler exporta as matérias em CSV ou XLSX. Aceita os mesmos filtros da listagem.
+// GET /subjects/export?format=csv|xlsx&name=&year=
+func (h *SubjectHandler) ExportSubjectsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := subjectFilterFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	stream, ok := newExportStream(w, r, "materias", "id", "name", "year", "credits")
+	if !ok {
+		return
+	}
+	stream.finish(h.service.StreamSubjects(r.Context(), filter, func(subject models.Subject) error {
+		return stream.row(subject.ID, subject.Name, subject.Year, subject.Credits)
+	}))
+}
+
+// ExportTeachersHandler exporta os professores em CSV ou XLSX. Aceita os mesmos filtros da listagem.
+// GET /teachers/export?format=csv|xlsx&name=&department=&department_id=
+func (h *TeacherHandler) ExportTeachersHandler(w http.ResponseWriter, r *http.Request) {
+	stream, ok := newExportStream(w, r, "professores", "id", "registry", "name", "department_id", "department")
+	if !ok {
+		return
+	}
+	stream.finish(h.service.StreamTeachers(r.Context(), teacherFilterFromQuery(r), func(teacher models.Teacher) error {
+		return stream.row(teacher.ID, teacher.Registry, teacher.Name, teacher.DepartmentID, teacher.Department)
+	}))
+}
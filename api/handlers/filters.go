@@ -0,0 +1,61 @@
+// handlers/filters.go
+package handlers
+
+import (
+	"college_api/models"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Os filtros abaixo são compartilhados pelas listagens e pelas exportações de cada entidade.
+
+// studentFilterFromQuery lê ?name=&shift=&current_year=&subject_id=&status=.
+// Sem status, só alunos ativos são listados; status=all inclui todos.
+func studentFilterFromQuery(r *http.Request) (models.StudentFilter, error) {
+	q := r.URL.Query()
+	filter := models.StudentFilter{Name: q.Get("name"), Shift: q.Get("shift"), SubjectID: q.Get("subject_id"), Status: q.Get("status")}
+	year, err := intQueryParam(r, "current_year")
+	filter.CurrentYear = year
+	return filter, err
+}
+
+// subjectFilterFromQuery lê ?name=&year=.
+func subjectFilterFromQuery(r *http.Request) (models.SubjectFilter, error) {
+	filter := models.SubjectFilter{Name: r.URL.Query().Get("name")}
+	year, err := intQueryParam(r, "year")
+	filter.Year = year
+	return filter, err
+}
+
+// teacherFilterFromQuery lê ?name=&department=&department_id=.
+func teacherFilterFromQuery(r *http.Request) models.TeacherFilter {
+	q := r.URL.Query()
+	return models.TeacherFilter{Name: q.Get("name"), Department: q.Get("department"), DepartmentID: q.Get("department_id")}
+}
+
+// intQueryParam lê um parâmetro inteiro opcional da query string (0 se ausente).
+func intQueryParam(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("parâmetro %s inválido: %s", name, v)
+	}
+	return n, nil
+}
+
+// boolQueryParam lê um parâmetro booleano opcional da query string (false se ausente).
+func boolQueryParam(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("parâmetro %s inválido: %s", name, v)
+	}
+	return b, nil
+}
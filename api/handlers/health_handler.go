@@ -0,0 +1,51 @@
+// handlers/health_handler.go
+package handlers
+
+import (
+	"college_api/models"
+	"college_api/services"
+	"encoding/json"
+	"net/http"
+)
+
+// HealthHandler atende as sondas da plataforma e a identificação da versão.
+// Essas rotas não exigem autenticação nem passam pela limitação de requisições.
+type HealthHandler struct {
+	service *services.HealthService
+}
+
+// NewHealthHandler cria uma nova instância de HealthHandler.
+func NewHealthHandler(s *services.HealthService) *HealthHandler {
+	return &HealthHandler{service: s}
+}
+
+// HealthzHandler responde 200 enquanto o processo estiver atendendo (liveness); não consulta o banco.
+// GET /healthz
+func (h *HealthHandler) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealthJSON(w, http.StatusOK, map[string]string{"status": models.HealthOK})
+}
+
+// ReadyzHandler responde 200 se o banco responder e as migrações estiverem aplicadas, senão 503.
+// GET /readyz
+func (h *HealthHandler) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	report := h.service.Readiness(r.Context())
+	status := http.StatusOK
+	if report.Status != models.HealthOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealthJSON(w, status, report)
+}
+
+// VersionHandler devolve o commit, o momento da compilação e a versão do esquema.
+// GET /version
+func (h *HealthHandler) VersionHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealthJSON(w, http.StatusOK, h.service.Version())
+}
+
+// writeHealthJSON escreve a resposta sem cache, para que as sondas sempre vejam o estado atual.
+func writeHealthJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
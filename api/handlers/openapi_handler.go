@@ -0,0 +1,36 @@
+// handlers/openapi_handler.go
+package handlers
+
+import (
+	"college_api/openapi"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// OpenAPIHandler serve a especificação OpenAPI 3.1 da API.
+// GET /openapi.json
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	serveStatic(w, r, "application/json", openapi.Document())
+}
+
+// DocsHandler serve a documentação interativa, que lê /openapi.json.
+// GET /docs
+func DocsHandler(w http.ResponseWriter, r *http.Request) {
+	serveStatic(w, r, "text/html; charset=utf-8", openapi.DocsPage)
+}
+
+// serveStatic responde com um conteúdo fixo do binário, com ETag derivada do conteúdo: o navegador
+// revalida a cada acesso (no-cache) e recebe 304 enquanto a versão em execução for a mesma.
+func serveStatic(w http.ResponseWriter, r *http.Request, contentType string, content []byte) {
+	sum := sha256.Sum256(content)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if ifNoneMatch(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(content)
+}
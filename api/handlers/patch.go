@@ -0,0 +1,30 @@
+// handlers/patch.go
+package handlers
+
+import (
+	"college_api/mergepatch"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// maxPatchBytes limita o tamanho do corpo de um PATCH.
+const maxPatchBytes = 1 << 20
+
+// readMergePatch lê o corpo de um PATCH, aceitando application/merge-patch+json (ou application/json).
+// Em caso de erro a resposta já é escrita e ok é false.
+func readMergePatch(w http.ResponseWriter, r *http.Request) (patch []byte, ok bool) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != mergepatch.ContentType && mediaType != "application/json") {
+		w.Header().Set("Accept-Patch", mergepatch.ContentType)
+		http.Error(w, "Content-Type deve ser "+mergepatch.ContentType, http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+
+	patch, err = io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchBytes))
+	if err != nil {
+		http.Error(w, "Requisição inválida: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return patch, true
+}
@@ -0,0 +1,162 @@
+// handlers/program_handler.go
+package handlers
+
+import (
+	"college_api/logging"
+	"college_api/models"
+	"college_api/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// ProgramHandler gerencia as requisições HTTP para cursos, o vínculo dos alunos e a auditoria de formatura.
+type ProgramHandler struct {
+	service *services.ProgramService
+}
+
+// NewProgramHandler cria uma nova instância de ProgramHandler.
+func NewProgramHandler(s *services.ProgramService) *ProgramHandler {
+	return &ProgramHandler{service: s}
+}
+
+// CreateProgramHandler lida com a criação de um curso.
+// POST /programs
+func (h *ProgramHandler) CreateProgramHandler(w http.ResponseWriter, r *http.Request) {
+	var program models.Program
+	if err := json.NewDecoder(r.Body).Decode(&program); err != nil {
+		http.Error(w, "Requisição inválida: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.CreateProgram(r.Context(), &program); err != nil {
+		writeProgramError(w, r, err, "criar curso")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(program.Version))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(program)
+}
+
+// GetAllProgramsHandler lista os cursos com suas grades.
+// GET /programs
+func (h *ProgramHandler) GetAllProgramsHandler(w http.ResponseWriter, r *http.Request) {
+	programs, err := h.service.GetAllPrograms(r.Context())
+	if err != nil {
+		writeProgramError(w, r, err, "buscar cursos")
+		return
+	}
+	writeListJSON(w, r, programs)
+}
+
+// GetProgramByIDHandler busca um curso pelo código.
+// GET /programs/{id}
+func (h *ProgramHandler) GetProgramByIDHandler(w http.ResponseWriter, r *http.Request) {
+	program, err := h.service.GetProgramByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeProgramError(w, r, err, "buscar curso")
+		return
+	}
+	writeVersionedJSON(w, r, program.Version, program)
+}
+
+// UpdateProgramHandler substitui o nome, a grade e os créditos mínimos de um curso.
+// PUT /programs/{id} (exige If-Match com a ETag atual)
+func (h *ProgramHandler) UpdateProgramHandler(w http.ResponseWriter, r *http.Request) {
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	var program models.Program
+	if err := json.NewDecoder(r.Body).Decode(&program); err != nil {
+		http.Error(w, "Requisição inválida: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	program.ID = mux.Vars(r)["id"] // Garante que o ID da URL seja usado
+	program.Version = version
+
+	if err := h.service.UpdateProgram(r.Context(), &program); err != nil {
+		writeProgramError(w, r, err, "atualizar curso")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(program.Version))
+	json.NewEncoder(w).Encode(program)
+}
+
+// DeleteProgramHandler remove um curso sem alunos vinculados.
+// DELETE /programs/{id} (exige If-Match com a ETag atual)
+func (h *ProgramHandler) DeleteProgramHandler(w http.ResponseWriter, r *http.Request) {
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteProgram(r.Context(), mux.Vars(r)["id"], version); err != nil {
+		writeProgramError(w, r, err, "remover curso")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AssignStudentProgramHandler vincula um aluno a um curso (program_id vazio desvincula).
+// PUT /students/{id}/program (exige If-Match com a ETag atual do aluno)
+// Corpo: {"program_id": "BSI"}
+func (h *ProgramHandler) AssignStudentProgramHandler(w http.ResponseWriter, r *http.Request) {
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	var assignment models.StudentProgramAssignment
+	if err := json.NewDecoder(r.Body).Decode(&assignment); err != nil {
+		http.Error(w, "Requisição inválida: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	student, err := h.service.AssignStudentProgram(r.Context(), mux.Vars(r)["id"], assignment.ProgramID, version)
+	if err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, "O aluno foi alterado por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+			return
+		}
+		writeProgramError(w, r, err, "vincular aluno ao curso")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(student.Version))
+	json.NewEncoder(w).Encode(student)
+}
+
+// DegreeAuditHandler compara as matérias do aluno com a grade do seu curso e lista o que falta.
+// GET /students/{id}/degree-audit
+func (h *ProgramHandler) DegreeAuditHandler(w http.ResponseWriter, r *http.Request) {
+	audit, err := h.service.DegreeAudit(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeProgramError(w, r, err, "auditar formatura")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(audit)
+}
+
+// writeProgramError traduz os erros do serviço de cursos em status HTTP.
+func writeProgramError(w http.ResponseWriter, r *http.Request, err error, action string) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrValidation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrVersionConflict):
+		http.Error(w, "O curso foi alterado por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+	default:
+		logging.FromContext(r.Context()).Error("erro ao "+action+" no serviço", "error", err)
+		http.Error(w, "Erro ao "+action+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
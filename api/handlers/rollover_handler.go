@@ -0,0 +1,112 @@
+// handlers/rollover_handler.go
+package handlers
+
+import (
+	"college_api/logging"
+	"college_api/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// RolloverHandler gerencia as rotas da virada de ano letivo.
+type RolloverHandler struct {
+	service *services.RolloverService
+}
+
+// NewRolloverHandler cria uma nova instância de RolloverHandler.
+func NewRolloverHandler(s *services.RolloverService) *RolloverHandler {
+	return &RolloverHandler{service: s}
+}
+
+// RunRolloverHandler executa (ou, com dry_run, apenas pré-visualiza) a virada de ano letivo.
+// Sem parâmetros, usa services.DefaultRolloverCriteria.
+// POST /admin/rollover?dry_run=&min_credit_share=0.75&final_year=4
+func (h *RolloverHandler) RunRolloverHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := boolQueryParam(r, "dry_run")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	criteria := services.DefaultRolloverCriteria
+	if v := r.URL.Query().Get("min_credit_share"); v != "" {
+		share, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			http.Error(w, "Parâmetro min_credit_share inválido: "+v, http.StatusBadRequest)
+			return
+		}
+		criteria.MinCreditShare = share
+	}
+	if r.URL.Query().Get("final_year") != "" {
+		if criteria.FinalYear, err = intQueryParam(r, "final_year"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.service.Run(r.Context(), criteria, dryRun)
+	if err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao executar virada de ano letivo no serviço", "error", err)
+		http.Error(w, "Erro ao executar virada de ano letivo: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// ListRolloversHandler lista as viradas mais recentes.
+// GET /admin/rollovers?limit=20
+func (h *RolloverHandler) ListRolloversHandler(w http.ResponseWriter, r *http.Request) {
+	limit, err := intQueryParam(r, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	runs, err := h.service.ListRuns(r.Context(), limit)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("erro ao listar viradas de ano letivo no serviço", "error", err)
+		http.Error(w, "Erro ao listar viradas de ano letivo: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
+
+// UndoRolloverHandler desfaz uma virada dentro da janela permitida.
+// POST /admin/rollovers/{id}:undo
+func (h *RolloverHandler) UndoRolloverHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de virada inválido", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.service.Undo(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, services.ErrConflict):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			logging.FromContext(r.Context()).Error("erro ao desfazer virada de ano letivo no serviço", "error", err)
+			http.Error(w, "Erro ao desfazer virada de ano letivo: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
@@ -2,10 +2,11 @@
 package handlers
 
 import (
+	"college_api/logging"
 	"college_api/models"
 	"college_api/services"
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -33,11 +34,13 @@ func (h *StudentHandler) CreateStudentHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// O serviço agora validará e gerará a matrícula.
-	if err := h.service.CreateStudent(&student); err != nil {
-		log.Printf("Erro ao criar aluno no serviço: %v", err)
-		// Aqui, você pode refinar o tratamento de erro para retornar 400 Bad Request
-		// se o erro for de validação (ex: turno inválido).
-		// Por enquanto, manteremos 500 para simplicidade, mas um tratamento mais granular seria melhor.
+	if err := h.service.CreateStudent(r.Context(), &student); err != nil {
+		// Erros de validação (nome, turno, matérias inexistentes) voltam 400 com a lista do que foi recusado
+		if errors.Is(err, services.ErrValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao criar aluno no serviço", "error", err)
 		http.Error(w, "Erro ao criar aluno: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -53,41 +56,53 @@ func (h *StudentHandler) GetStudentByIDHandler(w http.ResponseWriter, r *http.Re
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	student, err := h.service.GetStudentByID(id)
+	student, err := h.service.GetStudentByID(r.Context(), id)
 	if err != nil {
-		if err.Error() == "aluno não encontrado" { // Erro personalizado do serviço
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		log.Printf("Erro ao buscar aluno no serviço: %v", err)
+		logging.FromContext(r.Context()).Error("erro ao buscar aluno no serviço", "error", err)
 		http.Error(w, "Erro ao buscar aluno: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if student == nil {
+		http.Error(w, "aluno não encontrado", http.StatusNotFound)
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(student)
+	writeVersionedJSON(w, r, student.Version, student)
 }
 
 // GetAllStudentsHandler lida com a busca de todos os alunos.
-// GET /students
+// GET /students?name=&shift=&current_year=&subject_id=
 func (h *StudentHandler) GetAllStudentsHandler(w http.ResponseWriter, r *http.Request) {
-	students, err := h.service.GetAllStudents()
+	filter, err := studentFilterFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	students, err := h.service.GetAllStudents(r.Context(), filter)
 	if err != nil {
-		log.Printf("Erro ao buscar todos os alunos no serviço: %v", err)
+		if errors.Is(err, services.ErrValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao buscar todos os alunos no serviço", "error", err)
 		http.Error(w, "Erro ao buscar alunos: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(students)
+	writeListJSON(w, r, students)
 }
 
 // UpdateStudentHandler lida com a atualização de um aluno existente.
-// PUT /students/{id}
+// PUT /students/{id} (exige If-Match com a ETag atual)
 func (h *StudentHandler) UpdateStudentHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
 	var student models.Student
 	if err := json.NewDecoder(r.Body).Decode(&student); err != nil {
 		http.Error(w, "Requisição inválida: "+err.Error(), http.StatusBadRequest)
@@ -95,34 +110,89 @@ func (h *StudentHandler) UpdateStudentHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	student.ID = id // Garante que o ID da URL seja usado
+	student.Version = version
 
-	if err := h.service.UpdateStudent(&student); err != nil {
-		if err.Error() == "aluno não encontrado para atualização" {
+	if err := h.service.UpdateStudent(r.Context(), &student); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		log.Printf("Erro ao atualizar aluno no serviço: %v", err)
+		if errors.Is(err, services.ErrValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, "O aluno foi alterado por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao atualizar aluno no serviço", "error", err)
 		http.Error(w, "Erro ao atualizar aluno: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(student.Version))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(student)
 }
 
+// PatchStudentHandler lida com a atualização parcial de um aluno via JSON Merge Patch (RFC 7396).
+// PATCH /students/{id} (exige If-Match com a ETag atual)
+func (h *StudentHandler) PatchStudentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	patch, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	student, err := h.service.PatchStudent(r.Context(), id, patch, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, services.ErrValidation):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrVersionConflict):
+			http.Error(w, "O aluno foi alterado por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+		default:
+			logging.FromContext(r.Context()).Error("erro ao atualizar parcialmente aluno no serviço", "error", err)
+			http.Error(w, "Erro ao atualizar aluno: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(student.Version))
+	json.NewEncoder(w).Encode(student)
+}
+
 // DeleteStudentHandler lida com a exclusão de um aluno por ID.
-// DELETE /students/{id}
+// DELETE /students/{id} (exige If-Match com a ETag atual)
 func (h *StudentHandler) DeleteStudentHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	if err := h.service.DeleteStudent(id); err != nil {
-		if err.Error() == "aluno não encontrado para exclusão" {
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteStudent(r.Context(), id, version); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		log.Printf("Erro ao deletar aluno no serviço: %v", err)
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, "O aluno foi alterado por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao deletar aluno no serviço", "error", err)
 		http.Error(w, "Erro ao deletar aluno: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -138,12 +208,16 @@ func (h *StudentHandler) AddSubjectToStudentHandler(w http.ResponseWriter, r *ht
 	studentID := vars["studentID"]
 	subjectID := vars["subjectID"]
 
-	if err := h.service.AddSubjectToStudent(studentID, subjectID); err != nil {
-		if err.Error() == "aluno não encontrado" || err.Error() == "matéria não encontrada" || err.Error() == "matéria já associada a este aluno" {
-			http.Error(w, err.Error(), http.StatusBadRequest) // 400 Bad Request para erros de validação
+	if err := h.service.AddSubjectToStudent(r.Context(), studentID, subjectID); err != nil {
+		if errors.Is(err, services.ErrConflict) {
+			http.Error(w, err.Error(), http.StatusConflict)
 			return
 		}
-		log.Printf("Erro ao adicionar matéria ao aluno no serviço: %v", err)
+		if errors.Is(err, services.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound) // Aluno ou matéria inexistente
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao adicionar matéria ao aluno no serviço", "error", err)
 		http.Error(w, "Erro ao adicionar matéria ao aluno: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -160,12 +234,12 @@ func (h *StudentHandler) RemoveSubjectFromStudentHandler(w http.ResponseWriter,
 	studentID := vars["studentID"]
 	subjectID := vars["subjectID"]
 
-	if err := h.service.RemoveSubjectFromStudent(studentID, subjectID); err != nil {
-		if err.Error() == "aluno não encontrado" || err.Error() == "matéria não associada a este aluno" {
+	if err := h.service.RemoveSubjectFromStudent(r.Context(), studentID, subjectID); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound) // 404 Not Found para associação inexistente
 			return
 		}
-		log.Printf("Erro ao remover matéria do aluno no serviço: %v", err)
+		logging.FromContext(r.Context()).Error("erro ao remover matéria do aluno no serviço", "error", err)
 		http.Error(w, "Erro ao remover matéria do aluno: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -173,3 +247,84 @@ func (h *StudentHandler) RemoveSubjectFromStudentHandler(w http.ResponseWriter,
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent) // 204 No Content
 }
+
+// RestoreStudentHandler lida com a restauração de um aluno excluído logicamente.
+// POST /students/{id}:restore
+func (h *StudentHandler) RestoreStudentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	student, err := h.service.RestoreStudent(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao restaurar aluno no serviço", "error", err)
+		http.Error(w, "Erro ao restaurar aluno: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(student.Version))
+	json.NewEncoder(w).Encode(student)
+}
+
+// ChangeStudentStatusHandler muda a situação de um aluno (ver services.StudentStatusTransitions).
+// POST /students/{id}/status (exige If-Match com a ETag atual)
+// Corpo: {"status": "suspended", "reason": "...", "effective_date": "2025-03-10"}
+func (h *StudentHandler) ChangeStudentStatusHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	var change models.StudentStatusChange
+	if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+		http.Error(w, "Requisição inválida: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	student, _, err := h.service.ChangeStudentStatus(r.Context(), id, change, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, services.ErrValidation):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrConflict):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, services.ErrVersionConflict):
+			http.Error(w, "O aluno foi alterado por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+		default:
+			logging.FromContext(r.Context()).Error("erro ao mudar situação do aluno no serviço", "error", err)
+			http.Error(w, "Erro ao mudar situação do aluno: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(student.Version))
+	json.NewEncoder(w).Encode(student)
+}
+
+// GetStudentStatusHistoryHandler lista as mudanças de situação de um aluno, da mais antiga à mais recente.
+// GET /students/{id}/status-history
+func (h *StudentHandler) GetStudentStatusHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	history, err := h.service.GetStudentStatusHistory(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao buscar histórico de situações do aluno no serviço", "error", err)
+		http.Error(w, "Erro ao buscar histórico de situações: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeListJSON(w, r, history)
+}
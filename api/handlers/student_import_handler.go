@@ -0,0 +1,76 @@
+// handlers/student_import_handler.go
+package handlers
+
+import (
+	"college_api/logging"
+	"college_api/services"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// maxImportBytes limita o tamanho do arquivo CSV aceito na importação.
+const maxImportBytes = 10 << 20
+
+// ImportStudentsHandler lida com a importação em lote de alunos a partir de um CSV.
+// O arquivo pode vir no corpo (text/csv) ou em um formulário multipart no campo "file".
+// Com ?dry_run=true tudo é validado e nada é gravado.
+// POST /students/import
+func (h *StudentHandler) ImportStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := boolQueryParam(r, "dry_run")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	var file io.Reader = r.Body
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(maxImportBytes); err != nil {
+			http.Error(w, "Formulário inválido: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		part, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Envie o arquivo CSV no campo \"file\"", http.StatusBadRequest)
+			return
+		}
+		defer part.Close()
+		file = part
+	case "text/csv", "text/plain", "application/csv", "":
+	default:
+		http.Error(w, "Content-Type não suportado: envie text/csv ou multipart/form-data", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	report, err := h.service.ImportStudentsCSV(r.Context(), file, dryRun)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			http.Error(w, "Arquivo maior que o limite de 10 MB", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, services.ErrValidation):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrConflict):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			logging.FromContext(r.Context()).Error("erro ao importar alunos no serviço", "error", err)
+			http.Error(w, "Erro ao importar alunos: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if report.Invalid > 0 {
+		status = http.StatusUnprocessableEntity
+	} else if report.Committed {
+		status = http.StatusCreated
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -2,10 +2,11 @@
 package handlers
 
 import (
+	"college_api/logging"
 	"college_api/models"
 	"college_api/services"
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -30,8 +31,8 @@ func (h *SubjectHandler) CreateSubjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := h.service.CreateSubject(&subject); err != nil {
-		log.Printf("Erro ao criar matéria no serviço: %v", err)
+	if err := h.service.CreateSubject(r.Context(), &subject); err != nil {
+		logging.FromContext(r.Context()).Error("erro ao criar matéria no serviço", "error", err)
 		http.Error(w, "Erro ao criar matéria: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -47,41 +48,53 @@ func (h *SubjectHandler) GetSubjectByIDHandler(w http.ResponseWriter, r *http.Re
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	subject, err := h.service.GetSubjectByID(id)
+	subject, err := h.service.GetSubjectByID(r.Context(), id)
 	if err != nil {
-		if err.Error() == "matéria não encontrada" { // Erro personalizado do serviço
+		if errors.Is(err, services.ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		log.Printf("Erro ao buscar matéria no serviço: %v", err)
+		logging.FromContext(r.Context()).Error("erro ao buscar matéria no serviço", "error", err)
 		http.Error(w, "Erro ao buscar matéria: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(subject)
+	writeVersionedJSON(w, r, subject.Version, subject)
 }
 
 // GetAllSubjectsHandler lida com a busca de todas as matérias.
-// GET /subjects
+// GET /subjects?name=&year=
 func (h *SubjectHandler) GetAllSubjectsHandler(w http.ResponseWriter, r *http.Request) {
-	subjects, err := h.service.GetAllSubjects()
+	filter, err := subjectFilterFromQuery(r)
 	if err != nil {
-		log.Printf("Erro ao buscar todas as matérias no serviço: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	subjects, err := h.service.GetAllSubjects(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao buscar todas as matérias no serviço", "error", err)
 		http.Error(w, "Erro ao buscar matérias: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(subjects)
+	writeListJSON(w, r, subjects)
 }
 
 // UpdateSubjectHandler lida com a atualização de uma matéria existente.
-// PUT /subjects/{id}
+// PUT /subjects/{id} (exige If-Match com a ETag atual)
 func (h *SubjectHandler) UpdateSubjectHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
 	var subject models.Subject
 	if err := json.NewDecoder(r.Body).Decode(&subject); err != nil {
 		http.Error(w, "Requisição inválida: "+err.Error(), http.StatusBadRequest)
@@ -89,34 +102,89 @@ func (h *SubjectHandler) UpdateSubjectHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	subject.ID = id // Garante que o ID da URL seja usado
+	subject.Version = version
 
-	if err := h.service.UpdateSubject(&subject); err != nil {
-		if err.Error() == "matéria não encontrada para atualização" {
+	if err := h.service.UpdateSubject(r.Context(), &subject); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, "A matéria foi alterada por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, services.ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		log.Printf("Erro ao atualizar matéria no serviço: %v", err)
+		if errors.Is(err, services.ErrValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao atualizar matéria no serviço", "error", err)
 		http.Error(w, "Erro ao atualizar matéria: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(subject.Version))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(subject)
 }
 
+// PatchSubjectHandler lida com a atualização parcial de uma matéria via JSON Merge Patch (RFC 7396).
+// PATCH /subjects/{id} (exige If-Match com a ETag atual)
+func (h *SubjectHandler) PatchSubjectHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	patch, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	subject, err := h.service.PatchSubject(r.Context(), id, patch, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, services.ErrValidation):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrVersionConflict):
+			http.Error(w, "A matéria foi alterada por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+		default:
+			logging.FromContext(r.Context()).Error("erro ao atualizar parcialmente matéria no serviço", "error", err)
+			http.Error(w, "Erro ao atualizar matéria: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(subject.Version))
+	json.NewEncoder(w).Encode(subject)
+}
+
 // DeleteSubjectHandler lida com a exclusão de uma matéria por ID.
-// DELETE /subjects/{id}
+// DELETE /subjects/{id} (exige If-Match com a ETag atual)
 func (h *SubjectHandler) DeleteSubjectHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	if err := h.service.DeleteSubject(id); err != nil {
-		if err.Error() == "matéria não encontrada para exclusão" {
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSubject(r.Context(), id, version); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, "A matéria foi alterada por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, services.ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		log.Printf("Erro ao deletar matéria no serviço: %v", err)
+		logging.FromContext(r.Context()).Error("erro ao deletar matéria no serviço", "error", err)
 		http.Error(w, "Erro ao deletar matéria: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -124,3 +192,25 @@ func (h *SubjectHandler) DeleteSubjectHandler(w http.ResponseWriter, r *http.Req
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent) // 204 No Content para deleção bem-sucedida
 }
+
+// RestoreSubjectHandler lida com a restauração de uma matéria excluída logicamente.
+// POST /subjects/{id}:restore
+func (h *SubjectHandler) RestoreSubjectHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	subject, err := h.service.RestoreSubject(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao restaurar matéria no serviço", "error", err)
+		http.Error(w, "Erro ao restaurar matéria: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(subject.Version))
+	json.NewEncoder(w).Encode(subject)
+}
@@ -2,10 +2,11 @@
 package handlers
 
 import (
+	"college_api/logging"
 	"college_api/models"
 	"college_api/services"
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -30,8 +31,12 @@ func (h *TeacherHandler) CreateTeacherHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := h.service.CreateTeacher(&teacher); err != nil {
-		log.Printf("Erro ao criar professor no serviço: %v", err)
+	if err := h.service.CreateTeacher(r.Context(), &teacher); err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao criar professor no serviço", "error", err)
 		http.Error(w, "Erro ao criar professor: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -47,41 +52,44 @@ func (h *TeacherHandler) GetTeacherByIDHandler(w http.ResponseWriter, r *http.Re
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	teacher, err := h.service.GetTeacherByID(id)
+	teacher, err := h.service.GetTeacherByID(r.Context(), id)
 	if err != nil {
-		if err.Error() == "professor não encontrado" { // Erro personalizado do serviço
+		if errors.Is(err, services.ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		log.Printf("Erro ao buscar professor no serviço: %v", err)
+		logging.FromContext(r.Context()).Error("erro ao buscar professor no serviço", "error", err)
 		http.Error(w, "Erro ao buscar professor: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(teacher)
+	writeVersionedJSON(w, r, teacher.Version, teacher)
 }
 
 // GetAllTeachersHandler lida com a busca de todos os professores.
-// GET /teachers
+// GET /teachers?name=&department=&department_id= (department aceita o código ou o nome)
 func (h *TeacherHandler) GetAllTeachersHandler(w http.ResponseWriter, r *http.Request) {
-	teachers, err := h.service.GetAllTeachers()
+	teachers, err := h.service.GetAllTeachers(r.Context(), teacherFilterFromQuery(r))
 	if err != nil {
-		log.Printf("Erro ao buscar todos os professores no serviço: %v", err)
+		logging.FromContext(r.Context()).Error("erro ao buscar todos os professores no serviço", "error", err)
 		http.Error(w, "Erro ao buscar professores: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(teachers)
+	writeListJSON(w, r, teachers)
 }
 
 // UpdateTeacherHandler lida com a atualização de um professor existente.
-// PUT /teachers/{id}
+// PUT /teachers/{id} (exige If-Match com a ETag atual)
 func (h *TeacherHandler) UpdateTeacherHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
 	var teacher models.Teacher
 	if err := json.NewDecoder(r.Body).Decode(&teacher); err != nil {
 		http.Error(w, "Requisição inválida: "+err.Error(), http.StatusBadRequest)
@@ -89,34 +97,99 @@ func (h *TeacherHandler) UpdateTeacherHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	teacher.ID = id // Garante que o ID da URL seja usado
+	teacher.Version = version
 
-	if err := h.service.UpdateTeacher(&teacher); err != nil {
-		if err.Error() == "professor não encontrado para atualização" {
+	if err := h.service.UpdateTeacher(r.Context(), &teacher); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, "O professor foi alterado por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, services.ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		log.Printf("Erro ao atualizar professor no serviço: %v", err)
+		if errors.Is(err, services.ErrValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, services.ErrConflict) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao atualizar professor no serviço", "error", err)
 		http.Error(w, "Erro ao atualizar professor: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(teacher.Version))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(teacher)
 }
 
+// PatchTeacherHandler lida com a atualização parcial de um professor via JSON Merge Patch (RFC 7396).
+// PATCH /teachers/{id} (exige If-Match com a ETag atual)
+func (h *TeacherHandler) PatchTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	patch, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	teacher, err := h.service.PatchTeacher(r.Context(), id, patch, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, services.ErrValidation):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrConflict):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, services.ErrVersionConflict):
+			http.Error(w, "O professor foi alterado por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+		default:
+			logging.FromContext(r.Context()).Error("erro ao atualizar parcialmente professor no serviço", "error", err)
+			http.Error(w, "Erro ao atualizar professor: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(teacher.Version))
+	json.NewEncoder(w).Encode(teacher)
+}
+
 // DeleteTeacherHandler lida com a exclusão de um professor por ID.
-// DELETE /teachers/{id}
+// DELETE /teachers/{id} (exige If-Match com a ETag atual)
 func (h *TeacherHandler) DeleteTeacherHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	if err := h.service.DeleteTeacher(id); err != nil {
-		if err.Error() == "professor não encontrado para exclusão" {
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTeacher(r.Context(), id, version); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, "O professor foi alterado por outra pessoa; recarregue e tente novamente", http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, services.ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		log.Printf("Erro ao deletar professor: %v", err)
+		if errors.Is(err, services.ErrConflict) { // Chefe de departamento
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao deletar professor", "error", err)
 		http.Error(w, "Erro ao deletar professor: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -124,3 +197,25 @@ func (h *TeacherHandler) DeleteTeacherHandler(w http.ResponseWriter, r *http.Req
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent) // 204 No Content para deleção bem-sucedida
 }
+
+// RestoreTeacherHandler lida com a restauração de um professor excluído logicamente.
+// POST /teachers/{id}:restore
+func (h *TeacherHandler) RestoreTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	teacher, err := h.service.RestoreTeacher(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		logging.FromContext(r.Context()).Error("erro ao restaurar professor no serviço", "error", err)
+		http.Error(w, "Erro ao restaurar professor: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagFor(teacher.Version))
+	json.NewEncoder(w).Encode(teacher)
+}
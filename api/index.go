@@ -2,10 +2,16 @@
 package handler // O pacote deve ser 'handler' para Vercel Functions
 
 import (
+	"college_api/cache"
 	"college_api/config" // Importa o config do seu módulo Go
 	"college_api/handlers"
+	"college_api/logging"
+	"college_api/metrics"
+	"college_api/middleware"
 	"college_api/repositories"
 	"college_api/services"
+	"college_api/tracing"
+	"context"
 	"log"
 	"net/http"
 
@@ -31,71 +37,279 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 
 // initAPI inicializa todas as dependências da aplicação
 func initAPI() {
-	// A DATABASE_URL será definida via variável de ambiente da Vercel.
-	config.InitDB() // Inicializa o banco de dados PostgreSQL
-	// NOTE: defer config.CloseDB() não é usado em Serverless Functions
-	// A conexão é mantida viva pela plataforma entre invocações.
-
-	log.Println("Backend da universidade inicializando para Vercel Function...")
+	// --- Configuração (variáveis de ambiente da Vercel ou .env local), validada de uma vez ---
+	cfg, _, err := config.Load(nil)
+	if err != nil {
+		log.Fatalf("Configuração inválida:\n%v", err)
+	}
 
-	// --- Inicializando Repositórios e Serviços ---
-	subjectRepo := repositories.NewSubjectRepository()
-	studentRepo := repositories.NewStudentRepository()
-	teacherRepo := repositories.NewTeacherRepository()
+	// --- Logger estruturado (LOG_FORMAT e LOG_LEVEL) ---
+	logger := logging.Setup(cfg.Log)
 
-	subjectService := services.NewSubjectService(subjectRepo)
-	studentService := services.NewStudentService(studentRepo, subjectRepo)
-	teacherService := services.NewTeacherService(teacherRepo)
+	// --- Rastreamento OpenTelemetry (OTEL_TRACES_EXPORTER: none, otlp ou stdout) ---
+	// NOTE: o shutdown do exportador não é chamado em Serverless Functions; o batcher exporta periodicamente.
+	if _, err := tracing.Setup(context.Background()); err != nil {
+		log.Fatal(err)
+	}
 
-	// --- Inicializando Handlers ---
-	subjectHandler := handlers.NewSubjectHandler(subjectService)
-	studentHandler := handlers.NewStudentHandler(studentService)
-	teacherHandler := handlers.NewTeacherHandler(teacherService)
+	// Falhas de conexão ou de migração não derrubam a função: elas ficam visíveis em /readyz (503).
+	db, err := config.OpenDB(cfg.DB)
+	if db == nil {
+		log.Fatal(err)
+	}
+	if err != nil {
+		logger.Error("initAPI: banco de dados indisponível na inicialização; novas tentativas a cada requisição, com backoff", "error", err)
+	}
+	reconnector := config.NewReconnector(db, cfg.DB, err)
+	metrics.RegisterDB(db)
+	repositories.SetQueryTimeout(cfg.DB.QueryTimeout)
+	// NOTE: db.Close() não é usado em Serverless Functions
+	// O pool sobrevive entre invocações enquanto a instância estiver ativa; DB_CONN_MAX_IDLE_TIME descarta
+	// as conexões que ficaram paradas com a instância congelada, e DB_MAX_OPEN_CONNS limita cada instância.
 
-	// --- Configurando o Roteador Mux ---
-	router = mux.NewRouter()
+	logger.Info("Backend da universidade inicializando para Vercel Function...")
 
-	// Rotas para Matérias
-	router.HandleFunc("/subjects", subjectHandler.CreateSubjectHandler).Methods("POST")
-	router.HandleFunc("/subjects", subjectHandler.GetAllSubjectsHandler).Methods("GET")
-	router.HandleFunc("/subjects/{id}", subjectHandler.GetSubjectByIDHandler).Methods("GET")
-	router.HandleFunc("/subjects/{id}", subjectHandler.UpdateSubjectHandler).Methods("PUT")
-	router.HandleFunc("/subjects/{id}", subjectHandler.DeleteSubjectHandler).Methods("DELETE")
+	// --- Inicializando Repositórios e Serviços ---
+	subjectRepo := repositories.NewSubjectRepository(db)
+	studentRepo := repositories.NewStudentRepository(db)
+	teacherRepo := repositories.NewTeacherRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	rolloverRepo := repositories.NewRolloverRepository(db)
+	programRepo := repositories.NewProgramRepository(db)
+	departmentRepo := repositories.NewDepartmentRepository(db)
+	healthRepo := repositories.NewHealthRepository(db)
+	unitOfWork := repositories.NewUnitOfWork(db) // Transações que envolvem vários repositórios
 
-	// Rotas para Alunos
-	router.HandleFunc("/students", studentHandler.CreateStudentHandler).Methods("POST")
-	router.HandleFunc("/students", studentHandler.GetAllStudentsHandler).Methods("GET")
-	router.HandleFunc("/students/{id}", studentHandler.GetStudentByIDHandler).Methods("GET")
-	router.HandleFunc("/students/{id}", studentHandler.UpdateStudentHandler).Methods("PUT")
-	router.HandleFunc("/students/{id}", studentHandler.DeleteStudentHandler).Methods("DELETE")
+	// --- Cache de leitura de matérias e professores (CACHE_ENABLED, CACHE_TTL e CACHE_MAX_ENTRIES) ---
+	subjectCache := newReadCache(cfg.Cache)
+	teacherCache := newReadCache(cfg.Cache)
 
-	// Rotas para associação Aluno-Matéria
-	router.HandleFunc("/students/{studentID}/subjects/{subjectID}", studentHandler.AddSubjectToStudentHandler).Methods("POST")
-	router.HandleFunc("/students/{studentID}/subjects/{subjectID}", studentHandler.RemoveSubjectFromStudentHandler).Methods("DELETE")
+	subjectService := services.NewSubjectService(subjectRepo, subjectCache)
+	studentService := services.NewStudentService(studentRepo, subjectRepo, unitOfWork, subjectCache)
+	teacherService := services.NewTeacherService(teacherRepo, departmentRepo, teacherCache)
+	auditService := services.NewAuditService(auditRepo)
+	purgeService := services.NewPurgeService(studentRepo, subjectRepo, teacherRepo)
+	programService := services.NewProgramService(programRepo, studentRepo, subjectRepo)
+	departmentService := services.NewDepartmentService(departmentRepo, teacherRepo, teacherCache)
+	healthService := services.NewHealthService(healthRepo, reconnector)
+	rolloverService := services.NewRolloverService(studentRepo, rolloverRepo, cfg.RolloverUndoWindow)
 
-	// --- ROTAS PARA PROFESSORES ---
-	router.HandleFunc("/teachers", teacherHandler.CreateTeacherHandler).Methods("POST")
-	router.HandleFunc("/teachers", teacherHandler.GetAllTeachersHandler).Methods("GET")
-	router.HandleFunc("/teachers/{id}", teacherHandler.GetTeacherByIDHandler).Methods("GET")
-	router.HandleFunc("/teachers/{id}", teacherHandler.UpdateTeacherHandler).Methods("PUT")
-	router.HandleFunc("/teachers/{id}", teacherHandler.DeleteTeacherHandler).Methods("DELETE")
+	// --- Inicializando Handlers e Roteador Mux ---
+	router = mux.NewRouter()
+	registerRoutes(router, apiHandlers{
+		subject:    handlers.NewSubjectHandler(subjectService),
+		student:    handlers.NewStudentHandler(studentService),
+		teacher:    handlers.NewTeacherHandler(teacherService),
+		audit:      handlers.NewAuditHandler(auditService),
+		admin:      handlers.NewAdminHandler(purgeService, cfg.PurgeRetention),
+		rollover:   handlers.NewRolloverHandler(rolloverService),
+		program:    handlers.NewProgramHandler(programService),
+		department: handlers.NewDepartmentHandler(departmentService),
+		health:     handlers.NewHealthHandler(healthService),
+	}, cfg.Features)
 
 	// --- Configuração do CORS ---
 	// Em Vercel Functions, o CORS deve ser tratado pelo 'vercel.json' nos headers,
 	// mas é bom ter no código também como fallback ou para testes locais.
 	corsHandler := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type"},
-		AllowCredentials: true,
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "X-API-Key", "X-Request-ID", "If-Match", "If-None-Match", "traceparent", "tracestate"},
+		ExposedHeaders:   []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "X-Request-ID", "ETag", "Content-Disposition"},
+		AllowCredentials: cfg.CORS.AllowCredentials,
 		Debug:            false, // Defina como false em produção
 	})
 
 	// Aplica o middleware CORS ao seu roteador
 	router.Use(mux.MiddlewareFunc(corsHandler.Handler)) // Usa mux.MiddlewareFunc para integrar o handler como middleware
 
-	log.Println("Backend da universidade inicializado com sucesso para Vercel Function!")
+	// --- ID da requisição (propagado para auditoria e logs) ---
+	router.Use(middleware.RequestID)
+
+	// --- Span por requisição, continuando o trace W3C de quem chamou ---
+	router.Use(middleware.Tracing)
+
+	// --- Log de acesso e logger da requisição no contexto ---
+	router.Use(middleware.AccessLog(logger))
+
+	// --- Métricas de tráfego por rota (expostas em /metrics) ---
+	router.Use(middleware.Metrics)
+
+	// --- Limite por IP antes da autenticação: conta também as requisições recusadas com 401 ---
+	router.Use(middleware.RateLimit(ipRateLimitOptions(cfg.RateLimit)))
+
+	// --- Autenticação por chave de API (API_KEYS; sem chaves, a API é aberta) ---
+	// Antes do rate limit por cliente, que identifica o cliente pelo principal validado aqui
+	router.Use(middleware.APIKeyAuth(cfg.Auth.APIKeys, publicPaths...))
+
+	// --- Limitação de requisições por cliente (principal autenticado ou IP) ---
+	router.Use(middleware.RateLimit(rateLimitOptions(cfg.RateLimit)))
+
+	// --- 503 enquanto a inicialização do banco não for concluída (ver config.Reconnector) ---
+	router.Use(middleware.RequireDatabase(reconnector, append([]string{"/metrics"}, publicPaths...)...))
+
+	logger.Info("Backend da universidade inicializado com sucesso para Vercel Function!")
 
 	// Remover o http.ListenAndServe pois a Vercel Function não é um servidor tradicional
 	// log.Fatal(srv.ListenAndServe())
 }
+
+// apiHandlers reúne os handlers registrados por registerRoutes.
+type apiHandlers struct {
+	subject    *handlers.SubjectHandler
+	student    *handlers.StudentHandler
+	teacher    *handlers.TeacherHandler
+	audit      *handlers.AuditHandler
+	admin      *handlers.AdminHandler
+	rollover   *handlers.RolloverHandler
+	program    *handlers.ProgramHandler
+	department *handlers.DepartmentHandler
+	health     *handlers.HealthHandler
+}
+
+// registerRoutes registra todas as rotas da API em router. Toda rota registrada aqui precisa estar
+// descrita em openapi/routes.go (conferido em index_test.go).
+func registerRoutes(router *mux.Router, h apiHandlers, features config.FeatureConfig) {
+	// Rotas para Matérias
+	router.HandleFunc("/subjects", h.subject.CreateSubjectHandler).Methods("POST")
+	router.HandleFunc("/subjects", h.subject.GetAllSubjectsHandler).Methods("GET")
+	router.HandleFunc("/subjects/sync", h.subject.SyncCurriculumHandler).Methods("POST")
+	router.HandleFunc("/subjects/export", h.subject.ExportSubjectsHandler).Methods("GET") // Antes de /subjects/{id}
+	router.HandleFunc("/subjects/{id}", h.subject.GetSubjectByIDHandler).Methods("GET")
+	router.HandleFunc("/subjects/{id}", h.subject.UpdateSubjectHandler).Methods("PUT")
+	router.HandleFunc("/subjects/{id}", h.subject.PatchSubjectHandler).Methods("PATCH")
+	router.HandleFunc("/subjects/{id}", h.subject.DeleteSubjectHandler).Methods("DELETE")
+	router.HandleFunc("/subjects/{id}:restore", h.subject.RestoreSubjectHandler).Methods("POST")
+
+	// Rotas para Alunos
+	router.HandleFunc("/students", h.student.CreateStudentHandler).Methods("POST")
+	router.HandleFunc("/students", h.student.GetAllStudentsHandler).Methods("GET")
+	router.HandleFunc("/students/import", h.student.ImportStudentsHandler).Methods("POST")
+	router.HandleFunc("/students/export", h.student.ExportStudentsHandler).Methods("GET") // Antes de /students/{id}
+	router.HandleFunc("/students/{id}", h.student.GetStudentByIDHandler).Methods("GET")
+	router.HandleFunc("/students/{id}", h.student.UpdateStudentHandler).Methods("PUT")
+	router.HandleFunc("/students/{id}", h.student.PatchStudentHandler).Methods("PATCH")
+	router.HandleFunc("/students/{id}", h.student.DeleteStudentHandler).Methods("DELETE")
+	router.HandleFunc("/students/{id}:restore", h.student.RestoreStudentHandler).Methods("POST")
+	router.HandleFunc("/students/{id}/status", h.student.ChangeStudentStatusHandler).Methods("POST")
+	router.HandleFunc("/students/{id}/status-history", h.student.GetStudentStatusHistoryHandler).Methods("GET")
+	router.HandleFunc("/students/{id}/program", h.program.AssignStudentProgramHandler).Methods("PUT")
+	router.HandleFunc("/students/{id}/degree-audit", h.program.DegreeAuditHandler).Methods("GET")
+
+	// Rotas para associação Aluno-Matéria
+	router.HandleFunc("/students/{studentID}/subjects/{subjectID}", h.student.AddSubjectToStudentHandler).Methods("POST")
+	router.HandleFunc("/students/{studentID}/subjects/{subjectID}", h.student.RemoveSubjectFromStudentHandler).Methods("DELETE")
+
+	// --- ROTAS PARA PROFESSORES ---
+	router.HandleFunc("/teachers", h.teacher.CreateTeacherHandler).Methods("POST")
+	router.HandleFunc("/teachers", h.teacher.GetAllTeachersHandler).Methods("GET")
+	router.HandleFunc("/teachers/export", h.teacher.ExportTeachersHandler).Methods("GET") // Antes de /teachers/{id}
+	router.HandleFunc("/teachers/{id}", h.teacher.GetTeacherByIDHandler).Methods("GET")
+	router.HandleFunc("/teachers/{id}", h.teacher.UpdateTeacherHandler).Methods("PUT")
+	router.HandleFunc("/teachers/{id}", h.teacher.PatchTeacherHandler).Methods("PATCH")
+	router.HandleFunc("/teachers/{id}", h.teacher.DeleteTeacherHandler).Methods("DELETE")
+	router.HandleFunc("/teachers/{id}:restore", h.teacher.RestoreTeacherHandler).Methods("POST")
+
+	// --- ROTAS DE DEPARTAMENTOS ---
+	router.HandleFunc("/departments", h.department.CreateDepartmentHandler).Methods("POST")
+	router.HandleFunc("/departments", h.department.GetAllDepartmentsHandler).Methods("GET")
+	router.HandleFunc("/departments/{id}", h.department.GetDepartmentByIDHandler).Methods("GET")
+	router.HandleFunc("/departments/{id}", h.department.UpdateDepartmentHandler).Methods("PUT")
+	router.HandleFunc("/departments/{id}", h.department.DeleteDepartmentHandler).Methods("DELETE")
+	router.HandleFunc("/departments/{id}:restore", h.department.RestoreDepartmentHandler).Methods("POST")
+
+	// --- ROTAS DE CURSOS ---
+	router.HandleFunc("/programs", h.program.CreateProgramHandler).Methods("POST")
+	router.HandleFunc("/programs", h.program.GetAllProgramsHandler).Methods("GET")
+	router.HandleFunc("/programs/{id}", h.program.GetProgramByIDHandler).Methods("GET")
+	router.HandleFunc("/programs/{id}", h.program.UpdateProgramHandler).Methods("PUT")
+	router.HandleFunc("/programs/{id}", h.program.DeleteProgramHandler).Methods("DELETE")
+
+	// --- ROTA DE AUDITORIA (sempre autenticada, mesmo sem API_KEYS) ---
+	router.Handle("/audit", middleware.RequireAuthenticated(http.HandlerFunc(h.audit.GetAuditEntriesHandler))).Methods("GET")
+
+	// --- ROTAS ADMINISTRATIVAS (ENABLE_ADMIN_ROUTES) ---
+	if features.AdminRoutes {
+		router.HandleFunc("/admin/purge", h.admin.PurgeHandler).Methods("POST")
+		router.HandleFunc("/admin/rollover", h.rollover.RunRolloverHandler).Methods("POST")
+		router.HandleFunc("/admin/rollovers", h.rollover.ListRolloversHandler).Methods("GET")
+		router.HandleFunc("/admin/rollovers/{id}:undo", h.rollover.UndoRolloverHandler).Methods("POST")
+	}
+
+	// --- SAÚDE E VERSÃO (sem autenticação e sem limite de requisições) ---
+	router.HandleFunc("/healthz", h.health.HealthzHandler).Methods("GET")
+	router.HandleFunc("/readyz", h.health.ReadyzHandler).Methods("GET")
+	router.HandleFunc("/version", h.health.VersionHandler).Methods("GET")
+
+	// --- MÉTRICAS (Prometheus; ENABLE_METRICS) ---
+	if features.Metrics {
+		router.Handle("/metrics", metrics.Handler()).Methods("GET")
+	}
+
+	// --- DOCUMENTAÇÃO (OpenAPI 3.1 e página interativa) ---
+	router.HandleFunc("/openapi.json", handlers.OpenAPIHandler).Methods("GET")
+	router.HandleFunc("/docs", handlers.DocsHandler).Methods("GET")
+}
+
+// publicPaths são as rotas que nunca exigem autenticação nem passam pelo rate limit (sondas da plataforma
+// e documentação). Em openapi/routes.go elas são marcadas como public.
+var publicPaths = []string{"/healthz", "/readyz", "/version", "/openapi.json", "/docs"}
+
+// newReadCache cria um cache de leitura dos serviços conforme cfg (nil se desabilitado).
+func newReadCache(cfg config.CacheConfig) cache.Cache {
+	if !cfg.Enabled {
+		return nil
+	}
+	return cache.NewLRU(cfg.MaxEntries, cfg.TTL)
+}
+
+// rateLimitOptions monta a configuração do rate limit: o limite padrão por cliente (RATE_LIMIT_RPS e
+// RATE_LIMIT_BURST; RPS 0 desabilita), os limites das rotas mais caras e os proxies confiáveis.
+func rateLimitOptions(cfg config.RateLimitConfig) middleware.RateLimitOptions {
+	opts := middleware.RateLimitOptions{
+		Default: middleware.Limit{Rate: cfg.RPS, Burst: cfg.Burst},
+		Routes: map[string]middleware.Limit{
+			// A listagem de alunos faz uma consulta de matérias por aluno; é a rota mais cara.
+			"GET /students": {Rate: 2, Burst: 10},
+			// Cada importação pode gravar milhares de alunos em uma única transação.
+			"POST /students/import": {Rate: 0.1, Burst: 2},
+			// Exportações percorrem a tabela inteira.
+			"GET /students/export": {Rate: 0.2, Burst: 3},
+			"GET /subjects/export": {Rate: 0.2, Burst: 3},
+			"GET /teachers/export": {Rate: 0.2, Burst: 3},
+			// A virada bloqueia e atualiza todos os alunos em uma única transação.
+			"POST /admin/rollover": {Rate: 0.05, Burst: 2},
+		},
+	}
+	// Sondas de saúde e versão e a documentação nunca são limitadas (limite zero desabilita).
+	for _, path := range publicPaths {
+		opts.Routes[path] = middleware.Limit{}
+	}
+
+	opts.TrustedProxies = trustedProxies(cfg)
+	return opts
+}
+
+// ipRateLimitOptions monta o limite por IP registrado antes da autenticação (RATE_LIMIT_IP_RPS e
+// RATE_LIMIT_IP_BURST). Sem ele, quem tenta adivinhar uma chave de API recebe 401 sem passar por
+// limite nenhum. As rotas públicas também ficam de fora.
+func ipRateLimitOptions(cfg config.RateLimitConfig) middleware.RateLimitOptions {
+	opts := middleware.RateLimitOptions{
+		Default:        middleware.Limit{Rate: cfg.IPRPS, Burst: cfg.IPBurst},
+		Routes:         map[string]middleware.Limit{},
+		TrustedProxies: trustedProxies(cfg),
+		ByIP:           true,
+	}
+	for _, path := range publicPaths {
+		opts.Routes[path] = middleware.Limit{}
+	}
+	return opts
+}
+
+func trustedProxies(cfg config.RateLimitConfig) middleware.TrustedProxies {
+	proxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("TRUSTED_PROXIES inválido: %v", err) // Já validado em config.Load
+	}
+	return proxies
+}
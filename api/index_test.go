@@ -0,0 +1,37 @@
+// api/index_test.go
+package handler
+
+import (
+	"college_api/config"
+	"college_api/middleware"
+	"college_api/openapi"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// TestRoutesDocumented falha se alguma rota registrada no roteador não estiver descrita em openapi/routes.go.
+// Os handlers não são chamados, então não precisam de serviços.
+func TestRoutesDocumented(t *testing.T) {
+	router := mux.NewRouter()
+	registerRoutes(router, apiHandlers{}, config.FeatureConfig{Metrics: true, AdminRoutes: true})
+
+	if err := openapi.CheckRoutes(router); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuditRequiresAuthentication(t *testing.T) {
+	router := mux.NewRouter()
+	registerRoutes(router, apiHandlers{}, config.FeatureConfig{})
+	// Sem API_KEYS, APIKeyAuth não identifica ninguém: /audit continua fechada
+	router.Use(middleware.APIKeyAuth(nil))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/audit?entity=students", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("esperava 401, veio %d", rec.Code)
+	}
+}
@@ -0,0 +1,99 @@
+// api/logging/logging.go
+// Pacote logging configura o logger estruturado (log/slog) da aplicação, carrega-o no contexto
+// de cada requisição e protege dados pessoais (nomes) nas linhas de log.
+package logging
+
+import (
+	"college_api/requestctx"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Formatos de saída aceitos em LOG_FORMAT.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config define o formato e o nível mínimo das linhas de log.
+type Config struct {
+	Format string     // "text" (padrão) ou "json" (LOG_FORMAT)
+	Level  slog.Level // Nível mínimo (padrão: info; LOG_LEVEL)
+}
+
+// New cria um logger que escreve em w no formato e nível configurados, mascarando os atributos de nomes.
+func New(w io.Writer, cfg Config) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: cfg.Level, ReplaceAttr: redactAttr}
+	if cfg.Format == FormatJSON {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// Setup cria o logger da aplicação na saída de erro e o torna o padrão do slog.
+// As chamadas restantes ao pacote log passam a sair pelo mesmo handler, no nível info.
+func Setup(cfg Config) *slog.Logger {
+	logger := New(os.Stderr, cfg)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// contextKey evita colisões com chaves de contexto definidas em outros pacotes.
+type contextKey struct{}
+
+// WithLogger retorna um contexto que carrega o logger (normalmente já com o request_id).
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext devolve o logger da requisição. Fora de uma requisição (ex: collegectl),
+// devolve o logger padrão, com o request_id se o contexto tiver um.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+		if id := requestctx.RequestID(ctx); id != "" {
+			return slog.Default().With("request_id", id)
+		}
+	}
+	return slog.Default()
+}
+
+// piiKeys são as chaves de atributo cujo valor é um nome de pessoa e sai mascarado no log.
+var piiKeys = map[string]bool{
+	"name":         true,
+	"student_name": true,
+	"teacher_name": true,
+}
+
+// Name devolve o atributo "name" de uma pessoa; o valor é mascarado pelo handler.
+func Name(name string) slog.Attr {
+	return slog.String("name", name)
+}
+
+// redactAttr mascara os atributos de nomes (ver piiKeys), inclusive dentro de grupos.
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if piiKeys[a.Key] && a.Value.Kind() == slog.KindString {
+		a.Value = slog.StringValue(Redact(a.Value.String()))
+	}
+	return a
+}
+
+// Redact reduz um nome às iniciais (ex: "Maria da Silva" vira "M. d. S."), o suficiente
+// para distinguir linhas de log sem expor o nome completo.
+func Redact(name string) string {
+	var initials []string
+	for _, part := range strings.Fields(name) {
+		r, _ := utf8.DecodeRuneInString(part)
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			initials = append(initials, string(r)+".")
+		}
+	}
+	return strings.Join(initials, " ")
+}
@@ -0,0 +1,61 @@
+// api/mergepatch/mergepatch.go
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ContentType é o media type de um JSON Merge Patch (RFC 7396).
+const ContentType = "application/merge-patch+json"
+
+// Apply aplica patch ao documento original seguindo a RFC 7396:
+// membros do patch substituem os do original, null remove o membro e objetos são mesclados recursivamente.
+// Qualquer valor que não seja objeto (inclusive arrays) substitui o valor original por inteiro.
+func Apply(original, patch []byte) ([]byte, error) {
+	patchValue, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("merge patch inválido: %w", err)
+	}
+	var originalValue interface{}
+	if len(bytes.TrimSpace(original)) > 0 {
+		if originalValue, err = decode(original); err != nil {
+			return nil, fmt.Errorf("documento original inválido: %w", err)
+		}
+	}
+	return json.Marshal(merge(originalValue, patchValue))
+}
+
+func merge(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = merge(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// decode interpreta JSON preservando números como json.Number, para não perder precisão.
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("conteúdo extra após o documento JSON")
+	}
+	return value, nil
+}
@@ -0,0 +1,91 @@
+// api/metrics/metrics.go
+// Pacote metrics reúne as métricas Prometheus da aplicação: tráfego HTTP, pool de conexões,
+// duração das consultas por repositório, acertos do cache e contadores de domínio. Elas são expostas em /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace é o prefixo de todas as métricas da aplicação.
+const namespace = "college"
+
+// Registry é o registro próprio da aplicação (não o global do client_golang), com as
+// métricas de runtime do Go e do processo.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests conta as requisições por método, template da rota e status.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Requisições HTTP atendidas, por método, template da rota e status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration mede a latência das requisições por método, template da rota e status.
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latência das requisições HTTP, por método, template da rota e status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// DBQueryDuration mede a duração das operações de cada repositório (todas as consultas do método).
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duração das operações de banco, por repositório e operação.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"repository", "operation"})
+
+	// CacheRequests conta as leituras do cache de leitura dos serviços, por cache e resultado (hit ou miss).
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Leituras do cache, por cache e resultado (hit ou miss).",
+	}, []string{"cache", "result"})
+
+	// StudentsCreated conta os alunos criados (pela API, importação ou seeder).
+	StudentsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "students_created_total",
+		Help:      "Alunos criados.",
+	})
+
+	// EnrollmentRetries conta as novas tentativas de gerar a matrícula depois de uma colisão
+	// com outra criação simultânea.
+	EnrollmentRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enrollment_allocation_retries_total",
+		Help:      "Novas tentativas de alocar o número de matrícula após colisão.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		DBQueryDuration,
+		CacheRequests,
+		StudentsCreated,
+		EnrollmentRetries,
+	)
+}
+
+// RegisterDB expõe as estatísticas do pool de conexões (db.Stats()) como go_sql_*{db_name="college"}.
+// Deve ser chamado uma vez, depois de abrir a conexão.
+func RegisterDB(db *sql.DB) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// Handler devolve o handler HTTP de /metrics, no formato de exposição do Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,73 @@
+// api/middleware/apikey.go
+package middleware
+
+import (
+	"college_api/requestctx"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// APIKeyAuth retorna um middleware mux que exige uma das chaves em keys, enviada em X-API-Key ou
+// em Authorization: Bearer. O principal da requisição passa a ser a chave (identificada pelo início
+// do seu hash, nunca pela chave em si), usado na auditoria e no rate limit.
+// Sem chaves configuradas a API é aberta. Os caminhos em public (ex: /healthz) nunca exigem chave.
+func APIKeyAuth(keys []string, public ...string) mux.MiddlewareFunc {
+	publicPaths := make(map[string]bool, len(public))
+	for _, path := range public {
+		publicPaths[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(keys) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if publicPaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := r.Header.Get("X-API-Key")
+			if key == "" {
+				if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+					key = strings.TrimPrefix(auth, "Bearer ")
+				}
+			}
+			if key == "" || !validAPIKey(keys, key) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="college-api"`)
+				http.Error(w, "Chave de API ausente ou inválida", http.StatusUnauthorized)
+				return
+			}
+			sum := sha256.Sum256([]byte(key))
+			principal := requestctx.Principal{ID: hex.EncodeToString(sum[:8]), Kind: "apikey"}
+			next.ServeHTTP(w, r.WithContext(requestctx.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequireAuthenticated exige que a requisição tenha um principal autenticado (ver APIKeyAuth), mesmo
+// com a API aberta. Protege rotas que expõem dados de terceiros (ex: /audit): sem API_KEYS configurada,
+// elas sempre respondem 401.
+func RequireAuthenticated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := requestctx.PrincipalFrom(r.Context()); !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="college-api"`)
+			http.Error(w, "Esta rota exige autenticação: configure API_KEYS e envie uma chave de API", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validAPIKey compara key com todas as chaves em tempo constante.
+func validAPIKey(keys []string, key string) bool {
+	valid := 0
+	for _, k := range keys {
+		valid |= subtle.ConstantTimeCompare([]byte(k), []byte(key))
+	}
+	return valid == 1
+}
@@ -0,0 +1,81 @@
+// api/middleware/clientip.go
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies é a lista de redes cujos cabeçalhos X-Forwarded-For são confiáveis.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies converte uma lista de CIDRs (ou IPs avulsos) em TrustedProxies.
+func ParseTrustedProxies(entries []string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			// IP avulso: vira uma rede /32 (IPv4) ou /128 (IPv6)
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("proxy confiável inválido: %q", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				bits = 32
+			}
+			entry = fmt.Sprintf("%s/%d", entry, bits)
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("proxy confiável inválido: %q: %w", entry, err)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// contains verifica se o IP pertence a algum proxy confiável.
+func (t TrustedProxies) contains(ip net.IP) bool {
+	for _, network := range t {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP determina o IP real do cliente.
+// O X-Forwarded-For só é considerado quando a conexão vem de um proxy confiável; nesse caso
+// a lista é percorrida da direita para a esquerda e o primeiro endereço não confiável é o cliente.
+// Assim um cliente não consegue forjar o próprio IP enviando o cabeçalho diretamente.
+func (t TrustedProxies) ClientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	remoteIP := net.ParseIP(remote)
+	if remoteIP == nil || !t.contains(remoteIP) {
+		return remote
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		ip := net.ParseIP(hop)
+		if ip == nil {
+			// Valor malformado: não dá para confiar em nada à esquerda dele
+			break
+		}
+		if !t.contains(ip) {
+			return ip.String()
+		}
+		remote = ip.String()
+	}
+	return remote
+}
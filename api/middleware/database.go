@@ -0,0 +1,47 @@
+// api/middleware/database.go
+package middleware
+
+import (
+	"college_api/logging"
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// DatabaseGate informa se o banco está pronto, tentando concluir a inicialização se necessário
+// (implementado por config.Reconnector).
+type DatabaseGate interface {
+	Ensure(ctx context.Context) error
+	RetryAfter() time.Duration
+}
+
+// RequireDatabase retorna um middleware mux que responde 503, com Retry-After, enquanto o banco não
+// estiver pronto, em vez de deixar a requisição falhar nos repositórios. Os caminhos em skip
+// (ex: /healthz, /readyz) passam sempre: eles informam o próprio estado.
+func RequireDatabase(gate DatabaseGate, skip ...string) mux.MiddlewareFunc {
+	skipPaths := make(map[string]bool, len(skip))
+	for _, path := range skip {
+		skipPaths[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skipPaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err := gate.Ensure(r.Context()); err != nil {
+				logging.FromContext(r.Context()).Warn("RequireDatabase: banco de dados indisponível", "error", err)
+				retryAfter := int(math.Ceil(gate.RetryAfter().Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+				http.Error(w, "Banco de dados indisponível, tente novamente em instantes", http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+// api/middleware/metrics.go
+package middleware
+
+import (
+	"college_api/metrics"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Metrics é um middleware mux que conta as requisições e mede sua latência, rotuladas pelo
+// método, pelo template da rota (nunca pelo caminho real, para não explodir a cardinalidade) e pelo status.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		route := routeTemplate(r)
+		status := strconv.Itoa(rec.status)
+		metrics.HTTPRequests.WithLabelValues(r.Method, route, status).Inc()
+		metrics.HTTPDuration.WithLabelValues(r.Method, route, status).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,107 @@
+// api/middleware/ratelimit.go
+package middleware
+
+import (
+	"college_api/logging"
+	"college_api/requestctx"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// RateLimitOptions configura o middleware de limitação de requisições.
+type RateLimitOptions struct {
+	// Default é o limite aplicado a rotas sem configuração própria.
+	Default Limit
+	// Routes sobrescreve o limite por rota. A chave é "MÉTODO /template" (ex: "GET /students")
+	// ou apenas "/template" para valer em todos os métodos. O template é o registrado no mux.
+	Routes map[string]Limit
+	// Store guarda os baldes; se nil, um MemoryStore é usado.
+	Store RateLimitStore
+	// TrustedProxies define de quem o X-Forwarded-For é aceito.
+	TrustedProxies TrustedProxies
+	// ByIP identifica o cliente sempre pelo IP. Usado no limite registrado antes da autenticação, que
+	// assim também conta as requisições recusadas com 401 (tentativas de adivinhar uma chave).
+	ByIP bool
+}
+
+// RateLimit retorna um middleware mux que limita requisições por cliente usando balde de tokens.
+// O cliente é identificado pelo principal autenticado ou, sem ele, pelo IP; por isso o middleware deve
+// ser registrado depois da autenticação (ou usar ByIP). Cada rota com limite próprio tem baldes
+// separados dos do limite padrão.
+func RateLimit(opts RateLimitOptions) mux.MiddlewareFunc {
+	if opts.Store == nil {
+		opts.Store = NewMemoryStore(10 * time.Minute)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope, limit := opts.limitFor(r)
+			if limit.Rate <= 0 || limit.Burst <= 0 {
+				next.ServeHTTP(w, r) // Limite desabilitado para esta rota
+				return
+			}
+
+			key := scope + "|" + opts.clientKey(r)
+			decision, err := opts.Store.Take(r.Context(), key, limit, time.Now())
+			if err != nil {
+				// Falha no store não deve derrubar a API: deixa a requisição passar
+				logging.FromContext(r.Context()).Error("RateLimit: erro ao consultar store", "scope", scope, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.ResetAfter)))
+
+			if !decision.Allowed {
+				retryAfter := ceilSeconds(decision.RetryAfter)
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"error":       "limite de requisições excedido, tente novamente mais tarde",
+					"retry_after": retryAfter,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limitFor encontra o limite da rota atual e o escopo usado para separar os baldes.
+func (o RateLimitOptions) limitFor(r *http.Request) (string, Limit) {
+	if route := mux.CurrentRoute(r); route != nil && len(o.Routes) > 0 {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			if limit, ok := o.Routes[r.Method+" "+tpl]; ok {
+				return r.Method + " " + tpl, limit
+			}
+			if limit, ok := o.Routes[tpl]; ok {
+				return tpl, limit
+			}
+		}
+	}
+	return "*", o.Default
+}
+
+// clientKey identifica o cliente: o principal validado pela autenticação (Kind e ID; cada chave de API
+// ou, com uma autenticação de usuários, cada usuário tem o seu balde) ou o IP. Cabeçalhos não
+// validados (ex: um X-API-Key qualquer) nunca escolhem o balde, senão bastaria trocá-los a cada
+// requisição para escapar do limite.
+func (o RateLimitOptions) clientKey(r *http.Request) string {
+	if p, ok := requestctx.PrincipalFrom(r.Context()); ok && !o.ByIP {
+		return p.Kind + ":" + p.ID
+	}
+	return "ip:" + o.TrustedProxies.ClientIP(r)
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,104 @@
+// api/middleware/ratelimit_store.go
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit descreve um balde de tokens: até Burst requisições seguidas,
+// reabastecido a Rate tokens por segundo.
+type Limit struct {
+	Rate  float64 // Tokens adicionados por segundo
+	Burst int     // Capacidade máxima do balde
+}
+
+// Decision é o resultado de uma tentativa de consumir um token.
+type Decision struct {
+	Allowed    bool
+	Remaining  int           // Tokens inteiros restantes após a requisição
+	ResetAfter time.Duration // Tempo até o balde ficar cheio novamente
+	RetryAfter time.Duration // Tempo até haver um token disponível (apenas quando negado)
+}
+
+// RateLimitStore guarda o estado dos baldes de cada cliente.
+// MemoryStore atende uma única instância; para várias instâncias basta uma
+// implementação compartilhada (ex: Redis) que respeite a mesma interface.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Decision, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryStore é um RateLimitStore em memória do processo.
+type MemoryStore struct {
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	idleTTL    time.Duration
+	lastSweep  time.Time
+	sweepEvery time.Duration
+}
+
+// NewMemoryStore cria um MemoryStore que descarta baldes sem uso há mais de idleTTL.
+func NewMemoryStore(idleTTL time.Duration) *MemoryStore {
+	if idleTTL <= 0 {
+		idleTTL = 10 * time.Minute
+	}
+	return &MemoryStore{
+		buckets:    make(map[string]*bucket),
+		idleTTL:    idleTTL,
+		sweepEvery: idleTTL / 2,
+	}
+}
+
+// Take consome um token do balde identificado por key.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	capacity := float64(limit.Burst)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		s.buckets[key] = b
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*limit.Rate)
+		b.last = now
+	}
+
+	decision := Decision{}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	decision.Remaining = int(math.Floor(b.tokens))
+	decision.ResetAfter = secondsToDuration((capacity - b.tokens) / limit.Rate)
+	return decision, nil
+}
+
+// sweep remove baldes ociosos para que a memória não cresça indefinidamente.
+// Deve ser chamado com s.mu travado.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.sweepEvery {
+		return
+	}
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > s.idleTTL {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,38 @@
+// api/middleware/requestid.go
+package middleware
+
+import (
+	"college_api/requestctx"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader é o cabeçalho usado para propagar o ID da requisição.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID reaproveita o X-Request-ID recebido (quando válido) ou gera um novo,
+// devolve-o na resposta e o coloca no contexto para auditoria e logs.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(requestctx.WithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID aceita apenas IDs curtos de caracteres ASCII visíveis, para não poluir logs e auditoria.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,21 @@
+// models/audit.go
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry representa um registro imutável da trilha de auditoria.
+type AuditEntry struct {
+	ID         int64           `json:"id"`                   // Sequencial gerado pelo banco
+	OccurredAt time.Time       `json:"occurred_at"`          // Momento da alteração
+	Actor      string          `json:"actor"`                // Quem fez a alteração (ex: "user:123" ou "anonymous")
+	Action     string          `json:"action"`               // "create", "update", "delete", "restore", "purge", "associate" ou "dissociate"
+	Entity     string          `json:"entity"`               // Tabela afetada (ex: "students")
+	EntityID   string          `json:"entity_id"`            // ID do registro afetado
+	Before     json.RawMessage `json:"before,omitempty"`     // Valores anteriores (nulo em criações)
+	After      json.RawMessage `json:"after,omitempty"`      // Valores novos (nulo em exclusões)
+	Diff       json.RawMessage `json:"diff,omitempty"`       // Campos alterados: {"campo": {"from": ..., "to": ...}}
+	RequestID  string          `json:"request_id,omitempty"` // X-Request-ID da requisição de origem
+}
@@ -0,0 +1,38 @@
+// models/backup.go
+package models
+
+import "time"
+
+// BackupFormat identifica os arquivos de backup da universidade.
+const BackupFormat = "college-backup"
+
+// BackupHeader é a primeira linha do arquivo de backup (JSON lines).
+type BackupHeader struct {
+	Format    string             `json:"format"`     // Sempre BackupFormat
+	Version   int                `json:"version"`    // Versão do formato do arquivo
+	CreatedAt time.Time          `json:"created_at"` // Momento do backup
+	Tables    []BackupTableEntry `json:"tables"`     // Tabelas e colunas presentes, na ordem do arquivo
+}
+
+// BackupTableEntry descreve as colunas de uma tabela no arquivo.
+type BackupTableEntry struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+}
+
+// BackupRecord é uma linha de dados: a tabela e os valores por coluna.
+type BackupRecord struct {
+	Table string                 `json:"table"`
+	Row   map[string]interface{} `json:"row"`
+}
+
+// BackupTrailer é a última linha do arquivo; sua ausência indica um arquivo truncado.
+type BackupTrailer struct {
+	Counts map[string]int `json:"counts"` // Linhas gravadas por tabela
+}
+
+// BackupReport resume um backup ou uma restauração.
+type BackupReport struct {
+	Version int            `json:"version"` // Versão do formato do arquivo
+	Counts  map[string]int `json:"counts"`  // Linhas por tabela
+}
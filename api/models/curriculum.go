@@ -0,0 +1,49 @@
+// models/curriculum.go
+package models
+
+// Curriculum é o arquivo de grade curricular (YAML ou JSON) usado para sincronizar o catálogo de matérias.
+type Curriculum struct {
+	Subjects []CurriculumSubject `json:"subjects" yaml:"subjects"`
+}
+
+// CurriculumSubject é uma matéria da grade curricular, identificada pelo ID (ex: "BSI101").
+type CurriculumSubject struct {
+	ID      string `json:"id" yaml:"id"`
+	Name    string `json:"name" yaml:"name"`
+	Year    int    `json:"year" yaml:"year"`
+	Credits int    `json:"credits" yaml:"credits"`
+}
+
+// FieldChange é a alteração de um campo (valor anterior e novo).
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// SubjectChange descreve as alterações de uma matéria existente.
+type SubjectChange struct {
+	ID      string                 `json:"id"`
+	Changes map[string]FieldChange `json:"changes"` // Campo -> alteração (name, year, credits)
+}
+
+// SubjectReference é uma matéria que não pode ser removida porque ainda tem alunos associados ou
+// faz parte da grade de algum curso.
+type SubjectReference struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Students int      `json:"students"`           // Quantidade de alunos associados
+	Programs []string `json:"programs,omitempty"` // Cursos cuja grade inclui a matéria
+}
+
+// CurriculumSyncReport é o diff entre a grade curricular e o catálogo de matérias.
+type CurriculumSyncReport struct {
+	DryRun    bool               `json:"dry_run"`           // true se apenas o diff foi calculado
+	Prune     bool               `json:"prune"`             // true se matérias ausentes da grade devem ser excluídas
+	Applied   bool               `json:"applied"`           // true se as alterações foram gravadas
+	Added     []Subject          `json:"added"`             // Matérias novas
+	Restored  []Subject          `json:"restored"`          // Matérias excluídas logicamente que voltam ao catálogo
+	Changed   []SubjectChange    `json:"changed"`           // Matérias existentes com campos alterados
+	Removed   []Subject          `json:"removed"`           // Matérias fora da grade (excluídas apenas com Prune)
+	Blocked   []SubjectReference `json:"blocked,omitempty"` // Remoções recusadas por ainda haver alunos ou cursos associados
+	Unchanged int                `json:"unchanged"`         // Matérias da grade já idênticas no catálogo
+}
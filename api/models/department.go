@@ -0,0 +1,11 @@
+// models/department.go
+package models
+
+// Department representa um departamento da universidade.
+type Department struct {
+	ID            string `json:"id"`              // ID único do departamento (gerado, ex: UUID)
+	Code          string `json:"code"`            // Código estável, prefixo dos registros dos professores (ex: "COMP")
+	Name          string `json:"name"`            // Nome do departamento (ex: "Ciência da Computação")
+	HeadTeacherID string `json:"head_teacher_id"` // Professor chefe do departamento (vazio se nenhum)
+	Version       int    `json:"version"`         // Versão do registro, incrementada a cada alteração (exposta como ETag)
+}
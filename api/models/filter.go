@@ -0,0 +1,24 @@
+// models/filter.go
+package models
+
+// StudentFilter restringe listagens e exportações de alunos. Campos vazios (ou zero) não filtram.
+type StudentFilter struct {
+	Name        string // Trecho do nome (sem diferenciar maiúsculas)
+	Shift       string // Turno: "M", "T" ou "N"
+	CurrentYear int    // Ano atual do aluno
+	SubjectID   string // Apenas alunos associados a esta matéria
+	Status      string // Situação do aluno; nos serviços, vazio significa "active" e "all" dispensa o filtro
+}
+
+// SubjectFilter restringe listagens e exportações de matérias.
+type SubjectFilter struct {
+	Name string // Trecho do nome (sem diferenciar maiúsculas)
+	Year int    // Ano em que a matéria é oferecida
+}
+
+// TeacherFilter restringe listagens e exportações de professores.
+type TeacherFilter struct {
+	Name         string // Trecho do nome (sem diferenciar maiúsculas)
+	Department   string // Código ou nome do departamento (comparação exata, sem diferenciar maiúsculas)
+	DepartmentID string // ID do departamento
+}
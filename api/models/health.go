@@ -0,0 +1,22 @@
+// models/health.go
+package models
+
+// Estados de uma verificação de prontidão.
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+)
+
+// Readiness é o resultado de /readyz: o estado geral e o de cada verificação.
+type Readiness struct {
+	Status string            `json:"status"` // "ok" se todas as verificações passaram, senão "unavailable"
+	Checks map[string]string `json:"checks"` // Verificação -> "ok" ou a descrição da falha
+}
+
+// VersionInfo é a resposta de /version.
+type VersionInfo struct {
+	Commit        string `json:"commit"`         // Commit do código em execução
+	BuildTime     string `json:"build_time"`     // Momento da compilação (RFC 3339)
+	GoVersion     string `json:"go_version"`     // Versão do Go usada na compilação
+	SchemaVersion int    `json:"schema_version"` // Versão do esquema esperada pelo código
+}
@@ -0,0 +1,71 @@
+// models/program.go
+package models
+
+// Tipos de matéria em um curso.
+const (
+	ProgramSubjectRequired = "required" // Obrigatória
+	ProgramSubjectElective = "elective" // Optativa
+)
+
+// Program representa um curso (ex: Bacharelado em Sistemas de Informação) e sua grade.
+type Program struct {
+	ID               string                   `json:"id"`                // Código do curso (ex: "BSI")
+	Name             string                   `json:"name"`              // Nome do curso
+	Subjects         []ProgramSubject         `json:"subjects"`          // Matérias obrigatórias e optativas
+	YearRequirements []ProgramYearRequirement `json:"year_requirements"` // Créditos mínimos por ano
+	Version          int                      `json:"version"`           // Versão do registro, incrementada a cada alteração (exposta como ETag)
+}
+
+// ProgramSubject é uma matéria da grade de um curso. Nome, ano e créditos vêm do catálogo
+// de matérias e são ignorados na entrada.
+type ProgramSubject struct {
+	SubjectID string `json:"subject_id"`
+	Kind      string `json:"kind"` // required ou elective
+	Name      string `json:"name,omitempty"`
+	Year      int    `json:"year,omitempty"`
+	Credits   int    `json:"credits,omitempty"`
+}
+
+// ProgramYearRequirement é o mínimo de créditos que o aluno precisa cursar em matérias do curso de um ano.
+type ProgramYearRequirement struct {
+	Year       int `json:"year"`
+	MinCredits int `json:"min_credits"`
+}
+
+// StudentProgramAssignment é o corpo da vinculação de um aluno a um curso (vazio desvincula).
+type StudentProgramAssignment struct {
+	ProgramID string `json:"program_id"`
+}
+
+// DegreeAudit compara as matérias associadas ao aluno com a grade do seu curso.
+type DegreeAudit struct {
+	StudentID          string               `json:"student_id"`
+	Enrollment         string               `json:"enrollment"`
+	StudentName        string               `json:"student_name"`
+	ProgramID          string               `json:"program_id"`
+	ProgramName        string               `json:"program_name"`
+	Complete           bool                 `json:"complete"`       // Todas as obrigatórias e todos os mínimos por ano cumpridos
+	EarnedCredits      int                  `json:"earned_credits"` // Créditos das matérias do curso associadas ao aluno
+	RequiredCompleted  []DegreeAuditSubject `json:"required_completed"`
+	RequiredRemaining  []DegreeAuditSubject `json:"required_remaining"`
+	ElectivesCompleted []DegreeAuditSubject `json:"electives_completed"`
+	ElectivesAvailable []DegreeAuditSubject `json:"electives_available"` // Optativas ainda não cursadas
+	Years              []DegreeAuditYear    `json:"years"`
+	OutsideProgram     []DegreeAuditSubject `json:"outside_program"` // Matérias associadas que não são do curso (não contam créditos)
+}
+
+// DegreeAuditSubject é uma matéria listada na auditoria de formatura.
+type DegreeAuditSubject struct {
+	SubjectID string `json:"subject_id"`
+	Name      string `json:"name"`
+	Year      int    `json:"year"`
+	Credits   int    `json:"credits"`
+}
+
+// DegreeAuditYear compara os créditos cursados em um ano com o mínimo exigido pelo curso.
+type DegreeAuditYear struct {
+	Year             int `json:"year"`
+	MinCredits       int `json:"min_credits"`
+	EarnedCredits    int `json:"earned_credits"`
+	RemainingCredits int `json:"remaining_credits"`
+}
@@ -0,0 +1,13 @@
+// models/purge.go
+package models
+
+import "time"
+
+// PurgeReport resume uma execução do expurgo de registros excluídos logicamente.
+type PurgeReport struct {
+	Cutoff          time.Time `json:"cutoff"`           // Registros excluídos antes deste instante foram removidos
+	Students        []string  `json:"students"`         // IDs dos alunos removidos definitivamente
+	Subjects        []string  `json:"subjects"`         // IDs das matérias removidas definitivamente
+	Teachers        []string  `json:"teachers"`         // IDs dos professores removidos definitivamente
+	SkippedSubjects []string  `json:"skipped_subjects"` // Matérias mantidas por ainda terem alunos associados ou estarem na grade de um curso
+}
@@ -0,0 +1,80 @@
+// models/rollover.go
+package models
+
+import "time"
+
+// Resultados possíveis de um aluno na virada de ano letivo.
+const (
+	RolloverPromoted   = "promoted"   // Avança para o próximo ano
+	RolloverGraduating = "graduating" // Concluiu o último ano: marcado como formando
+	RolloverRetained   = "retained"   // Não atingiu o critério: permanece no ano
+	RolloverSkipped    = "skipped"    // Já marcado como formando: não é avaliado
+)
+
+// RolloverCriteria define quem é promovido na virada de ano letivo.
+type RolloverCriteria struct {
+	MinCreditShare float64 `json:"min_credit_share"` // Fração mínima dos créditos do ano atual associados ao aluno (0 a 1)
+	FinalYear      int     `json:"final_year"`       // Último ano do curso; quem o conclui vira formando
+}
+
+// RolloverCandidate é um aluno avaliado na virada, com os créditos do seu ano atual.
+type RolloverCandidate struct {
+	StudentID     string
+	Enrollment    string
+	Name          string
+	CurrentYear   int
+	Graduating    bool
+	Version       int
+	EarnedCredits int // Créditos das matérias do ano atual associadas ao aluno
+	TotalCredits  int // Créditos de todas as matérias do ano atual no catálogo
+}
+
+// RolloverStudent é o resultado de um aluno na virada.
+type RolloverStudent struct {
+	StudentID     string  `json:"student_id"`
+	Enrollment    string  `json:"enrollment"`
+	Name          string  `json:"name"`
+	FromYear      int     `json:"from_year"`
+	ToYear        int     `json:"to_year"`
+	EarnedCredits int     `json:"earned_credits"`
+	TotalCredits  int     `json:"total_credits"`
+	CreditShare   float64 `json:"credit_share"`
+	Outcome       string  `json:"outcome"` // promoted, graduating, retained ou skipped
+	Reason        string  `json:"reason,omitempty"`
+}
+
+// RolloverReport é a prévia ou o resultado de uma virada de ano letivo.
+type RolloverReport struct {
+	ID           int64             `json:"id,omitempty"` // Identificador da execução (ausente na prévia)
+	DryRun       bool              `json:"dry_run"`
+	Criteria     RolloverCriteria  `json:"criteria"`
+	ExecutedAt   *time.Time        `json:"executed_at,omitempty"`
+	UndoDeadline *time.Time        `json:"undo_deadline,omitempty"` // Até quando a virada pode ser desfeita
+	Promoted     int               `json:"promoted"`
+	Graduating   int               `json:"graduating"`
+	Retained     int               `json:"retained"`
+	Skipped      int               `json:"skipped"`
+	Students     []RolloverStudent `json:"students"`
+}
+
+// RolloverRun é uma virada de ano letivo já executada.
+type RolloverRun struct {
+	ID           int64            `json:"id"`
+	ExecutedAt   time.Time        `json:"executed_at"`
+	Actor        string           `json:"actor"`
+	Criteria     RolloverCriteria `json:"criteria"`
+	UndoDeadline time.Time        `json:"undo_deadline"`
+	UndoneAt     *time.Time       `json:"undone_at,omitempty"`
+	UndoneBy     string           `json:"undone_by,omitempty"`
+	Changes      int              `json:"changes"` // Alunos alterados
+}
+
+// RolloverChange é o estado de um aluno antes e depois de uma virada, usado para desfazê-la.
+type RolloverChange struct {
+	StudentID      string
+	FromYear       int
+	ToYear         int
+	FromGraduating bool
+	ToGraduating   bool
+	VersionAfter   int
+}
@@ -9,4 +9,8 @@ type Student struct {
 	CurrentYear int       `json:"current_year"` // Ano atual do aluno na universidade (ex: 1, 2, 3, 4)
 	Shift       string    `json:"shift"`        // Turno do aluno (ex: "M" - Manhã, "T" - Tarde, "N" - Noite)
 	Subjects    []Subject `json:"subjects"`     // Matérias que o aluno está cursando/cursou
+	Graduating  bool      `json:"graduating"`   // Formando: concluiu o último ano na virada de ano letivo
+	Status      string    `json:"status"`       // Situação do aluno: active, suspended, locked, graduated, withdrawn ou transferred
+	ProgramID   string    `json:"program_id"`   // Curso ao qual o aluno está vinculado (vazio se nenhum)
+	Version     int       `json:"version"`      // Versão do registro, incrementada a cada alteração (exposta como ETag)
 }
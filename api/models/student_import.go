@@ -0,0 +1,29 @@
+// models/student_import.go
+package models
+
+// Situações de uma linha na importação de alunos.
+const (
+	ImportRowCreated = "created" // Aluno gravado
+	ImportRowValid   = "valid"   // Linha válida, mas nada foi gravado (dry-run ou outra linha com erro)
+	ImportRowError   = "error"   // Linha rejeitada; ver Errors
+)
+
+// StudentImportRow é o resultado da importação de uma linha do arquivo.
+type StudentImportRow struct {
+	Line       int      `json:"line"`                 // Número da linha no arquivo (o cabeçalho é a linha 1)
+	Status     string   `json:"status"`               // "created", "valid" ou "error"
+	Name       string   `json:"name"`                 // Nome lido da linha
+	StudentID  string   `json:"student_id,omitempty"` // ID gerado (apenas quando gravado)
+	Enrollment string   `json:"enrollment,omitempty"` // Matrícula gerada (ou que seria gerada, no dry-run)
+	Errors     []string `json:"errors,omitempty"`     // Problemas encontrados na linha
+}
+
+// StudentImportReport resume uma importação de alunos em lote.
+type StudentImportReport struct {
+	DryRun    bool               `json:"dry_run"`    // true se nada deveria ser gravado
+	Committed bool               `json:"committed"`  // true se os alunos foram gravados
+	TotalRows int                `json:"total_rows"` // Linhas de dados lidas (sem o cabeçalho)
+	Valid     int                `json:"valid_rows"` // Linhas sem erros
+	Invalid   int                `json:"error_rows"` // Linhas com erros
+	Rows      []StudentImportRow `json:"rows"`       // Resultado linha a linha
+}
@@ -0,0 +1,34 @@
+// models/student_status.go
+package models
+
+import "time"
+
+// Situações possíveis de um aluno. Só alunos ativos aparecem nas listagens por padrão
+// e podem ser associados a matérias.
+const (
+	StudentActive      = "active"      // Cursando normalmente
+	StudentSuspended   = "suspended"   // Suspenso por medida disciplinar ou administrativa
+	StudentLocked      = "locked"      // Matrícula trancada a pedido do aluno
+	StudentGraduated   = "graduated"   // Concluiu o curso
+	StudentWithdrawn   = "withdrawn"   // Desistente ou desligado
+	StudentTransferred = "transferred" // Transferido para outra instituição
+)
+
+// StudentStatusChange é o pedido de mudança de situação de um aluno.
+type StudentStatusChange struct {
+	Status        string `json:"status"`                   // Nova situação
+	Reason        string `json:"reason"`                   // Motivo (obrigatório)
+	EffectiveDate string `json:"effective_date,omitempty"` // Data em que a mudança vale (AAAA-MM-DD); padrão: hoje
+}
+
+// StudentStatusTransition é um registro do histórico de situações de um aluno.
+type StudentStatusTransition struct {
+	ID            string    `json:"id"`
+	StudentID     string    `json:"student_id"`
+	FromStatus    string    `json:"from_status"`
+	ToStatus      string    `json:"to_status"`
+	Reason        string    `json:"reason"`
+	EffectiveDate string    `json:"effective_date"` // AAAA-MM-DD
+	Actor         string    `json:"actor"`
+	RecordedAt    time.Time `json:"recorded_at"`
+}
@@ -7,4 +7,5 @@ type Subject struct {
     Name    string `json:"name"`     // Nome da matéria (ex: "Programação Orientada a Objetos")
     Year    int    `json:"year"`     // Ano em que a matéria é oferecida (ex: 1, 2, 3, 4)
     Credits int    `json:"credits"`  // Créditos da matéria (ex: 4)
+    Version int    `json:"version"`  // Versão do registro, incrementada a cada alteração (exposta como ETag)
 }
\ No newline at end of file
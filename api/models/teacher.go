@@ -3,8 +3,10 @@ package models
 
 // Teacher representa um professor na universidade.
 type Teacher struct {
-	ID         string `json:"id"`         // ID único do professor (gerado, ex: UUID)
-	Registry   string `json:"registry"`   // Registro único do professor (ex: "PROF001")
-	Name       string `json:"name"`       // Nome completo do professor
-	Department string `json:"department"` // Departamento do professor (ex: "Ciência da Computação")
+	ID           string `json:"id"`            // ID único do professor (gerado, ex: UUID)
+	Registry     string `json:"registry"`      // Registro único do professor (ex: "PROF001")
+	Name         string `json:"name"`          // Nome completo do professor
+	DepartmentID string `json:"department_id"` // Departamento do professor
+	Department   string `json:"department"`    // Nome do departamento (na entrada, aceito como alternativa a department_id: código ou nome)
+	Version      int    `json:"version"`       // Versão do registro, incrementada a cada alteração (exposta como ETag)
 }
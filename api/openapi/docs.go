@@ -0,0 +1,11 @@
+// api/openapi/docs.go
+package openapi
+
+import _ "embed"
+
+// DocsPage é a documentação interativa servida em /docs: uma página HTML autocontida (sem
+// dependências externas) que lê openapi.json do mesmo endereço, lista as operações com seus
+// parâmetros e schemas e permite enviá-las, com a chave de API informada na própria página.
+//
+//go:embed docs.html
+var DocsPage []byte
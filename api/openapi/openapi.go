@@ -0,0 +1,299 @@
+// api/openapi/openapi.go
+// Pacote openapi descreve a API em OpenAPI 3.1: as operações ficam na tabela de routes.go e os
+// schemas são gerados dos tipos do pacote models. O documento é servido em /openapi.json e
+// CheckRoutes, chamada nos testes com o roteador real, garante que toda rota registrada esteja descrita.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// Version é a versão do documento (info.version), incrementada quando o contrato da API muda.
+const Version = "1.0.0"
+
+// operation descreve uma rota. Os parâmetros de caminho são deduzidos do template ({id}).
+type operation struct {
+	method      string
+	path        string
+	tag         string
+	summary     string
+	description string
+	public      bool // Sem chave de API, rate limit nem verificação do banco (sondas e documentação)
+	params      []param
+	body        *body
+	responses   []response
+}
+
+type param struct {
+	in          string // query ou header
+	name        string
+	description string
+	schema      map[string]any
+	required    bool
+}
+
+type body struct {
+	description string
+	content     map[string]reflect.Type // Media type -> tipo do corpo (nil: texto ou binário)
+}
+
+type response struct {
+	status      int
+	description string
+	contentType string       // Vazio: sem corpo
+	schema      reflect.Type // nil com contentType: texto ou binário
+	headers     []string     // Cabeçalhos documentados em components/headers (ex: ETag)
+}
+
+// Parâmetros e respostas reaproveitados pela tabela de operações.
+
+func query(name, description string, schema map[string]any) param {
+	return param{in: "query", name: name, description: description, schema: schema}
+}
+
+var (
+	stringSchema  = map[string]any{"type": "string"}
+	integerSchema = map[string]any{"type": "integer"}
+	booleanSchema = map[string]any{"type": "boolean"}
+
+	ifMatch = param{in: "header", name: "If-Match", required: true, schema: stringSchema,
+		description: "ETag obtida no GET (controle de concorrência otimista). Ausente: 428; desatualizada: 412."}
+	ifNoneMatch = param{in: "header", name: "If-None-Match", schema: stringSchema,
+		description: "ETag já conhecida pelo cliente; se ainda for a atual, a resposta é 304 sem corpo."}
+	dryRun = query("dry_run", "Apenas simula, sem gravar nada.", booleanSchema)
+)
+
+func jsonBody[T any](description string) *body {
+	return &body{description: description, content: map[string]reflect.Type{"application/json": reflect.TypeFor[T]()}}
+}
+
+func jsonResponse[T any](status int, description string, headers ...string) response {
+	return response{status: status, description: description, contentType: "application/json", schema: reflect.TypeFor[T](), headers: headers}
+}
+
+func noContent(description string) response {
+	return response{status: http.StatusNoContent, description: description}
+}
+
+// errorResponse documenta um erro: o corpo é a mensagem em texto simples (http.Error).
+func errorResponse(status int, description string) response {
+	return response{status: status, description: description, contentType: "text/plain"}
+}
+
+func notModified() response {
+	return response{status: http.StatusNotModified, description: "A ETag de If-None-Match ainda é a atual."}
+}
+
+var (
+	errBadRequest = errorResponse(http.StatusBadRequest, "Requisição ou dados inválidos.")
+	errNotFound   = errorResponse(http.StatusNotFound, "Registro não encontrado.")
+	errConflict   = errorResponse(http.StatusConflict, "A operação conflita com o estado atual dos dados.")
+)
+
+var (
+	documentOnce sync.Once
+	document     []byte
+)
+
+// Document devolve a especificação em JSON. Ela é gerada uma vez, na primeira chamada.
+func Document() []byte {
+	documentOnce.Do(func() {
+		var err error
+		if document, err = json.MarshalIndent(build(), "", "  "); err != nil {
+			panic("openapi: especificação não serializável: " + err.Error()) // Erro de programação
+		}
+	})
+	return document
+}
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// build monta o documento a partir de routes.
+func build() map[string]any {
+	s := &schemas{components: map[string]any{}}
+	paths := map[string]any{}
+	for _, op := range routes {
+		item, _ := paths[op.path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[op.path] = item
+		}
+		item[strings.ToLower(op.method)] = op.build(s)
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   "College API",
+			"version": Version,
+			"description": "API de gestão acadêmica: alunos, matérias, professores, departamentos, cursos, " +
+				"auditoria e rotinas administrativas. Erros são devolvidos como texto simples. " +
+				"Com API_KEYS configurada, toda operação não pública exige uma chave em X-API-Key ou Authorization: Bearer.",
+		},
+		"servers": []any{map[string]any{"url": ".", "description": "O mesmo endereço que serve esta especificação"}},
+		"tags": []any{
+			map[string]any{"name": "Matérias"},
+			map[string]any{"name": "Alunos"},
+			map[string]any{"name": "Professores"},
+			map[string]any{"name": "Departamentos"},
+			map[string]any{"name": "Cursos"},
+			map[string]any{"name": "Auditoria"},
+			map[string]any{"name": "Administração", "description": "Registradas apenas com ENABLE_ADMIN_ROUTES=true."},
+			map[string]any{"name": "Operação", "description": "Saúde, versão, métricas e documentação."},
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": s.components,
+			"securitySchemes": map[string]any{
+				"apiKey": map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+			"headers": map[string]any{
+				"ETag":        map[string]any{"description": "Versão do registro; envie-a em If-Match para alterá-lo.", "schema": stringSchema},
+				"Retry-After": map[string]any{"description": "Segundos até uma nova tentativa.", "schema": integerSchema},
+			},
+			"responses": map[string]any{
+				"Unauthorized":       map[string]any{"description": "Chave de API ausente ou inválida (com API_KEYS configurada).", "content": textContent()},
+				"TooManyRequests":    map[string]any{"description": "Limite de requisições do cliente atingido.", "headers": headerRefs("Retry-After"), "content": textContent()},
+				"ServiceUnavailable": map[string]any{"description": "Banco de dados indisponível.", "headers": headerRefs("Retry-After"), "content": textContent()},
+				"InternalError":      map[string]any{"description": "Erro interno.", "content": textContent()},
+			},
+		},
+		"security": []any{map[string]any{"apiKey": []any{}}, map[string]any{"bearer": []any{}}},
+	}
+}
+
+// build gera o Operation Object, acrescentando as respostas comuns a todas as operações não públicas.
+func (op operation) build(s *schemas) map[string]any {
+	out := map[string]any{
+		"tags":        []any{op.tag},
+		"summary":     op.summary,
+		"operationId": operationID(op),
+	}
+	if op.description != "" {
+		out["description"] = op.description
+	}
+
+	var params []any
+	for _, match := range pathParam.FindAllStringSubmatch(op.path, -1) {
+		params = append(params, map[string]any{"name": match[1], "in": "path", "required": true, "schema": stringSchema})
+	}
+	for _, p := range op.params {
+		param := map[string]any{"name": p.name, "in": p.in, "schema": p.schema}
+		if p.description != "" {
+			param["description"] = p.description
+		}
+		if p.required {
+			param["required"] = true
+		}
+		params = append(params, param)
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	if op.body != nil {
+		content := map[string]any{}
+		for mediaType, t := range op.body.content {
+			if t == nil {
+				content[mediaType] = map[string]any{"schema": stringSchema}
+				continue
+			}
+			content[mediaType] = map[string]any{"schema": s.of(t)}
+		}
+		out["requestBody"] = map[string]any{"description": op.body.description, "required": true, "content": content}
+	}
+
+	responses := map[string]any{}
+	for _, r := range op.responses {
+		resp := map[string]any{"description": r.description}
+		if r.contentType != "" {
+			schema := stringSchema
+			if r.schema != nil {
+				schema = s.of(r.schema)
+			} else if !strings.HasPrefix(r.contentType, "text/") {
+				schema = map[string]any{"type": "string", "contentMediaType": r.contentType}
+			}
+			resp["content"] = map[string]any{r.contentType: map[string]any{"schema": schema}}
+		}
+		if len(r.headers) > 0 {
+			resp["headers"] = headerRefs(r.headers...)
+		}
+		responses[strconv.Itoa(r.status)] = resp
+	}
+	responses["500"] = map[string]any{"$ref": "#/components/responses/InternalError"}
+	if op.public {
+		out["security"] = []any{}
+	} else {
+		responses["401"] = map[string]any{"$ref": "#/components/responses/Unauthorized"}
+		responses["429"] = map[string]any{"$ref": "#/components/responses/TooManyRequests"}
+		responses["503"] = map[string]any{"$ref": "#/components/responses/ServiceUnavailable"}
+	}
+	out["responses"] = responses
+	return out
+}
+
+// operationID deriva um identificador estável do método e do caminho (ex: GET /students/{id} vira get_students_id).
+func operationID(op operation) string {
+	id := strings.ToLower(op.method) + strings.NewReplacer("/", "_", "{", "", "}", "", ":", "_", "-", "_", ".", "_").Replace(op.path)
+	return strings.TrimSuffix(id, "_")
+}
+
+func textContent() map[string]any {
+	return map[string]any{"text/plain": map[string]any{"schema": stringSchema}}
+}
+
+func headerRefs(names ...string) map[string]any {
+	headers := map[string]any{}
+	for _, name := range names {
+		headers[name] = map[string]any{"$ref": "#/components/headers/" + name}
+	}
+	return headers
+}
+
+// CheckRoutes confere se toda rota registrada em router (método e template do caminho) está descrita
+// na especificação, e lista as que faltarem. Rotas descritas mas não registradas (ex: administrativas
+// com ENABLE_ADMIN_ROUTES=false) não são erro.
+func CheckRoutes(router *mux.Router) error {
+	documented := make(map[string]bool, len(routes))
+	for _, op := range routes {
+		documented[op.method+" "+op.path] = true
+	}
+
+	var missing []string
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil // Rota sem caminho (ex: apenas um matcher de host)
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			missing = append(missing, "* "+path+" (sem métodos declarados)")
+			return nil
+		}
+		for _, method := range methods {
+			if !documented[method+" "+path] {
+				missing = append(missing, method+" "+path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("rotas ausentes da especificação OpenAPI (openapi/routes.go): %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
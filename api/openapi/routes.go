@@ -0,0 +1,292 @@
+// api/openapi/routes.go
+package openapi
+
+import (
+	"college_api/models"
+	"net/http"
+	"reflect"
+)
+
+// Filtros compartilhados pelas listagens e exportações (ver handlers/filters.go).
+var (
+	studentFilters = []param{
+		query("name", "Trecho do nome, sem diferenciar maiúsculas.", stringSchema),
+		query("shift", "Turno: M, T ou N.", stringSchema),
+		query("current_year", "Ano atual do aluno.", integerSchema),
+		query("subject_id", "Apenas alunos associados a esta matéria.", stringSchema),
+		query("status", "Situação do aluno (padrão: active; all inclui todas).", stringSchema),
+	}
+	subjectFilters = []param{
+		query("name", "Trecho do nome, sem diferenciar maiúsculas.", stringSchema),
+		query("year", "Ano em que a matéria é oferecida.", integerSchema),
+	}
+	teacherFilters = []param{
+		query("name", "Trecho do nome, sem diferenciar maiúsculas.", stringSchema),
+		query("department", "Código ou nome do departamento.", stringSchema),
+		query("department_id", "ID do departamento.", stringSchema),
+	}
+	exportFormat = query("format", "csv (padrão) ou xlsx.", map[string]any{"type": "string", "enum": []any{"csv", "xlsx"}})
+)
+
+// exportResponses descrevem a planilha devolvida pelas exportações, em CSV ou XLSX conforme ?format=.
+var exportResponses = []response{
+	{status: http.StatusOK, description: "Arquivo CSV (ou XLSX, com format=xlsx) como anexo.", contentType: "text/csv"},
+	errBadRequest,
+}
+
+// mergePatchBody é o corpo das operações PATCH: um JSON Merge Patch (RFC 7396) sobre o registro.
+func mergePatchBody[T any]() *body {
+	return &body{
+		description: "JSON Merge Patch (RFC 7396): só os campos enviados mudam; null remove o valor.",
+		content:     map[string]reflect.Type{"application/merge-patch+json": reflect.TypeFor[T]()},
+	}
+}
+
+// routes descreve todas as rotas registradas em registerRoutes (index.go). Ao criar uma rota, descreva-a
+// aqui: TestRoutesDocumented falha se alguma rota registrada estiver ausente (ver CheckRoutes).
+var routes = []operation{
+	// --- Matérias ---
+	{method: "POST", path: "/subjects", tag: "Matérias", summary: "Cria uma matéria",
+		description: "ID, nome, ano e créditos (maiores que zero) são obrigatórios.",
+		body:        jsonBody[models.Subject]("Matéria a criar."),
+		responses:   []response{jsonResponse[models.Subject](http.StatusCreated, "Matéria criada."), errBadRequest}},
+	{method: "GET", path: "/subjects", tag: "Matérias", summary: "Lista as matérias",
+		params:    append(subjectFilters, ifNoneMatch),
+		responses: []response{jsonResponse[[]models.Subject](http.StatusOK, "Matérias que atendem ao filtro.", "ETag"), notModified(), errBadRequest}},
+	{method: "POST", path: "/subjects/sync", tag: "Matérias", summary: "Sincroniza o catálogo com a grade curricular",
+		description: "Compara a grade (YAML ou JSON) com o catálogo e aplica as diferenças. Com prune, remove as matérias ausentes da grade; " +
+			"remoções de matérias com alunos associados ou na grade de algum curso são recusadas (409, com o relatório).",
+		params: []param{dryRun, query("prune", "Exclui as matérias que não estão na grade.", booleanSchema)},
+		body: &body{description: "Grade curricular.", content: map[string]reflect.Type{
+			"application/yaml": reflect.TypeFor[models.Curriculum](),
+			"application/json": reflect.TypeFor[models.Curriculum](),
+		}},
+		responses: []response{
+			jsonResponse[models.CurriculumSyncReport](http.StatusOK, "Diferenças calculadas (e aplicadas, sem dry_run)."),
+			jsonResponse[models.CurriculumSyncReport](http.StatusConflict, "Alguma remoção foi recusada por ainda haver alunos ou cursos associados."),
+			errBadRequest,
+			errorResponse(http.StatusRequestEntityTooLarge, "Grade maior que o limite."),
+			errorResponse(http.StatusUnsupportedMediaType, "Content-Type diferente de YAML ou JSON."),
+		}},
+	{method: "GET", path: "/subjects/export", tag: "Matérias", summary: "Exporta as matérias",
+		params: append([]param{exportFormat}, subjectFilters...), responses: exportResponses},
+	{method: "GET", path: "/subjects/{id}", tag: "Matérias", summary: "Busca uma matéria",
+		params:    []param{ifNoneMatch},
+		responses: []response{jsonResponse[models.Subject](http.StatusOK, "A matéria.", "ETag"), notModified(), errNotFound}},
+	{method: "PUT", path: "/subjects/{id}", tag: "Matérias", summary: "Substitui uma matéria",
+		params: []param{ifMatch}, body: jsonBody[models.Subject]("Novo estado da matéria (o ID vem do caminho)."),
+		responses: []response{jsonResponse[models.Subject](http.StatusOK, "Matéria atualizada.", "ETag"), errBadRequest, errNotFound,
+			errorResponse(http.StatusPreconditionFailed, "A matéria foi alterada depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente.")}},
+	{method: "PATCH", path: "/subjects/{id}", tag: "Matérias", summary: "Altera campos de uma matéria",
+		params: []param{ifMatch}, body: mergePatchBody[models.Subject](),
+		responses: []response{jsonResponse[models.Subject](http.StatusOK, "Matéria atualizada.", "ETag"), errBadRequest, errNotFound,
+			errorResponse(http.StatusPreconditionFailed, "A matéria foi alterada depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente."),
+			errorResponse(http.StatusUnsupportedMediaType, "Content-Type diferente de application/merge-patch+json.")}},
+	{method: "DELETE", path: "/subjects/{id}", tag: "Matérias", summary: "Exclui uma matéria",
+		description: "Exclusão lógica: a matéria pode ser restaurada até o expurgo.",
+		params:      []param{ifMatch},
+		responses: []response{noContent("Matéria excluída."), errNotFound,
+			errorResponse(http.StatusPreconditionFailed, "A matéria foi alterada depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente.")}},
+	{method: "POST", path: "/subjects/{id}:restore", tag: "Matérias", summary: "Restaura uma matéria excluída",
+		responses: []response{jsonResponse[models.Subject](http.StatusOK, "Matéria restaurada.", "ETag"), errNotFound}},
+
+	// --- Alunos ---
+	{method: "POST", path: "/students", tag: "Alunos", summary: "Cria um aluno",
+		description: "Nome, ano atual e turno são obrigatórios; ID e matrícula são gerados. As matérias em subjects (pelo id) " +
+			"são associadas na mesma transação: se alguma não existir, nada é gravado (400 com a lista).",
+		body:      jsonBody[models.Student]("Aluno a criar."),
+		responses: []response{jsonResponse[models.Student](http.StatusCreated, "Aluno criado."), errBadRequest}},
+	{method: "GET", path: "/students", tag: "Alunos", summary: "Lista os alunos",
+		params:    append(studentFilters, ifNoneMatch),
+		responses: []response{jsonResponse[[]models.Student](http.StatusOK, "Alunos que atendem ao filtro, com suas matérias.", "ETag"), notModified(), errBadRequest}},
+	{method: "POST", path: "/students/import", tag: "Alunos", summary: "Importa alunos de um CSV",
+		description: "Todas as linhas são validadas antes de gravar; se alguma tiver erro, nada é gravado (422, com o resultado linha a linha).",
+		params:      []param{dryRun},
+		body: &body{description: "CSV no corpo ou no campo file de um formulário multipart.", content: map[string]reflect.Type{
+			"text/csv": nil, "multipart/form-data": reflect.TypeFor[struct {
+				File string `json:"file"`
+			}](),
+		}},
+		responses: []response{
+			jsonResponse[models.StudentImportReport](http.StatusCreated, "Alunos gravados."),
+			jsonResponse[models.StudentImportReport](http.StatusOK, "Simulação (dry_run) sem erros."),
+			jsonResponse[models.StudentImportReport](http.StatusUnprocessableEntity, "Alguma linha tem erros; nada foi gravado."),
+			errBadRequest,
+			errorResponse(http.StatusConflict, "Matrículas tomadas por criações simultâneas em todas as tentativas; nada foi gravado."),
+			errorResponse(http.StatusRequestEntityTooLarge, "Arquivo maior que 10 MB."),
+			errorResponse(http.StatusUnsupportedMediaType, "Content-Type diferente de CSV ou multipart."),
+		}},
+	{method: "GET", path: "/students/export", tag: "Alunos", summary: "Exporta os alunos",
+		params: append([]param{exportFormat}, studentFilters...), responses: exportResponses},
+	{method: "GET", path: "/students/{id}", tag: "Alunos", summary: "Busca um aluno",
+		params:    []param{ifNoneMatch},
+		responses: []response{jsonResponse[models.Student](http.StatusOK, "O aluno, com suas matérias.", "ETag"), notModified(), errNotFound}},
+	{method: "PUT", path: "/students/{id}", tag: "Alunos", summary: "Substitui um aluno",
+		params: []param{ifMatch}, body: jsonBody[models.Student]("Novo estado do aluno (o ID vem do caminho)."),
+		responses: []response{jsonResponse[models.Student](http.StatusOK, "Aluno atualizado.", "ETag"), errBadRequest, errNotFound,
+			errorResponse(http.StatusPreconditionFailed, "O aluno foi alterado depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente.")}},
+	{method: "PATCH", path: "/students/{id}", tag: "Alunos", summary: "Altera campos de um aluno",
+		params: []param{ifMatch}, body: mergePatchBody[models.Student](),
+		responses: []response{jsonResponse[models.Student](http.StatusOK, "Aluno atualizado.", "ETag"), errBadRequest, errNotFound,
+			errorResponse(http.StatusPreconditionFailed, "O aluno foi alterado depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente."),
+			errorResponse(http.StatusUnsupportedMediaType, "Content-Type diferente de application/merge-patch+json.")}},
+	{method: "DELETE", path: "/students/{id}", tag: "Alunos", summary: "Exclui um aluno",
+		description: "Exclusão lógica: o aluno pode ser restaurado até o expurgo.",
+		params:      []param{ifMatch},
+		responses: []response{noContent("Aluno excluído."), errNotFound,
+			errorResponse(http.StatusPreconditionFailed, "O aluno foi alterado depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente.")}},
+	{method: "POST", path: "/students/{id}:restore", tag: "Alunos", summary: "Restaura um aluno excluído",
+		responses: []response{jsonResponse[models.Student](http.StatusOK, "Aluno restaurado.", "ETag"), errNotFound}},
+	{method: "POST", path: "/students/{id}/status", tag: "Alunos", summary: "Muda a situação de um aluno",
+		description: "Registra a transição no histórico. Transições não permitidas respondem 409.",
+		params:      []param{ifMatch}, body: jsonBody[models.StudentStatusChange]("Nova situação, motivo e data de efeito."),
+		responses: []response{jsonResponse[models.Student](http.StatusOK, "Aluno com a nova situação.", "ETag"), errBadRequest, errNotFound, errConflict,
+			errorResponse(http.StatusPreconditionFailed, "O aluno foi alterado depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente.")}},
+	{method: "GET", path: "/students/{id}/status-history", tag: "Alunos", summary: "Lista o histórico de situações de um aluno",
+		params:    []param{ifNoneMatch},
+		responses: []response{jsonResponse[[]models.StudentStatusTransition](http.StatusOK, "Transições, da mais antiga à mais recente.", "ETag"), notModified(), errNotFound}},
+	{method: "PUT", path: "/students/{id}/program", tag: "Alunos", summary: "Vincula um aluno a um curso",
+		description: "program_id vazio desvincula o aluno.",
+		params:      []param{ifMatch}, body: jsonBody[models.StudentProgramAssignment]("Curso do aluno."),
+		responses: []response{jsonResponse[models.Student](http.StatusOK, "Aluno vinculado.", "ETag"), errBadRequest, errNotFound, errConflict,
+			errorResponse(http.StatusPreconditionFailed, "O aluno foi alterado depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente.")}},
+	{method: "GET", path: "/students/{id}/degree-audit", tag: "Alunos", summary: "Audita a formatura de um aluno",
+		description: "Compara as matérias associadas ao aluno com a grade do seu curso.",
+		responses:   []response{jsonResponse[models.DegreeAudit](http.StatusOK, "Situação do aluno na grade do curso."), errBadRequest, errNotFound, errConflict}},
+	{method: "POST", path: "/students/{studentID}/subjects/{subjectID}", tag: "Alunos", summary: "Associa uma matéria a um aluno",
+		description: "Só alunos ativos podem ser associados (senão 409). Associar uma matéria já associada não é erro.",
+		responses: []response{jsonResponse[struct {
+			Message string `json:"message"`
+		}](http.StatusOK, "Matéria associada."), errNotFound, errConflict}},
+	{method: "DELETE", path: "/students/{studentID}/subjects/{subjectID}", tag: "Alunos", summary: "Desassocia uma matéria de um aluno",
+		responses: []response{noContent("Matéria desassociada."), errNotFound}},
+
+	// --- Professores ---
+	{method: "POST", path: "/teachers", tag: "Professores", summary: "Cria um professor",
+		description: "Nome e departamento (department_id, ou código ou nome em department) são obrigatórios; ID e registro são gerados.",
+		body:        jsonBody[models.Teacher]("Professor a criar."),
+		responses:   []response{jsonResponse[models.Teacher](http.StatusCreated, "Professor criado."), errBadRequest}},
+	{method: "GET", path: "/teachers", tag: "Professores", summary: "Lista os professores",
+		params:    append(teacherFilters, ifNoneMatch),
+		responses: []response{jsonResponse[[]models.Teacher](http.StatusOK, "Professores que atendem ao filtro.", "ETag"), notModified()}},
+	{method: "GET", path: "/teachers/export", tag: "Professores", summary: "Exporta os professores",
+		params: append([]param{exportFormat}, teacherFilters...), responses: exportResponses},
+	{method: "GET", path: "/teachers/{id}", tag: "Professores", summary: "Busca um professor",
+		params:    []param{ifNoneMatch},
+		responses: []response{jsonResponse[models.Teacher](http.StatusOK, "O professor.", "ETag"), notModified(), errNotFound}},
+	{method: "PUT", path: "/teachers/{id}", tag: "Professores", summary: "Substitui um professor",
+		description: "Apenas nome e departamento mudam; o chefe de um departamento não pode ser transferido (409).",
+		params:      []param{ifMatch}, body: jsonBody[models.Teacher]("Novo estado do professor (o ID vem do caminho)."),
+		responses: []response{jsonResponse[models.Teacher](http.StatusOK, "Professor atualizado.", "ETag"), errBadRequest, errNotFound, errConflict,
+			errorResponse(http.StatusPreconditionFailed, "O professor foi alterado depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente.")}},
+	{method: "PATCH", path: "/teachers/{id}", tag: "Professores", summary: "Altera campos de um professor",
+		params: []param{ifMatch}, body: mergePatchBody[models.Teacher](),
+		responses: []response{jsonResponse[models.Teacher](http.StatusOK, "Professor atualizado.", "ETag"), errBadRequest, errNotFound, errConflict,
+			errorResponse(http.StatusPreconditionFailed, "O professor foi alterado depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente."),
+			errorResponse(http.StatusUnsupportedMediaType, "Content-Type diferente de application/merge-patch+json.")}},
+	{method: "DELETE", path: "/teachers/{id}", tag: "Professores", summary: "Exclui um professor",
+		description: "Exclusão lógica: o professor pode ser restaurado até o expurgo. O chefe de um departamento não pode ser excluído (409).",
+		params:      []param{ifMatch},
+		responses: []response{noContent("Professor excluído."), errNotFound,
+			errorResponse(http.StatusConflict, "O professor chefia um departamento."),
+			errorResponse(http.StatusPreconditionFailed, "O professor foi alterado depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente.")}},
+	{method: "POST", path: "/teachers/{id}:restore", tag: "Professores", summary: "Restaura um professor excluído",
+		responses: []response{jsonResponse[models.Teacher](http.StatusOK, "Professor restaurado.", "ETag"), errNotFound}},
+
+	// --- Departamentos ---
+	{method: "POST", path: "/departments", tag: "Departamentos", summary: "Cria um departamento",
+		description: "O código (prefixo dos registros dos professores) é normalizado para maiúsculas e não pode repetir.",
+		body:        jsonBody[models.Department]("Departamento a criar."),
+		responses:   []response{jsonResponse[models.Department](http.StatusCreated, "Departamento criado.", "ETag"), errBadRequest, errConflict}},
+	{method: "GET", path: "/departments", tag: "Departamentos", summary: "Lista os departamentos",
+		params:    []param{ifNoneMatch},
+		responses: []response{jsonResponse[[]models.Department](http.StatusOK, "Departamentos.", "ETag"), notModified()}},
+	{method: "GET", path: "/departments/{id}", tag: "Departamentos", summary: "Busca um departamento",
+		params:    []param{ifNoneMatch},
+		responses: []response{jsonResponse[models.Department](http.StatusOK, "O departamento.", "ETag"), notModified(), errNotFound}},
+	{method: "PUT", path: "/departments/{id}", tag: "Departamentos", summary: "Atualiza um departamento",
+		description: "Nome e chefe mudam; o código é imutável.",
+		params:      []param{ifMatch}, body: jsonBody[models.Department]("Novo estado do departamento (o ID vem do caminho)."),
+		responses: []response{jsonResponse[models.Department](http.StatusOK, "Departamento atualizado.", "ETag"), errBadRequest, errNotFound, errConflict,
+			errorResponse(http.StatusPreconditionFailed, "O departamento foi alterado depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente.")}},
+	{method: "DELETE", path: "/departments/{id}", tag: "Departamentos", summary: "Exclui um departamento",
+		description: "Só departamentos sem professores ativos (senão 409).",
+		params:      []param{ifMatch},
+		responses: []response{noContent("Departamento excluído."), errNotFound, errConflict,
+			errorResponse(http.StatusPreconditionFailed, "O departamento foi alterado depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente.")}},
+	{method: "POST", path: "/departments/{id}:restore", tag: "Departamentos", summary: "Restaura um departamento excluído",
+		responses: []response{jsonResponse[models.Department](http.StatusOK, "Departamento restaurado.", "ETag"), errNotFound, errConflict}},
+
+	// --- Cursos ---
+	{method: "POST", path: "/programs", tag: "Cursos", summary: "Cria um curso",
+		description: "Nome, ano e créditos das matérias da grade vêm do catálogo e são ignorados na entrada.",
+		body:        jsonBody[models.Program]("Curso e sua grade."),
+		responses:   []response{jsonResponse[models.Program](http.StatusCreated, "Curso criado.", "ETag"), errBadRequest, errConflict}},
+	{method: "GET", path: "/programs", tag: "Cursos", summary: "Lista os cursos",
+		params:    []param{ifNoneMatch},
+		responses: []response{jsonResponse[[]models.Program](http.StatusOK, "Cursos.", "ETag"), notModified()}},
+	{method: "GET", path: "/programs/{id}", tag: "Cursos", summary: "Busca um curso",
+		params:    []param{ifNoneMatch},
+		responses: []response{jsonResponse[models.Program](http.StatusOK, "O curso e sua grade.", "ETag"), notModified(), errNotFound}},
+	{method: "PUT", path: "/programs/{id}", tag: "Cursos", summary: "Substitui um curso",
+		params: []param{ifMatch}, body: jsonBody[models.Program]("Novo estado do curso (o ID vem do caminho)."),
+		responses: []response{jsonResponse[models.Program](http.StatusOK, "Curso atualizado.", "ETag"), errBadRequest, errNotFound, errConflict,
+			errorResponse(http.StatusPreconditionFailed, "O curso foi alterado depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente.")}},
+	{method: "DELETE", path: "/programs/{id}", tag: "Cursos", summary: "Exclui um curso",
+		params: []param{ifMatch},
+		responses: []response{noContent("Curso excluído."), errNotFound, errConflict,
+			errorResponse(http.StatusPreconditionFailed, "O curso foi alterado depois do GET."), errorResponse(http.StatusPreconditionRequired, "If-Match ausente.")}},
+
+	// --- Auditoria ---
+	{method: "GET", path: "/audit", tag: "Auditoria", summary: "Consulta a trilha de auditoria",
+		description: "Exige chave de API mesmo com a API aberta: sem API_KEYS configurada, responde sempre 401. Nomes de pessoas aparecem omitidos.",
+		params: []param{
+			query("entity", "Tabela afetada (ex: students).", stringSchema),
+			query("id", "ID do registro afetado.", stringSchema),
+			query("limit", "Máximo de registros, dos mais recentes.", integerSchema),
+		},
+		responses: []response{jsonResponse[[]models.AuditEntry](http.StatusOK, "Registros de auditoria, do mais recente ao mais antigo."), errBadRequest}},
+
+	// --- Administração (ENABLE_ADMIN_ROUTES) ---
+	{method: "POST", path: "/admin/purge", tag: "Administração", summary: "Expurga registros excluídos",
+		description: "Remove definitivamente os registros excluídos logicamente há mais tempo que a retenção (padrão: PURGE_RETENTION).",
+		params:      []param{query("retention", "Retenção como duração Go (ex: 720h).", stringSchema)},
+		responses:   []response{jsonResponse[models.PurgeReport](http.StatusOK, "Registros removidos."), errBadRequest}},
+	{method: "POST", path: "/admin/rollover", tag: "Administração", summary: "Executa a virada de ano letivo",
+		params: []param{dryRun,
+			query("min_credit_share", "Fração mínima dos créditos do ano atual para promoção (0 a 1).", map[string]any{"type": "number"}),
+			query("final_year", "Último ano do curso; quem o conclui vira formando.", integerSchema),
+		},
+		responses: []response{
+			jsonResponse[models.RolloverReport](http.StatusCreated, "Virada executada."),
+			jsonResponse[models.RolloverReport](http.StatusOK, "Prévia (dry_run)."),
+			errBadRequest,
+		}},
+	{method: "GET", path: "/admin/rollovers", tag: "Administração", summary: "Lista as viradas de ano letivo",
+		params:    []param{query("limit", "Máximo de viradas, das mais recentes.", integerSchema)},
+		responses: []response{jsonResponse[[]models.RolloverRun](http.StatusOK, "Viradas executadas."), errBadRequest}},
+	{method: "POST", path: "/admin/rollovers/{id}:undo", tag: "Administração", summary: "Desfaz uma virada de ano letivo",
+		description: "Apenas dentro da janela ROLLOVER_UNDO_WINDOW e se nenhum aluno envolvido foi alterado depois (senão 409).",
+		responses:   []response{jsonResponse[models.RolloverRun](http.StatusOK, "Virada desfeita."), errBadRequest, errNotFound, errConflict}},
+
+	// --- Operação ---
+	{method: "GET", path: "/healthz", tag: "Operação", summary: "Verifica se o processo está no ar", public: true,
+		responses: []response{jsonResponse[struct {
+			Status string `json:"status"`
+		}](http.StatusOK, "O processo responde (não consulta o banco).")}},
+	{method: "GET", path: "/readyz", tag: "Operação", summary: "Verifica se a API está pronta", public: true,
+		description: "Verifica a conexão com o banco e a versão do esquema.",
+		responses: []response{
+			jsonResponse[models.Readiness](http.StatusOK, "Todas as verificações passaram."),
+			jsonResponse[models.Readiness](http.StatusServiceUnavailable, "Alguma verificação falhou."),
+		}},
+	{method: "GET", path: "/version", tag: "Operação", summary: "Identifica o código em execução", public: true,
+		responses: []response{jsonResponse[models.VersionInfo](http.StatusOK, "Commit, compilação e versão do esquema.")}},
+	{method: "GET", path: "/metrics", tag: "Operação", summary: "Métricas Prometheus",
+		description: "Registrada apenas com ENABLE_METRICS=true.",
+		responses:   []response{{status: http.StatusOK, description: "Métricas no formato de exposição do Prometheus.", contentType: "text/plain"}}},
+	{method: "GET", path: "/openapi.json", tag: "Operação", summary: "Esta especificação", public: true,
+		responses: []response{{status: http.StatusOK, description: "Documento OpenAPI 3.1.", contentType: "application/json"}}},
+	{method: "GET", path: "/docs", tag: "Operação", summary: "Documentação interativa", public: true,
+		responses: []response{{status: http.StatusOK, description: "Página HTML que lê /openapi.json.", contentType: "text/html"}}},
+}
@@ -0,0 +1,52 @@
+// api/openapi/routes_test.go
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCheckRoutesReportsMissing(t *testing.T) {
+	h := func(http.ResponseWriter, *http.Request) {}
+	router := mux.NewRouter()
+	router.HandleFunc("/students", h).Methods("GET")
+	router.HandleFunc("/students/{id}/exemplo", h).Methods("GET", "POST")
+	router.HandleFunc("/sem-metodo", h)
+
+	err := CheckRoutes(router)
+	if err == nil {
+		t.Fatal("esperava erro para rotas não descritas")
+	}
+	for _, want := range []string{"GET /students/{id}/exemplo", "POST /students/{id}/exemplo", "/sem-metodo"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("erro não cita %q: %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "GET /students,") {
+		t.Errorf("GET /students está descrita e não deveria ser citada: %v", err)
+	}
+}
+
+func TestDocumentOperationIDsUnique(t *testing.T) {
+	var doc struct {
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(Document(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]string{}
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			if prev, ok := seen[op.OperationID]; ok {
+				t.Errorf("operationId %q repetido em %s %s e %s", op.OperationID, method, path, prev)
+			}
+			seen[op.OperationID] = method + " " + path
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// repositories/audit_repository.go
+package repositories
+
+import (
+	"college_api/logging"
+	"college_api/models"
+	"college_api/requestctx"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+)
+
+// Ações registradas na trilha de auditoria.
+const (
+	AuditActionCreate     = "create"
+	AuditActionUpdate     = "update"
+	AuditActionDelete     = "delete"
+	AuditActionAssociate  = "associate"
+	AuditActionDissociate = "dissociate"
+	AuditActionRestore    = "restore"
+	AuditActionPurge      = "purge"
+)
+
+// Entidades auditadas (nomes das tabelas).
+const (
+	AuditEntityStudents        = "students"
+	AuditEntitySubjects        = "subjects"
+	AuditEntityTeachers        = "teachers"
+	AuditEntityStudentSubjects = "student_subjects"
+	AuditEntityPrograms        = "programs"
+	AuditEntityDepartments     = "departments"
+)
+
+// AuditRedacted substitui na auditoria o valor dos campos pessoais (ver auditPersonalFields).
+const AuditRedacted = "[omitido]"
+
+// auditPersonalFields lista, por entidade, os campos com dados pessoais. A auditoria registra que eles
+// mudaram, mas não os valores: a trilha é somente inserção e não poderia ser corrigida depois.
+var auditPersonalFields = map[string][]string{
+	AuditEntityStudents: {"name"},
+	AuditEntityTeachers: {"name"},
+}
+
+// AuditRepository consulta a trilha de auditoria.
+// A escrita é feita por recordAudit, dentro da transação de cada alteração.
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository cria uma nova instância de AuditRepository.
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// ListAuditEntries busca os registros de uma entidade, opcionalmente filtrando pelo ID, do mais recente ao mais antigo.
+func (r *AuditRepository) ListAuditEntries(ctx context.Context, entity, entityID string, limit int) ([]models.AuditEntry, error) {
+	ctx, done := observeQuery(ctx, "audit", "ListAuditEntries")
+	defer done()
+	query := `
+		SELECT id, occurred_at, actor, action, entity, entity_id, before, after, diff, request_id
+		FROM audit_log
+		WHERE entity = $1 AND ($2 = '' OR entity_id = $2)
+		ORDER BY id DESC
+		LIMIT $3`
+	rows, err := r.db.QueryContext(ctx, query, entity, entityID, limit)
+	if err != nil {
+		logging.FromContext(ctx).Error("ListAuditEntries: erro ao consultar auditoria", "entity", entity, "entity_id", entityID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after, diff []byte
+		var requestID sql.NullString
+		if err := rows.Scan(&entry.ID, &entry.OccurredAt, &entry.Actor, &entry.Action, &entry.Entity, &entry.EntityID, &before, &after, &diff, &requestID); err != nil {
+			logging.FromContext(ctx).Error("ListAuditEntries: erro ao escanear registro de auditoria", "error", err)
+			return nil, err
+		}
+		entry.Before = before
+		entry.After = after
+		entry.Diff = diff
+		entry.RequestID = requestID.String
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// recordAudit grava um registro de auditoria na transação tx.
+// before e after são os estados do registro (nil em criações e exclusões, respectivamente);
+// o ator e o ID da requisição vêm do contexto. Os campos pessoais da entidade são gravados como
+// AuditRedacted, inclusive na diferença, que é calculada antes e por isso ainda indica se mudaram.
+func recordAudit(ctx context.Context, tx *sql.Tx, action, entity, entityID string, before, after interface{}) error {
+	beforeMap, err := auditSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterMap, err := auditSnapshot(after)
+	if err != nil {
+		return err
+	}
+	diff := auditDiff(beforeMap, afterMap)
+	redactAudit(entity, beforeMap, afterMap, diff)
+
+	beforeJSON, err := marshalNullable(beforeMap)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := marshalNullable(afterMap)
+	if err != nil {
+		return err
+	}
+	diffJSON, err := marshalNullable(diff)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_log (actor, action, entity, entity_id, before, after, diff, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))`
+	_, err = tx.ExecContext(ctx, query, requestctx.Actor(ctx), action, entity, entityID, beforeJSON, afterJSON, diffJSON, requestctx.RequestID(ctx))
+	if err != nil {
+		logging.FromContext(ctx).Error("recordAudit: erro ao gravar auditoria", "action", action, "entity", entity, "entity_id", entityID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// auditSnapshot converte um registro em mapa campo -> valor usando suas tags JSON.
+// Listas de matérias são ignoradas: associações têm registros próprios (student_subjects).
+func auditSnapshot(v interface{}) (map[string]interface{}, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, err
+	}
+	delete(snapshot, "subjects")
+	return snapshot, nil
+}
+
+// redactAudit omite os campos pessoais da entidade nos estados e na diferença de um registro.
+func redactAudit(entity string, before, after, diff map[string]interface{}) {
+	for _, field := range auditPersonalFields[entity] {
+		redactAuditField(before, field)
+		redactAuditField(after, field)
+		if change, ok := diff[field].(map[string]interface{}); ok {
+			redactAuditField(change, "from")
+			redactAuditField(change, "to")
+		}
+	}
+}
+
+// redactAuditField troca por AuditRedacted o valor de field em snapshot, se houver um.
+func redactAuditField(snapshot map[string]interface{}, field string) {
+	if value, ok := snapshot[field]; ok && value != nil && value != "" {
+		snapshot[field] = AuditRedacted
+	}
+}
+
+// auditDiff lista os campos que mudaram entre before e after.
+func auditDiff(before, after map[string]interface{}) map[string]interface{} {
+	diff := map[string]interface{}{}
+	for field, newValue := range after {
+		oldValue, existed := before[field]
+		if !existed || !reflect.DeepEqual(oldValue, newValue) {
+			diff[field] = map[string]interface{}{"from": oldValue, "to": newValue}
+		}
+	}
+	for field, oldValue := range before {
+		if _, stillExists := after[field]; !stillExists {
+			diff[field] = map[string]interface{}{"from": oldValue, "to": nil}
+		}
+	}
+	if len(diff) == 0 {
+		return nil
+	}
+	return diff
+}
+
+// marshalNullable serializa v em JSON, devolvendo nil (NULL no banco) para mapas vazios.
+func marshalNullable(v map[string]interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
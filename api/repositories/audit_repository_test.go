@@ -0,0 +1,34 @@
+// api/repositories/audit_repository_test.go
+package repositories
+
+import (
+	"college_api/models"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRedactAuditOmitsPersonalFields(t *testing.T) {
+	before, _ := auditSnapshot(&models.Student{ID: "s1", Name: "Maria da Silva", Shift: "M"})
+	after, _ := auditSnapshot(&models.Student{ID: "s1", Name: "Maria Souza", Shift: "N"})
+	diff := auditDiff(before, after)
+	redactAudit(AuditEntityStudents, before, after, diff)
+
+	raw, _ := json.Marshal([]interface{}{before, after, diff})
+	if strings.Contains(string(raw), "Maria") {
+		t.Fatalf("o nome não deveria ser gravado na auditoria: %s", raw)
+	}
+	if _, changed := diff["name"]; !changed {
+		t.Error("a diferença deveria registrar que o nome mudou")
+	}
+	if change := diff["shift"].(map[string]interface{}); change["to"] != "N" {
+		t.Errorf("campos não pessoais deveriam ser mantidos, veio %v", change)
+	}
+
+	// Matérias não têm dados pessoais: o nome é mantido
+	subject, _ := auditSnapshot(&models.Subject{ID: "MAT101", Name: "Cálculo I"})
+	redactAudit(AuditEntitySubjects, nil, subject, nil)
+	if subject["name"] != "Cálculo I" {
+		t.Errorf("o nome da matéria não deveria ser omitido, veio %v", subject["name"])
+	}
+}
@@ -0,0 +1,224 @@
+// repositories/backup_repository.go
+package repositories
+
+import (
+	"college_api/logging"
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// BackupTable descreve uma tabela incluída no backup.
+type BackupTable struct {
+	Name       string
+	Columns    []string
+	Key        []string          // Chave primária (usada para detectar duplicatas e ordenar)
+	References map[string]string // Coluna -> tabela referenciada (chave de uma coluna; NULL é aceito)
+}
+
+// BackupTables lista as tabelas do backup na ordem de restauração: uma tabela só referencia as anteriores.
+// Ao mudar tabelas ou colunas, incremente services.BackupVersion e acrescente a conversão da versão
+// anterior em services.backupUpgrades.
+// A trilha de auditoria não faz parte do backup.
+var BackupTables = []BackupTable{
+	{Name: "subjects", Columns: []string{"id", "name", "year", "credits", "version", "deleted_at"}, Key: []string{"id"}},
+	// O chefe do departamento referencia teachers, que vem depois: a restrição é verificada só no commit.
+	{Name: "departments", Columns: []string{"id", "code", "name", "head_teacher_id", "version", "deleted_at"}, Key: []string{"id"}},
+	{
+		Name:       "teachers",
+		Columns:    []string{"id", "registry", "name", "department_id", "department", "version", "deleted_at"},
+		Key:        []string{"id"},
+		References: map[string]string{"department_id": "departments"},
+	},
+	{Name: "programs", Columns: []string{"id", "name", "version"}, Key: []string{"id"}},
+	{
+		Name:       "program_subjects",
+		Columns:    []string{"program_id", "subject_id", "kind"},
+		Key:        []string{"program_id", "subject_id"},
+		References: map[string]string{"program_id": "programs", "subject_id": "subjects"},
+	},
+	{
+		Name:       "program_year_requirements",
+		Columns:    []string{"program_id", "year", "min_credits"},
+		Key:        []string{"program_id", "year"},
+		References: map[string]string{"program_id": "programs"},
+	},
+	{
+		Name:       "students",
+		Columns:    []string{"id", "enrollment", "name", "current_year", "shift", "graduating", "status", "program_id", "version", "deleted_at"},
+		Key:        []string{"id"},
+		References: map[string]string{"program_id": "programs"},
+	},
+	{
+		Name:       "student_status_history",
+		Columns:    []string{"id", "student_id", "from_status", "to_status", "reason", "effective_date", "actor", "recorded_at"},
+		Key:        []string{"id"},
+		References: map[string]string{"student_id": "students"},
+	},
+	{
+		Name:       "student_subjects",
+		Columns:    []string{"student_id", "subject_id"},
+		Key:        []string{"student_id", "subject_id"},
+		References: map[string]string{"student_id": "students", "subject_id": "subjects"},
+	},
+}
+
+// BackupRepository lê e grava tabelas inteiras para backup e restauração, sem COPY nem pg_dump.
+// Usa apenas SELECT e INSERT simples; o que muda entre bancos fica em backupDialect, escolhido
+// pelo driver de db. Assim o mesmo backup pode ser gerado e restaurado no PostgreSQL, no SQLite
+// (arquivo ou :memory:) ou em outro banco com as mesmas tabelas.
+type BackupRepository struct {
+	db      *sql.DB
+	dialect backupDialect
+}
+
+// NewBackupRepository cria uma nova instância de BackupRepository para o banco de db.
+func NewBackupRepository(db *sql.DB) *BackupRepository {
+	return &BackupRepository{db: db, dialect: backupDialectFor(db)}
+}
+
+// backupDialect descreve as diferenças entre bancos que o backup precisa levar em conta.
+type backupDialect struct {
+	placeholder func(n int) string // Parâmetro de número n (a partir de 1)
+	snapshot    *sql.TxOptions     // Opções da transação em que todas as tabelas são lidas
+	restoreInit []string           // Comandos executados no início da restauração
+}
+
+var (
+	// PostgreSQL: o chefe do departamento é verificado só no commit (DEFERRABLE INITIALLY DEFERRED).
+	postgresBackupDialect = backupDialect{
+		placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+		snapshot:    &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true},
+	}
+	// SQLite: a transação já lê um estado único do banco; as chaves estrangeiras são adiadas para o commit,
+	// porque departamentos e professores se referenciam.
+	sqliteBackupDialect = backupDialect{
+		placeholder: func(int) string { return "?" },
+		restoreInit: []string{"PRAGMA defer_foreign_keys = ON"},
+	}
+	// Demais bancos (ex: um banco em memória nos testes): parâmetros ? e nenhuma opção especial.
+	genericBackupDialect = backupDialect{
+		placeholder: func(int) string { return "?" },
+	}
+)
+
+// backupDialectFor escolhe o dialeto pelo driver de db.
+func backupDialectFor(db *sql.DB) backupDialect {
+	if _, ok := db.Driver().(*pq.Driver); ok {
+		return postgresBackupDialect
+	}
+	if strings.Contains(strings.ToLower(fmt.Sprintf("%T", db.Driver())), "sqlite") {
+		return sqliteBackupDialect
+	}
+	return genericBackupDialect
+}
+
+// BeginSnapshot abre uma transação somente leitura em que todas as tabelas são lidas no mesmo instante.
+func (r *BackupRepository) BeginSnapshot(ctx context.Context) (*sql.Tx, error) {
+	ctx, done := observeStream(ctx, "backup", "BeginSnapshot")
+	defer done()
+	return r.db.BeginTx(ctx, r.dialect.snapshot)
+}
+
+// BeginTx abre uma transação de escrita para a restauração.
+func (r *BackupRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range r.dialect.restoreInit {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// DumpTable percorre as linhas da tabela em ordem de chave, chamando fn com os valores por coluna.
+// Datas são convertidas para UTC e textos binários para string, para que o JSON seja portável.
+func (r *BackupRepository) DumpTable(ctx context.Context, tx *sql.Tx, table BackupTable, fn func(row map[string]interface{}) error) error {
+	ctx, done := observeStream(ctx, "backup", "DumpTable")
+	defer done()
+	query := fmt.Sprintf("SELECT %s FROM %s ORDER BY %s", strings.Join(table.Columns, ", "), table.Name, strings.Join(table.Key, ", "))
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		logging.FromContext(ctx).Error("DumpTable: erro ao ler tabela", "table", table.Name, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	values := make([]interface{}, len(table.Columns))
+	pointers := make([]interface{}, len(table.Columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			logging.FromContext(ctx).Error("DumpTable: erro ao escanear linha", "table", table.Name, "error", err)
+			return err
+		}
+		row := make(map[string]interface{}, len(table.Columns))
+		for i, column := range table.Columns {
+			switch v := values[i].(type) {
+			case time.Time:
+				row[column] = v.UTC()
+			case []byte:
+				row[column] = string(v)
+			default:
+				row[column] = v
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// IsEmpty informa se todas as tabelas do backup estão vazias (inclusive sem registros excluídos logicamente).
+func (r *BackupRepository) IsEmpty(ctx context.Context, tx *sql.Tx) (bool, error) {
+	ctx, done := observeQuery(ctx, "backup", "IsEmpty")
+	defer done()
+	for _, table := range BackupTables {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+table.Name+")").Scan(&exists); err != nil {
+			logging.FromContext(ctx).Error("IsEmpty: erro ao verificar tabela", "table", table.Name, "error", err)
+			return false, err
+		}
+		if exists {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// InsertRow insere uma linha na tabela, apenas com as colunas presentes em row
+// (colunas ausentes recebem o valor padrão do banco).
+func (r *BackupRepository) InsertRow(ctx context.Context, tx *sql.Tx, table BackupTable, row map[string]interface{}) error {
+	ctx, done := observeQuery(ctx, "backup", "InsertRow")
+	defer done()
+	columns := make([]string, 0, len(row))
+	placeholders := make([]string, 0, len(row))
+	args := make([]interface{}, 0, len(row))
+	for _, column := range table.Columns {
+		value, ok := row[column]
+		if !ok {
+			continue
+		}
+		columns = append(columns, column)
+		args = append(args, value)
+		placeholders = append(placeholders, r.dialect.placeholder(len(args)))
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table.Name, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		logging.FromContext(ctx).Error("InsertRow: erro ao inserir linha", "table", table.Name, "error", err)
+		return err
+	}
+	return nil
+}
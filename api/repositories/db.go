@@ -0,0 +1,146 @@
+// repositories/db.go
+package repositories
+
+import (
+	"college_api/logging"
+	"college_api/metrics"
+	"college_api/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrVersionConflict indica que o registro foi alterado por outra requisição desde que o cliente o leu.
+var ErrVersionConflict = errors.New("versão do registro não confere")
+
+// checkVersion compara a versão esperada pelo cliente com a atual. expected 0 dispensa a verificação.
+func checkVersion(expected, current int) error {
+	if expected != 0 && expected != current {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// dbConn é o subconjunto de métodos comum a *sql.DB e *sql.Tx usado nas leituras dos repositórios.
+type dbConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// connFor devolve a transação vinculada ao repositório (via WithTx) ou, se não houver, o pool.
+func connFor(db *sql.DB, tx *sql.Tx) dbConn {
+	if tx != nil {
+		return tx
+	}
+	return db
+}
+
+// whereClause monta um WHERE dinâmico com parâmetros posicionais ($1, $2, ...).
+type whereClause struct {
+	conds []string
+	args  []interface{}
+}
+
+// add acrescenta uma condição; o "?" em cond é substituído pelo parâmetro de arg.
+func (w *whereClause) add(cond string, arg interface{}) {
+	w.args = append(w.args, arg)
+	w.conds = append(w.conds, strings.Replace(cond, "?", "$"+strconv.Itoa(len(w.args)), 1))
+}
+
+// String junta as condições com AND.
+func (w *whereClause) String() string {
+	if len(w.conds) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(w.conds, " AND ")
+}
+
+// likePattern monta um padrão ILIKE de "contém", escapando os curingas digitados pelo usuário.
+func likePattern(s string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s) + "%"
+}
+
+// withTx executa fn dentro de uma transação, fazendo commit se fn não retornar erro
+// e rollback caso contrário. Se outer não for nil (repositório vinculado a uma transação
+// via WithTx), fn roda nela e o commit/rollback fica a cargo de quem a abriu.
+func withTx(ctx context.Context, db *sql.DB, outer *sql.Tx, fn func(tx *sql.Tx) error) error {
+	if outer != nil {
+		return fn(outer)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tracing.RecordError(trace.SpanFromContext(ctx), err)
+		if rbErr := tx.Rollback(); rbErr != nil {
+			logging.FromContext(ctx).Error("withTx: erro ao fazer rollback", "error", rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// DefaultQueryTimeout é o limite padrão de cada operação dos repositórios.
+const DefaultQueryTimeout = 30 * time.Second
+
+// queryTimeout é o limite de tempo aplicado por observeQuery (0 desabilita).
+var queryTimeout = DefaultQueryTimeout
+
+// SetQueryTimeout define o limite de tempo de cada operação dos repositórios (0 desabilita).
+// Deve ser chamado na inicialização, antes de atender requisições.
+func SetQueryTimeout(d time.Duration) {
+	queryTimeout = d
+}
+
+// observeQuery prepara o contexto de uma operação do repositório (todas as consultas do método):
+// aplica o limite de tempo configurado, abre um span com o nome da operação (sem os valores dos
+// parâmetros) se o contexto fizer parte de um trace e, ao final, registra a duração em
+// college_db_query_duration_seconds. Se o cliente desconectar ou o limite estourar, as consultas
+// em andamento são canceladas no banco. Uso:
+//
+//	ctx, done := observeQuery(ctx, "students", "GetStudentByID")
+//	defer done()
+func observeQuery(ctx context.Context, repository, operation string) (context.Context, func()) {
+	return observe(ctx, repository, operation, queryTimeout)
+}
+
+// observeStream é observeQuery sem limite de tempo, para operações que entregam as linhas a um
+// callback (exportações e backup) e duram o quanto o cliente levar para recebê-las, ou que abrem
+// uma transação devolvida a quem chamou. O cancelamento pelo cliente continua valendo.
+func observeStream(ctx context.Context, repository, operation string) (context.Context, func()) {
+	return observe(ctx, repository, operation, 0)
+}
+
+// observe implementa observeQuery e observeStream; timeout 0 dispensa o limite.
+func observe(ctx context.Context, repository, operation string, timeout time.Duration) (context.Context, func()) {
+	start := time.Now()
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	var span trace.Span
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		ctx, span = tracing.StartQuery(ctx, repository, operation)
+	}
+	return ctx, func() {
+		cancel()
+		metrics.DBQueryDuration.WithLabelValues(repository, operation).Observe(time.Since(start).Seconds())
+		if span != nil {
+			span.End()
+		}
+	}
+}
+
+// isUniqueViolation informa se err é a violação da restrição de unicidade informada.
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
@@ -0,0 +1,159 @@
+// api/repositories/db_test.go
+package repositories
+
+import (
+	"college_api/models"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingDriver é um driver database/sql cujas consultas só terminam quando o contexto é cancelado,
+// simulando uma consulta lenta ou um banco que parou de responder.
+type blockingDriver struct {
+	mu        sync.Mutex
+	commits   int
+	rollbacks int
+}
+
+func (d *blockingDriver) Open(string) (driver.Conn, error)             { return &blockingConn{d}, nil }
+func (d *blockingDriver) Connect(context.Context) (driver.Conn, error) { return &blockingConn{d}, nil }
+func (d *blockingDriver) Driver() driver.Driver                        { return d }
+
+type blockingConn struct{ d *blockingDriver }
+
+func (c *blockingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("blockingConn: Prepare não suportado")
+}
+func (c *blockingConn) Close() error              { return nil }
+func (c *blockingConn) Begin() (driver.Tx, error) { return &blockingTx{c.d}, nil }
+func (c *blockingConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return &blockingTx{c.d}, nil
+}
+
+func (c *blockingConn) QueryContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Rows, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (c *blockingConn) ExecContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Result, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+type blockingTx struct{ d *blockingDriver }
+
+func (t *blockingTx) Commit() error {
+	t.d.mu.Lock()
+	defer t.d.mu.Unlock()
+	t.d.commits++
+	return nil
+}
+
+func (t *blockingTx) Rollback() error {
+	t.d.mu.Lock()
+	defer t.d.mu.Unlock()
+	t.d.rollbacks++
+	return nil
+}
+
+func openBlockingDB(t *testing.T) (*sql.DB, *blockingDriver) {
+	t.Helper()
+	d := &blockingDriver{}
+	db := sql.OpenDB(d)
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+// withQueryTimeout troca o limite de tempo das operações durante o teste.
+func withQueryTimeout(t *testing.T, d time.Duration) {
+	t.Helper()
+	previous := queryTimeout
+	SetQueryTimeout(d)
+	t.Cleanup(func() { SetQueryTimeout(previous) })
+}
+
+func TestQueryTimeoutAbortsQuery(t *testing.T) {
+	withQueryTimeout(t, 20*time.Millisecond)
+	db, _ := openBlockingDB(t)
+
+	start := time.Now()
+	_, err := NewSubjectRepository(db).GetSubjectByID(context.Background(), "MAT101")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("esperava context.DeadlineExceeded, veio %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("a consulta deveria ser abortada pelo limite de 20ms, levou %s", elapsed)
+	}
+}
+
+func TestCanceledContextAbortsQuery(t *testing.T) {
+	withQueryTimeout(t, time.Minute)
+	db, _ := openBlockingDB(t)
+
+	// Cliente que desconecta no meio da consulta
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := NewSubjectRepository(db).GetAllSubjects(ctx, models.SubjectFilter{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("esperava context.Canceled, veio %v", err)
+	}
+}
+
+func TestQueryTimeoutRollsBackTransaction(t *testing.T) {
+	withQueryTimeout(t, 20*time.Millisecond)
+	db, d := openBlockingDB(t)
+
+	err := NewSubjectRepository(db).DeleteSubject(context.Background(), "MAT101", 0)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("esperava context.DeadlineExceeded, veio %v", err)
+	}
+	// O database/sql desfaz a transação em segundo plano quando o contexto termina
+	deadline := time.Now().Add(time.Second)
+	for {
+		d.mu.Lock()
+		commits, rollbacks := d.commits, d.rollbacks
+		d.mu.Unlock()
+		if commits != 0 {
+			t.Fatalf("a transação não deveria ser confirmada: %d commits", commits)
+		}
+		if rollbacks > 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("a transação deveria ser desfeita")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestObserveDeadlines(t *testing.T) {
+	withQueryTimeout(t, time.Second)
+
+	ctx, done := observeQuery(context.Background(), "subjects", "GetSubjectByID")
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("observeQuery deveria aplicar o limite de tempo")
+	}
+	done()
+	if ctx.Err() == nil {
+		t.Error("done deveria liberar o contexto da operação")
+	}
+
+	ctx, done = observeStream(context.Background(), "students", "StreamStudents")
+	defer done()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("observeStream não deveria aplicar limite de tempo")
+	}
+
+	SetQueryTimeout(0)
+	ctx, done = observeQuery(context.Background(), "subjects", "GetSubjectByID")
+	defer done()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("com limite 0, observeQuery não deveria aplicar limite de tempo")
+	}
+}
@@ -0,0 +1,227 @@
+// repositories/department_repository.go
+package repositories
+
+import (
+	"college_api/logging"
+	"college_api/models"
+	"context"
+	"database/sql"
+)
+
+// DepartmentRepository define as operações de CRUD para departamentos.
+type DepartmentRepository struct {
+	db *sql.DB
+	tx *sql.Tx // Transação externa, quando o repositório foi obtido via WithTx
+}
+
+// NewDepartmentRepository cria uma nova instância de DepartmentRepository.
+func NewDepartmentRepository(db *sql.DB) *DepartmentRepository {
+	return &DepartmentRepository{db: db}
+}
+
+// WithTx devolve uma cópia do repositório que executa todas as operações dentro de tx.
+func (r *DepartmentRepository) WithTx(tx *sql.Tx) *DepartmentRepository {
+	return &DepartmentRepository{db: r.db, tx: tx}
+}
+
+// conn devolve a conexão usada pelas leituras: a transação vinculada ou o pool.
+func (r *DepartmentRepository) conn() dbConn {
+	return connFor(r.db, r.tx)
+}
+
+// selectDepartmentSQL é a consulta base dos departamentos.
+const selectDepartmentSQL = `SELECT id, code, name, COALESCE(head_teacher_id, ''), version FROM departments`
+
+// scanDepartment lê um departamento de *sql.Row ou *sql.Rows (colunas de selectDepartmentSQL).
+func scanDepartment(row interface{ Scan(...interface{}) error }) (*models.Department, error) {
+	department := &models.Department{}
+	if err := row.Scan(&department.ID, &department.Code, &department.Name, &department.HeadTeacherID, &department.Version); err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+// CreateDepartment insere um novo departamento.
+func (r *DepartmentRepository) CreateDepartment(ctx context.Context, department *models.Department) error {
+	ctx, done := observeQuery(ctx, "departments", "CreateDepartment")
+	defer done()
+	err := withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		query := `INSERT INTO departments (id, code, name, head_teacher_id) VALUES ($1, $2, $3, NULLIF($4, ''))`
+		if _, err := tx.ExecContext(ctx, query, department.ID, department.Code, department.Name, department.HeadTeacherID); err != nil {
+			logging.FromContext(ctx).Error("CreateDepartment: erro ao inserir departamento", "department_code", department.Code, "error", err)
+			return err
+		}
+		department.Version = 1 // Valor padrão da coluna
+		return recordAudit(ctx, tx, AuditActionCreate, AuditEntityDepartments, department.ID, nil, department)
+	})
+	if err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Info("CreateDepartment: departamento criado", "department_id", department.ID, "department_code", department.Code)
+	return nil
+}
+
+// GetDepartmentByID busca um departamento ativo pelo ID. Retorna nil se não existir.
+func (r *DepartmentRepository) GetDepartmentByID(ctx context.Context, id string) (*models.Department, error) {
+	ctx, done := observeQuery(ctx, "departments", "GetDepartmentByID")
+	defer done()
+	department, err := scanDepartment(r.conn().QueryRowContext(ctx, selectDepartmentSQL+` WHERE id = $1 AND deleted_at IS NULL`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("GetDepartmentByID: erro ao buscar departamento", "department_id", id, "error", err)
+		return nil, err
+	}
+	return department, nil
+}
+
+// FindDepartment busca um departamento ativo pelo código ou pelo nome, sem diferenciar maiúsculas.
+// O código tem precedência sobre o nome. Retorna nil se não existir.
+func (r *DepartmentRepository) FindDepartment(ctx context.Context, codeOrName string) (*models.Department, error) {
+	ctx, done := observeQuery(ctx, "departments", "FindDepartment")
+	defer done()
+	query := selectDepartmentSQL + `
+		WHERE deleted_at IS NULL AND LOWER($1) IN (LOWER(code), LOWER(name))
+		ORDER BY LOWER(code) = LOWER($1) DESC, code
+		LIMIT 1`
+	department, err := scanDepartment(r.conn().QueryRowContext(ctx, query, codeOrName))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("FindDepartment: erro ao buscar departamento", "department", codeOrName, "error", err)
+		return nil, err
+	}
+	return department, nil
+}
+
+// CodeExists informa se algum departamento, inclusive excluído logicamente, já usa o código.
+func (r *DepartmentRepository) CodeExists(ctx context.Context, code string) (bool, error) {
+	ctx, done := observeQuery(ctx, "departments", "CodeExists")
+	defer done()
+	var exists bool
+	err := r.conn().QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM departments WHERE LOWER(code) = LOWER($1))`, code).Scan(&exists)
+	return exists, err
+}
+
+// GetAllDepartments busca todos os departamentos ativos, ordenados pelo código.
+func (r *DepartmentRepository) GetAllDepartments(ctx context.Context) ([]models.Department, error) {
+	ctx, done := observeQuery(ctx, "departments", "GetAllDepartments")
+	defer done()
+	rows, err := r.conn().QueryContext(ctx, selectDepartmentSQL+` WHERE deleted_at IS NULL ORDER BY code`)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetAllDepartments: erro ao consultar departamentos", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	departments := []models.Department{}
+	for rows.Next() {
+		department, err := scanDepartment(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("GetAllDepartments: erro ao escanear departamento", "error", err)
+			return nil, err
+		}
+		departments = append(departments, *department)
+	}
+	return departments, rows.Err()
+}
+
+// UpdateDepartment atualiza o nome e o chefe do departamento (o código não muda).
+// Se department.Version for diferente de zero, ela precisa ser a versão atual (senão ErrVersionConflict).
+func (r *DepartmentRepository) UpdateDepartment(ctx context.Context, department *models.Department) error {
+	ctx, done := observeQuery(ctx, "departments", "UpdateDepartment")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockDepartmentTx(ctx, tx, department.ID, false)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+		if err := checkVersion(department.Version, before.Version); err != nil {
+			return err
+		}
+
+		query := `UPDATE departments SET name = $1, head_teacher_id = NULLIF($2, ''), version = version + 1 WHERE id = $3`
+		if _, err := tx.ExecContext(ctx, query, department.Name, department.HeadTeacherID, department.ID); err != nil {
+			logging.FromContext(ctx).Error("UpdateDepartment: erro ao atualizar departamento", "department_id", department.ID, "error", err)
+			return err
+		}
+		department.Code = before.Code
+		department.Version = before.Version + 1
+		return recordAudit(ctx, tx, AuditActionUpdate, AuditEntityDepartments, department.ID, before, department)
+	})
+}
+
+// DeleteDepartment exclui logicamente um departamento.
+// expectedVersion diferente de zero precisa ser a versão atual (senão ErrVersionConflict).
+func (r *DepartmentRepository) DeleteDepartment(ctx context.Context, id string, expectedVersion int) error {
+	ctx, done := observeQuery(ctx, "departments", "DeleteDepartment")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockDepartmentTx(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+		if err := checkVersion(expectedVersion, before.Version); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE departments SET deleted_at = NOW(), version = version + 1 WHERE id = $1`, id); err != nil {
+			logging.FromContext(ctx).Error("DeleteDepartment: erro ao excluir departamento", "department_id", id, "error", err)
+			return err
+		}
+		return recordAudit(ctx, tx, AuditActionDelete, AuditEntityDepartments, id, before, nil)
+	})
+}
+
+// RestoreDepartment restaura um departamento excluído logicamente.
+func (r *DepartmentRepository) RestoreDepartment(ctx context.Context, id string) error {
+	ctx, done := observeQuery(ctx, "departments", "RestoreDepartment")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockDepartmentTx(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE departments SET deleted_at = NULL, version = version + 1 WHERE id = $1`, id); err != nil {
+			logging.FromContext(ctx).Error("RestoreDepartment: erro ao restaurar departamento", "department_id", id, "error", err)
+			return err
+		}
+		before.Version++
+		return recordAudit(ctx, tx, AuditActionRestore, AuditEntityDepartments, id, nil, before)
+	})
+}
+
+// CountTeachersInDepartment conta os professores ativos do departamento.
+func (r *DepartmentRepository) CountTeachersInDepartment(ctx context.Context, id string) (int, error) {
+	ctx, done := observeQuery(ctx, "departments", "CountTeachersInDepartment")
+	defer done()
+	var count int
+	err := r.conn().QueryRowContext(ctx, `SELECT COUNT(*) FROM teachers WHERE department_id = $1 AND deleted_at IS NULL`, id).Scan(&count)
+	return count, err
+}
+
+// lockDepartmentTx lê e trava (até o fim da transação) o departamento ativo ou excluído, conforme deleted.
+// Retorna nil se não existir.
+func lockDepartmentTx(ctx context.Context, tx *sql.Tx, id string, deleted bool) (*models.Department, error) {
+	query := selectDepartmentSQL + ` WHERE id = $1 AND (deleted_at IS NOT NULL) = $2 FOR UPDATE`
+	department, err := scanDepartment(tx.QueryRowContext(ctx, query, id, deleted))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return department, nil
+}
@@ -0,0 +1,36 @@
+// repositories/health_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+)
+
+// HealthRepository consulta o estado do banco para as verificações de prontidão.
+type HealthRepository struct {
+	db *sql.DB
+}
+
+// NewHealthRepository cria uma nova instância de HealthRepository.
+func NewHealthRepository(db *sql.DB) *HealthRepository {
+	return &HealthRepository{db: db}
+}
+
+// Ping verifica se o banco responde.
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	ctx, done := observeQuery(ctx, "health", "Ping")
+	defer done()
+	return r.db.PingContext(ctx)
+}
+
+// SchemaVersion devolve a versão do esquema gravada pelas migrações (0 se nenhuma foi gravada).
+func (r *HealthRepository) SchemaVersion(ctx context.Context) (int, error) {
+	ctx, done := observeQuery(ctx, "health", "SchemaVersion")
+	defer done()
+	var version int
+	err := r.db.QueryRowContext(ctx, `SELECT version FROM schema_info`).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return version, err
+}
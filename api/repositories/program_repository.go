@@ -0,0 +1,252 @@
+// repositories/program_repository.go
+package repositories
+
+import (
+	"college_api/logging"
+	"college_api/models"
+	"context"
+	"database/sql"
+)
+
+// ProgramRepository define as operações de CRUD para cursos e suas grades.
+type ProgramRepository struct {
+	db *sql.DB
+	tx *sql.Tx // Transação externa, quando o repositório foi obtido via WithTx
+}
+
+// NewProgramRepository cria uma nova instância de ProgramRepository.
+func NewProgramRepository(db *sql.DB) *ProgramRepository {
+	return &ProgramRepository{db: db}
+}
+
+// WithTx devolve uma cópia do repositório que executa todas as operações dentro de tx.
+func (r *ProgramRepository) WithTx(tx *sql.Tx) *ProgramRepository {
+	return &ProgramRepository{db: r.db, tx: tx}
+}
+
+// conn devolve a conexão usada pelas leituras: a transação vinculada ou o pool.
+func (r *ProgramRepository) conn() dbConn {
+	return connFor(r.db, r.tx)
+}
+
+// CreateProgram insere um curso com sua grade e créditos mínimos por ano.
+func (r *ProgramRepository) CreateProgram(ctx context.Context, program *models.Program) error {
+	ctx, done := observeQuery(ctx, "programs", "CreateProgram")
+	defer done()
+	err := withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO programs (id, name) VALUES ($1, $2)`, program.ID, program.Name); err != nil {
+			logging.FromContext(ctx).Error("CreateProgram: erro ao inserir curso", "program_id", program.ID, "error", err)
+			return err
+		}
+		if err := replaceProgramChildrenTx(ctx, tx, program); err != nil {
+			return err
+		}
+		program.Version = 1 // Valor padrão da coluna
+		return recordAudit(ctx, tx, AuditActionCreate, AuditEntityPrograms, program.ID, nil, program)
+	})
+	if err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Info("CreateProgram: curso criado", "program_id", program.ID)
+	return nil
+}
+
+// GetProgramByID busca um curso com sua grade. Retorna nil se não existir.
+func (r *ProgramRepository) GetProgramByID(ctx context.Context, id string) (*models.Program, error) {
+	ctx, done := observeQuery(ctx, "programs", "GetProgramByID")
+	defer done()
+	program := &models.Program{}
+	err := r.conn().QueryRowContext(ctx, `SELECT id, name, version FROM programs WHERE id = $1`, id).Scan(&program.ID, &program.Name, &program.Version)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("GetProgramByID: erro ao buscar curso", "program_id", id, "error", err)
+		return nil, err
+	}
+	if err := r.loadProgramChildren(ctx, program); err != nil {
+		return nil, err
+	}
+	return program, nil
+}
+
+// GetAllPrograms busca todos os cursos, ordenados pelo código, com suas grades.
+func (r *ProgramRepository) GetAllPrograms(ctx context.Context) ([]models.Program, error) {
+	ctx, done := observeQuery(ctx, "programs", "GetAllPrograms")
+	defer done()
+	rows, err := r.conn().QueryContext(ctx, `SELECT id, name, version FROM programs ORDER BY id`)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetAllPrograms: erro ao consultar cursos", "error", err)
+		return nil, err
+	}
+	programs := []models.Program{}
+	for rows.Next() {
+		var program models.Program
+		if err := rows.Scan(&program.ID, &program.Name, &program.Version); err != nil {
+			rows.Close()
+			logging.FromContext(ctx).Error("GetAllPrograms: erro ao escanear curso", "error", err)
+			return nil, err
+		}
+		programs = append(programs, program)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range programs {
+		if err := r.loadProgramChildren(ctx, &programs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return programs, nil
+}
+
+// loadProgramChildren preenche a grade (com nome, ano e créditos do catálogo) e os mínimos por ano.
+func (r *ProgramRepository) loadProgramChildren(ctx context.Context, program *models.Program) error {
+	rows, err := r.conn().QueryContext(ctx, `
+		SELECT ps.subject_id, ps.kind, s.name, s.year, s.credits
+		FROM program_subjects ps
+		JOIN subjects s ON s.id = ps.subject_id
+		WHERE ps.program_id = $1
+		ORDER BY s.year, ps.subject_id`, program.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("loadProgramChildren: erro ao consultar grade do curso", "program_id", program.ID, "error", err)
+		return err
+	}
+	program.Subjects = []models.ProgramSubject{}
+	for rows.Next() {
+		var subject models.ProgramSubject
+		if err := rows.Scan(&subject.SubjectID, &subject.Kind, &subject.Name, &subject.Year, &subject.Credits); err != nil {
+			rows.Close()
+			return err
+		}
+		program.Subjects = append(program.Subjects, subject)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = r.conn().QueryContext(ctx, `SELECT year, min_credits FROM program_year_requirements WHERE program_id = $1 ORDER BY year`, program.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("loadProgramChildren: erro ao consultar créditos mínimos do curso", "program_id", program.ID, "error", err)
+		return err
+	}
+	defer rows.Close()
+	program.YearRequirements = []models.ProgramYearRequirement{}
+	for rows.Next() {
+		var requirement models.ProgramYearRequirement
+		if err := rows.Scan(&requirement.Year, &requirement.MinCredits); err != nil {
+			return err
+		}
+		program.YearRequirements = append(program.YearRequirements, requirement)
+	}
+	return rows.Err()
+}
+
+// UpdateProgram atualiza o nome do curso e substitui sua grade e seus mínimos por ano.
+// Se program.Version for diferente de zero, ela precisa ser a versão atual (senão ErrVersionConflict).
+func (r *ProgramRepository) UpdateProgram(ctx context.Context, program *models.Program) error {
+	ctx, done := observeQuery(ctx, "programs", "UpdateProgram")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := r.WithTx(tx).lockProgram(ctx, program.ID)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+		if err := checkVersion(program.Version, before.Version); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE programs SET name = $1, version = version + 1 WHERE id = $2`, program.Name, program.ID); err != nil {
+			logging.FromContext(ctx).Error("UpdateProgram: erro ao atualizar curso", "program_id", program.ID, "error", err)
+			return err
+		}
+		if err := replaceProgramChildrenTx(ctx, tx, program); err != nil {
+			return err
+		}
+		program.Version = before.Version + 1
+		return recordAudit(ctx, tx, AuditActionUpdate, AuditEntityPrograms, program.ID, before, program)
+	})
+}
+
+// DeleteProgram remove definitivamente um curso e sua grade. Cursos com alunos vinculados
+// (inclusive excluídos logicamente) não podem ser removidos: a chave estrangeira recusa.
+// expectedVersion diferente de zero precisa ser a versão atual (senão ErrVersionConflict).
+func (r *ProgramRepository) DeleteProgram(ctx context.Context, id string, expectedVersion int) error {
+	ctx, done := observeQuery(ctx, "programs", "DeleteProgram")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := r.WithTx(tx).lockProgram(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+		if err := checkVersion(expectedVersion, before.Version); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM programs WHERE id = $1`, id); err != nil {
+			logging.FromContext(ctx).Error("DeleteProgram: erro ao remover curso", "program_id", id, "error", err)
+			return err
+		}
+		return recordAudit(ctx, tx, AuditActionDelete, AuditEntityPrograms, id, before, nil)
+	})
+}
+
+// CountStudentsInProgram conta os alunos vinculados ao curso, inclusive os excluídos logicamente.
+func (r *ProgramRepository) CountStudentsInProgram(ctx context.Context, id string) (int, error) {
+	ctx, done := observeQuery(ctx, "programs", "CountStudentsInProgram")
+	defer done()
+	var count int
+	err := r.conn().QueryRowContext(ctx, `SELECT COUNT(*) FROM students WHERE program_id = $1`, id).Scan(&count)
+	return count, err
+}
+
+// lockProgram lê (e trava até o fim da transação) o curso com sua grade, para compor o "antes" da auditoria.
+// O repositório precisa estar vinculado a uma transação. Retorna nil se não existir.
+func (r *ProgramRepository) lockProgram(ctx context.Context, id string) (*models.Program, error) {
+	program := &models.Program{}
+	err := r.tx.QueryRowContext(ctx, `SELECT id, name, version FROM programs WHERE id = $1 FOR UPDATE`, id).Scan(&program.ID, &program.Name, &program.Version)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadProgramChildren(ctx, program); err != nil {
+		return nil, err
+	}
+	return program, nil
+}
+
+// replaceProgramChildrenTx regrava a grade e os mínimos por ano do curso.
+func replaceProgramChildrenTx(ctx context.Context, tx *sql.Tx, program *models.Program) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM program_subjects WHERE program_id = $1`, program.ID); err != nil {
+		return err
+	}
+	for _, subject := range program.Subjects {
+		query := `INSERT INTO program_subjects (program_id, subject_id, kind) VALUES ($1, $2, $3)`
+		if _, err := tx.ExecContext(ctx, query, program.ID, subject.SubjectID, subject.Kind); err != nil {
+			logging.FromContext(ctx).Error("replaceProgramChildrenTx: erro ao gravar matéria do curso", "program_id", program.ID, "subject_id", subject.SubjectID, "error", err)
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM program_year_requirements WHERE program_id = $1`, program.ID); err != nil {
+		return err
+	}
+	for _, requirement := range program.YearRequirements {
+		query := `INSERT INTO program_year_requirements (program_id, year, min_credits) VALUES ($1, $2, $3)`
+		if _, err := tx.ExecContext(ctx, query, program.ID, requirement.Year, requirement.MinCredits); err != nil {
+			logging.FromContext(ctx).Error("replaceProgramChildrenTx: erro ao gravar créditos mínimos do curso", "program_id", program.ID, "year", requirement.Year, "error", err)
+			return err
+		}
+	}
+	return nil
+}
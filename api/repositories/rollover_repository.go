@@ -0,0 +1,230 @@
+// repositories/rollover_repository.go
+package repositories
+
+import (
+	"college_api/logging"
+	"college_api/models"
+	"college_api/requestctx"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// RolloverRepository guarda as execuções da virada de ano letivo e calcula os créditos dos alunos.
+type RolloverRepository struct {
+	db *sql.DB
+	tx *sql.Tx // Transação externa, quando o repositório foi obtido via WithTx
+}
+
+// NewRolloverRepository cria uma nova instância de RolloverRepository.
+func NewRolloverRepository(db *sql.DB) *RolloverRepository {
+	return &RolloverRepository{db: db}
+}
+
+// WithTx devolve uma cópia do repositório que executa todas as operações dentro de tx.
+func (r *RolloverRepository) WithTx(tx *sql.Tx) *RolloverRepository {
+	return &RolloverRepository{db: r.db, tx: tx}
+}
+
+// BeginTx abre uma transação no pool (ver WithTx).
+func (r *RolloverRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return r.db.BeginTx(ctx, nil)
+}
+
+// conn devolve a conexão usada pelas consultas: a transação vinculada ou o pool.
+func (r *RolloverRepository) conn() dbConn {
+	return connFor(r.db, r.tx)
+}
+
+// ListCandidates devolve os alunos ativos (não excluídos e com situação active) com os créditos do
+// seu ano atual: os das matérias associadas a eles e o total do catálogo para aquele ano. Dentro de
+// uma transação, os alunos ficam bloqueados (FOR UPDATE) até o fim da virada.
+func (r *RolloverRepository) ListCandidates(ctx context.Context) ([]models.RolloverCandidate, error) {
+	ctx, done := observeQuery(ctx, "rollover", "ListCandidates")
+	defer done()
+	query := `
+		WITH year_totals AS (
+			SELECT year, SUM(credits) AS total FROM subjects WHERE deleted_at IS NULL GROUP BY year
+		)
+		SELECT s.id, s.enrollment, s.name, s.current_year, s.graduating, s.version,
+			COALESCE((
+				SELECT SUM(sub.credits)
+				FROM student_subjects ss
+				JOIN subjects sub ON sub.id = ss.subject_id AND sub.deleted_at IS NULL
+				WHERE ss.student_id = s.id AND sub.year = s.current_year
+			), 0),
+			COALESCE(yt.total, 0)
+		FROM students s
+		LEFT JOIN year_totals yt ON yt.year = s.current_year
+		WHERE s.deleted_at IS NULL AND s.status = 'active'
+		ORDER BY s.enrollment
+		FOR UPDATE OF s`
+	rows, err := r.conn().QueryContext(ctx, query)
+	if err != nil {
+		logging.FromContext(ctx).Error("ListCandidates: erro ao consultar alunos", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []models.RolloverCandidate
+	for rows.Next() {
+		var c models.RolloverCandidate
+		if err := rows.Scan(&c.StudentID, &c.Enrollment, &c.Name, &c.CurrentYear, &c.Graduating, &c.Version, &c.EarnedCredits, &c.TotalCredits); err != nil {
+			logging.FromContext(ctx).Error("ListCandidates: erro ao escanear aluno", "error", err)
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// CreateRun registra uma execução da virada, com o ator do contexto, e devolve seu ID e horário.
+func (r *RolloverRepository) CreateRun(ctx context.Context, criteria models.RolloverCriteria, undoWindow time.Duration) (int64, time.Time, error) {
+	ctx, done := observeQuery(ctx, "rollover", "CreateRun")
+	defer done()
+	raw, err := json.Marshal(criteria)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	var id int64
+	var executedAt time.Time
+	query := `
+		INSERT INTO rollovers (actor, criteria, undo_deadline)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3))
+		RETURNING id, executed_at`
+	if err := r.conn().QueryRowContext(ctx, query, requestctx.Actor(ctx), string(raw), undoWindow.Seconds()).Scan(&id, &executedAt); err != nil {
+		logging.FromContext(ctx).Error("CreateRun: erro ao registrar virada de ano letivo", "error", err)
+		return 0, time.Time{}, err
+	}
+	return id, executedAt, nil
+}
+
+// AddChange guarda o estado anterior e posterior de um aluno alterado pela virada.
+func (r *RolloverRepository) AddChange(ctx context.Context, runID int64, change models.RolloverChange) error {
+	ctx, done := observeQuery(ctx, "rollover", "AddChange")
+	defer done()
+	query := `
+		INSERT INTO rollover_changes (rollover_id, student_id, from_year, to_year, from_graduating, to_graduating, version_after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.conn().ExecContext(ctx, query, runID, change.StudentID, change.FromYear, change.ToYear, change.FromGraduating, change.ToGraduating, change.VersionAfter)
+	if err != nil {
+		logging.FromContext(ctx).Error("AddChange: erro ao registrar alteração do aluno", "run_id", runID, "student_id", change.StudentID, "error", err)
+	}
+	return err
+}
+
+// ListRuns devolve as execuções da virada, da mais recente à mais antiga.
+func (r *RolloverRepository) ListRuns(ctx context.Context, limit int) ([]models.RolloverRun, error) {
+	ctx, done := observeQuery(ctx, "rollover", "ListRuns")
+	defer done()
+	rows, err := r.conn().QueryContext(ctx, selectRolloverRunSQL+` ORDER BY r.id DESC LIMIT $1`, limit)
+	if err != nil {
+		logging.FromContext(ctx).Error("ListRuns: erro ao consultar viradas de ano letivo", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []models.RolloverRun{}
+	for rows.Next() {
+		run, err := scanRolloverRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+// GetRun busca uma execução pelo ID. Retorna nil se não existir.
+func (r *RolloverRepository) GetRun(ctx context.Context, id int64) (*models.RolloverRun, error) {
+	ctx, done := observeQuery(ctx, "rollover", "GetRun")
+	defer done()
+	return r.getRun(ctx, id, "")
+}
+
+// LockRun busca uma execução bloqueando-a até o fim da transação. Retorna nil se não existir.
+func (r *RolloverRepository) LockRun(ctx context.Context, id int64) (*models.RolloverRun, error) {
+	ctx, done := observeQuery(ctx, "rollover", "LockRun")
+	defer done()
+	return r.getRun(ctx, id, " FOR UPDATE OF r")
+}
+
+func (r *RolloverRepository) getRun(ctx context.Context, id int64, lock string) (*models.RolloverRun, error) {
+	run, err := scanRolloverRun(r.conn().QueryRowContext(ctx, selectRolloverRunSQL+` WHERE r.id = $1`+lock, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("getRun: erro ao buscar virada", "run_id", id, "error", err)
+	}
+	return run, err
+}
+
+// HasLaterActiveRun informa se existe uma virada posterior a id que não foi desfeita.
+func (r *RolloverRepository) HasLaterActiveRun(ctx context.Context, id int64) (bool, error) {
+	ctx, done := observeQuery(ctx, "rollover", "HasLaterActiveRun")
+	defer done()
+	var exists bool
+	err := r.conn().QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM rollovers WHERE id > $1 AND undone_at IS NULL)`, id).Scan(&exists)
+	return exists, err
+}
+
+// ListChanges devolve as alterações de alunos de uma execução.
+func (r *RolloverRepository) ListChanges(ctx context.Context, runID int64) ([]models.RolloverChange, error) {
+	ctx, done := observeQuery(ctx, "rollover", "ListChanges")
+	defer done()
+	query := `
+		SELECT student_id, from_year, to_year, from_graduating, to_graduating, version_after
+		FROM rollover_changes WHERE rollover_id = $1 ORDER BY student_id`
+	rows, err := r.conn().QueryContext(ctx, query, runID)
+	if err != nil {
+		logging.FromContext(ctx).Error("ListChanges: erro ao consultar alterações da virada", "run_id", runID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []models.RolloverChange
+	for rows.Next() {
+		var c models.RolloverChange
+		if err := rows.Scan(&c.StudentID, &c.FromYear, &c.ToYear, &c.FromGraduating, &c.ToGraduating, &c.VersionAfter); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// MarkUndone marca a execução como desfeita pelo ator do contexto.
+func (r *RolloverRepository) MarkUndone(ctx context.Context, id int64) error {
+	ctx, done := observeQuery(ctx, "rollover", "MarkUndone")
+	defer done()
+	_, err := r.conn().ExecContext(ctx, `UPDATE rollovers SET undone_at = NOW(), undone_by = $1 WHERE id = $2`, requestctx.Actor(ctx), id)
+	if err != nil {
+		logging.FromContext(ctx).Error("MarkUndone: erro ao marcar virada como desfeita", "run_id", id, "error", err)
+	}
+	return err
+}
+
+// selectRolloverRunSQL é a consulta base das execuções, com a contagem de alunos alterados.
+const selectRolloverRunSQL = `
+	SELECT r.id, r.executed_at, r.actor, r.criteria, r.undo_deadline, r.undone_at, COALESCE(r.undone_by, ''),
+		(SELECT COUNT(*) FROM rollover_changes c WHERE c.rollover_id = r.id)
+	FROM rollovers r`
+
+// scanRolloverRun lê uma execução de *sql.Row ou *sql.Rows.
+func scanRolloverRun(row interface{ Scan(...interface{}) error }) (*models.RolloverRun, error) {
+	var run models.RolloverRun
+	var criteria []byte
+	var undoneAt sql.NullTime
+	if err := row.Scan(&run.ID, &run.ExecutedAt, &run.Actor, &criteria, &run.UndoDeadline, &undoneAt, &run.UndoneBy, &run.Changes); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(criteria, &run.Criteria); err != nil {
+		return nil, err
+	}
+	if undoneAt.Valid {
+		run.UndoneAt = &undoneAt.Time
+	}
+	return &run, nil
+}
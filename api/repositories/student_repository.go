@@ -2,65 +2,115 @@
 package repositories
 
 import (
-	"college_api/config"
+	"college_api/logging"
 	"college_api/models"
+	"college_api/requestctx"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// ErrEnrollmentTaken indica que a matrícula gerada já foi usada por outro aluno criado ao mesmo tempo.
+var ErrEnrollmentTaken = errors.New("matrícula já utilizada por outro aluno")
+
+// ErrSubjectNotFound indica que uma matéria informada na criação do aluno não existe (ou foi excluída).
+var ErrSubjectNotFound = errors.New("matéria não encontrada")
+
+// ErrStudentNotActive indica que a operação exige um aluno ativo (ex: associar matérias).
+var ErrStudentNotActive = errors.New("o aluno não está ativo")
+
 // StudentRepository define as operações de CRUD para alunos.
 type StudentRepository struct {
 	db *sql.DB
+	tx *sql.Tx // Transação externa, quando o repositório foi obtido via WithTx
 }
 
 // NewStudentRepository cria uma nova instância de StudentRepository.
-func NewStudentRepository() *StudentRepository {
-	return &StudentRepository{db: config.DB}
+func NewStudentRepository(db *sql.DB) *StudentRepository {
+	return &StudentRepository{db: db}
+}
+
+// WithTx devolve uma cópia do repositório que executa todas as operações dentro de tx.
+// Quem abriu a transação é responsável pelo commit ou rollback.
+func (r *StudentRepository) WithTx(tx *sql.Tx) *StudentRepository {
+	return &StudentRepository{db: r.db, tx: tx}
 }
 
-// CreateStudent insere um novo aluno no banco de dados.
-func (r *StudentRepository) CreateStudent(student *models.Student) error {
+// BeginTx abre uma transação no pool, para operações que envolvem vários repositórios (ver WithTx).
+func (r *StudentRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return r.db.BeginTx(ctx, nil)
+}
+
+// conn devolve a conexão usada pelas leituras: a transação vinculada ou o pool.
+func (r *StudentRepository) conn() dbConn {
+	return connFor(r.db, r.tx)
+}
+
+// CreateStudent insere um novo aluno no banco de dados, junto com suas matérias e o registro de auditoria.
+// Se alguma matéria não existir, nada é gravado e o erro é ErrSubjectNotFound com o ID da matéria.
+func (r *StudentRepository) CreateStudent(ctx context.Context, student *models.Student) error {
+	ctx, done := observeQuery(ctx, "students", "CreateStudent")
+	defer done()
 	student.ID = uuid.New().String() // Gera um ID único para o aluno
-	query := `INSERT INTO students (id, enrollment, name, current_year, shift) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.db.Exec(query, student.ID, student.Enrollment, student.Name, student.CurrentYear, student.Shift)
-	if err != nil {
-		log.Printf("CreateStudent: Erro ao executar INSERT para aluno %s: %v", student.Name, err) // Log mais específico
-		return err
-	}
+	err := withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		query := `INSERT INTO students (id, enrollment, name, current_year, shift) VALUES ($1, $2, $3, $4, $5)`
+		_, err := tx.ExecContext(ctx, query, student.ID, student.Enrollment, student.Name, student.CurrentYear, student.Shift)
+		if err != nil {
+			if isUniqueViolation(err, "students_enrollment_key") {
+				return ErrEnrollmentTaken
+			}
+			logging.FromContext(ctx).Error("CreateStudent: erro ao inserir aluno", logging.Name(student.Name), "error", err)
+			return err
+		}
+		student.Status = models.StudentActive // Valores padrão das colunas
+		student.Version = 1
+		if err := recordAudit(ctx, tx, AuditActionCreate, AuditEntityStudents, student.ID, nil, student); err != nil {
+			return err
+		}
 
-	// Insere as matérias do aluno na tabela de relacionamento
-	if student.Subjects != nil {
+		// Insere as matérias do aluno na tabela de relacionamento; uma matéria inexistente desfaz a criação
 		for _, subject := range student.Subjects {
-			err := r.AddSubjectToStudent(student.ID, subject.ID)
+			added, err := addSubjectToStudentTx(ctx, tx, student.ID, subject.ID)
 			if err != nil {
-				log.Printf("CreateStudent: Aviso - Erro ao adicionar matéria %s ao aluno %s: %v", subject.ID, student.ID, err)
+				return err
+			}
+			if !added {
+				return fmt.Errorf("%w: %s", ErrSubjectNotFound, subject.ID)
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	log.Printf("CreateStudent: Aluno %s (%s) criado com sucesso. Matrícula: %s", student.Name, student.ID, student.Enrollment) // Log de sucesso
+	logging.FromContext(ctx).Info("CreateStudent: aluno criado", "student_id", student.ID, logging.Name(student.Name), "enrollment", student.Enrollment)
 	return nil
 }
 
 // GetStudentByID busca um aluno pelo ID.
-func (r *StudentRepository) GetStudentByID(id string) (*models.Student, error) {
+func (r *StudentRepository) GetStudentByID(ctx context.Context, id string) (*models.Student, error) {
+	ctx, done := observeQuery(ctx, "students", "GetStudentByID")
+	defer done()
 	student := &models.Student{}
-	query := `SELECT id, enrollment, name, current_year, shift FROM students WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&student.ID, &student.Enrollment, &student.Name, &student.CurrentYear, &student.Shift)
+	query := `SELECT id, enrollment, name, current_year, shift, graduating, status, COALESCE(program_id, ''), version FROM students WHERE id = $1 AND deleted_at IS NULL`
+	err := r.conn().QueryRowContext(ctx, query, id).Scan(&student.ID, &student.Enrollment, &student.Name, &student.CurrentYear, &student.Shift, &student.Graduating, &student.Status, &student.ProgramID, &student.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("GetStudentByID: Aluno com ID %s não encontrado no DB.", id) // Log de não encontrado
-			return nil, nil                                                         // Aluno não encontrado
+			logging.FromContext(ctx).Debug("GetStudentByID: aluno não encontrado", "student_id", id)
+			return nil, nil // Aluno não encontrado
 		}
-		log.Printf("GetStudentByID: Erro ao escanear dados do aluno com ID %s: %v", id, err) // Log de erro no Scan
+		logging.FromContext(ctx).Error("GetStudentByID: erro ao buscar aluno", "student_id", id, "error", err)
 		return nil, err
 	}
 
-	subjects, err := r.GetSubjectsByStudentID(student.ID)
+	subjects, err := r.GetSubjectsByStudentID(ctx, student.ID)
 	if err != nil {
-		log.Printf("GetStudentByID: Erro ao buscar matérias para o aluno %s (ID: %s): %v", student.Name, student.ID, err)
+		logging.FromContext(ctx).Error("GetStudentByID: erro ao buscar matérias do aluno", "student_id", student.ID, "error", err)
 		return nil, err
 	}
 	if subjects == nil {
@@ -68,15 +118,18 @@ func (r *StudentRepository) GetStudentByID(id string) (*models.Student, error) {
 	} else {
 		student.Subjects = subjects
 	}
-	log.Printf("GetStudentByID: Aluno '%s' (ID: %s) encontrado com sucesso, %d matérias.", student.Name, student.ID, len(student.Subjects)) // Log de sucesso
+	logging.FromContext(ctx).Debug("GetStudentByID: aluno encontrado", "student_id", student.ID, "subjects", len(student.Subjects))
 	return student, nil
 }
 
-// GetAllStudents busca todos os alunos.
-func (r *StudentRepository) GetAllStudents() ([]models.Student, error) {
-	rows, err := r.db.Query(`SELECT id, enrollment, name, current_year, shift FROM students`)
+// GetAllStudents busca todos os alunos que atendem ao filtro.
+func (r *StudentRepository) GetAllStudents(ctx context.Context, filter models.StudentFilter) ([]models.Student, error) {
+	ctx, done := observeQuery(ctx, "students", "GetAllStudents")
+	defer done()
+	where := studentFilterWhere(filter)
+	rows, err := r.conn().QueryContext(ctx, `SELECT s.id, s.enrollment, s.name, s.current_year, s.shift, s.graduating, s.status, COALESCE(s.program_id, ''), s.version FROM students s WHERE `+where.String(), where.args...)
 	if err != nil {
-		log.Printf("GetAllStudents: Erro ao executar SELECT ALL FROM students: %v", err) // Log de erro na query
+		logging.FromContext(ctx).Error("GetAllStudents: erro ao consultar alunos", "error", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -85,14 +138,14 @@ func (r *StudentRepository) GetAllStudents() ([]models.Student, error) {
 	for rows.Next() {
 		student := models.Student{}
 		// Certifique-se de que os campos do Scan correspondem exatamente à SELECT
-		if err := rows.Scan(&student.ID, &student.Enrollment, &student.Name, &student.CurrentYear, &student.Shift); err != nil {
-			log.Printf("GetAllStudents: Erro ao escanear linha de aluno do DB: %v", err) // Log de erro no Scan
+		if err := rows.Scan(&student.ID, &student.Enrollment, &student.Name, &student.CurrentYear, &student.Shift, &student.Graduating, &student.Status, &student.ProgramID, &student.Version); err != nil {
+			logging.FromContext(ctx).Error("GetAllStudents: erro ao escanear aluno", "error", err)
 			return nil, err
 		}
 		// Buscar matérias para cada aluno
-		subjects, err := r.GetSubjectsByStudentID(student.ID)
+		subjects, err := r.GetSubjectsByStudentID(ctx, student.ID)
 		if err != nil {
-			log.Printf("GetAllStudents: Erro ao buscar matérias para aluno %s (ID: %s) durante iteração: %v", student.Name, student.ID, err)
+			logging.FromContext(ctx).Error("GetAllStudents: erro ao buscar matérias do aluno", "student_id", student.ID, "error", err)
 			return nil, err
 		}
 		if subjects == nil {
@@ -102,75 +155,441 @@ func (r *StudentRepository) GetAllStudents() ([]models.Student, error) {
 		}
 		students = append(students, student)
 	}
-	log.Printf("GetAllStudents: %d alunos encontrados e processados.", len(students)) // Log de sucesso com contagem
+	logging.FromContext(ctx).Debug("GetAllStudents: alunos encontrados", "count", len(students))
 	return students, nil
 }
 
-// UpdateStudent atualiza um aluno existente.
-func (r *StudentRepository) UpdateStudent(student *models.Student) error {
-	query := `UPDATE students SET enrollment = $1, name = $2, current_year = $3, shift = $4 WHERE id = $5`
-	result, err := r.db.Exec(query, student.Enrollment, student.Name, student.CurrentYear, student.Shift, student.ID)
+// StreamStudents percorre os alunos que atendem ao filtro, ordenados pela matrícula, chamando fn
+// para cada um sem carregar a lista inteira em memória. As matérias vêm na mesma consulta.
+// Se fn retornar erro, a iteração é interrompida e o erro é devolvido.
+func (r *StudentRepository) StreamStudents(ctx context.Context, filter models.StudentFilter, fn func(models.Student) error) error {
+	ctx, done := observeStream(ctx, "students", "StreamStudents")
+	defer done()
+	where := studentFilterWhere(filter)
+	query := `
+		SELECT s.id, s.enrollment, s.name, s.current_year, s.shift, s.graduating, s.status, COALESCE(s.program_id, ''), s.version,
+			array_agg(sub.id ORDER BY sub.id) FILTER (WHERE sub.id IS NOT NULL),
+			array_agg(sub.name ORDER BY sub.id) FILTER (WHERE sub.id IS NOT NULL)
+		FROM students s
+		LEFT JOIN student_subjects ss ON ss.student_id = s.id
+		LEFT JOIN subjects sub ON sub.id = ss.subject_id AND sub.deleted_at IS NULL
+		WHERE ` + where.String() + `
+		GROUP BY s.id
+		ORDER BY s.enrollment`
+	rows, err := r.conn().QueryContext(ctx, query, where.args...)
 	if err != nil {
-		log.Printf("UpdateStudent: Erro ao executar UPDATE para aluno %s (ID: %s): %v", student.Name, student.ID, err)
+		logging.FromContext(ctx).Error("StreamStudents: erro ao consultar alunos", "error", err)
 		return err
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		log.Printf("UpdateStudent: Nenhum aluno encontrado para atualizar com ID %s.", student.ID)
-		return sql.ErrNoRows // Nenhum aluno encontrado para atualizar
+	defer rows.Close()
+
+	for rows.Next() {
+		student := models.Student{Subjects: []models.Subject{}}
+		var subjectIDs, subjectNames pq.StringArray
+		if err := rows.Scan(&student.ID, &student.Enrollment, &student.Name, &student.CurrentYear, &student.Shift, &student.Graduating, &student.Status, &student.ProgramID, &student.Version, &subjectIDs, &subjectNames); err != nil {
+			logging.FromContext(ctx).Error("StreamStudents: erro ao escanear aluno", "error", err)
+			return err
+		}
+		for i, id := range subjectIDs {
+			student.Subjects = append(student.Subjects, models.Subject{ID: id, Name: subjectNames[i]})
+		}
+		if err := fn(student); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// studentFilterWhere traduz o filtro de alunos em condições sobre a tabela students (alias s).
+func studentFilterWhere(filter models.StudentFilter) *whereClause {
+	where := &whereClause{conds: []string{"s.deleted_at IS NULL"}}
+	if filter.Name != "" {
+		where.add("s.name ILIKE ?", likePattern(filter.Name))
+	}
+	if filter.Shift != "" {
+		where.add("s.shift = ?", filter.Shift)
+	}
+	if filter.CurrentYear != 0 {
+		where.add("s.current_year = ?", filter.CurrentYear)
+	}
+	if filter.Status != "" {
+		where.add("s.status = ?", filter.Status)
+	}
+	if filter.SubjectID != "" {
+		where.add("EXISTS (SELECT 1 FROM student_subjects f WHERE f.student_id = s.id AND f.subject_id = ?)", filter.SubjectID)
+	}
+	return where
+}
+
+// UpdateStudent atualiza um aluno existente.
+// Se student.Version for diferente de zero, ela precisa ser a versão atual (senão ErrVersionConflict).
+func (r *StudentRepository) UpdateStudent(ctx context.Context, student *models.Student) error {
+	ctx, done := observeQuery(ctx, "students", "UpdateStudent")
+	defer done()
+	err := withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockStudentTx(ctx, tx, student.ID, false)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			logging.FromContext(ctx).Debug("UpdateStudent: aluno não encontrado", "student_id", student.ID)
+			return sql.ErrNoRows // Nenhum aluno encontrado para atualizar
+		}
+		if err := checkVersion(student.Version, before.Version); err != nil {
+			return err
+		}
+
+		query := `UPDATE students SET enrollment = $1, name = $2, current_year = $3, shift = $4, graduating = $5, version = version + 1 WHERE id = $6`
+		if _, err := tx.ExecContext(ctx, query, student.Enrollment, student.Name, student.CurrentYear, student.Shift, student.Graduating, student.ID); err != nil {
+			logging.FromContext(ctx).Error("UpdateStudent: erro ao atualizar aluno", "student_id", student.ID, "error", err)
+			return err
+		}
+		student.Version = before.Version + 1
+		return recordAudit(ctx, tx, AuditActionUpdate, AuditEntityStudents, student.ID, before, student)
+	})
+	if err != nil {
+		return err
 	}
-	log.Printf("UpdateStudent: Aluno %s (ID: %s) atualizado com sucesso.", student.Name, student.ID)
+	logging.FromContext(ctx).Info("UpdateStudent: aluno atualizado", "student_id", student.ID, "version", student.Version)
 	return nil
 }
 
-// DeleteStudent deleta um aluno pelo ID.
-func (r *StudentRepository) DeleteStudent(id string) error {
-	query := `DELETE FROM students WHERE id = $1`
-	result, err := r.db.Exec(query, id)
+// DeleteStudent exclui logicamente um aluno pelo ID (preenche deleted_at).
+// As associações com matérias são mantidas para que o histórico acadêmico sobreviva a uma restauração.
+// expectedVersion diferente de zero precisa ser a versão atual (senão ErrVersionConflict).
+func (r *StudentRepository) DeleteStudent(ctx context.Context, id string, expectedVersion int) error {
+	ctx, done := observeQuery(ctx, "students", "DeleteStudent")
+	defer done()
+	err := withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockStudentTx(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			logging.FromContext(ctx).Debug("DeleteStudent: aluno não encontrado", "student_id", id)
+			return sql.ErrNoRows // Nenhum aluno encontrado para deletar
+		}
+		if err := checkVersion(expectedVersion, before.Version); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE students SET deleted_at = NOW(), version = version + 1 WHERE id = $1`, id); err != nil {
+			logging.FromContext(ctx).Error("DeleteStudent: erro ao excluir aluno", "student_id", id, "error", err)
+			return err
+		}
+		return recordAudit(ctx, tx, AuditActionDelete, AuditEntityStudents, id, before, nil)
+	})
 	if err != nil {
-		log.Printf("DeleteStudent: Erro ao executar DELETE para aluno ID %s: %v", id, err)
 		return err
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		log.Printf("DeleteStudent: Nenhum aluno encontrado para deletar com ID %s.", id)
-		return sql.ErrNoRows // Nenhum aluno encontrado para deletar
+	logging.FromContext(ctx).Info("DeleteStudent: aluno excluído", "student_id", id)
+	return nil
+}
+
+// RestoreStudent desfaz a exclusão lógica de um aluno. Retorna sql.ErrNoRows se não houver aluno excluído com o ID.
+func (r *StudentRepository) RestoreStudent(ctx context.Context, id string) error {
+	ctx, done := observeQuery(ctx, "students", "RestoreStudent")
+	defer done()
+	err := withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockStudentTx(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE students SET deleted_at = NULL, version = version + 1 WHERE id = $1`, id); err != nil {
+			logging.FromContext(ctx).Error("RestoreStudent: erro ao restaurar aluno", "student_id", id, "error", err)
+			return err
+		}
+		before.Version++
+		return recordAudit(ctx, tx, AuditActionRestore, AuditEntityStudents, id, nil, before)
+	})
+	if err != nil {
+		return err
 	}
-	log.Printf("DeleteStudent: Aluno com ID %s deletado com sucesso.", id)
+	logging.FromContext(ctx).Info("RestoreStudent: aluno restaurado", "student_id", id)
 	return nil
 }
 
-// AddSubjectToStudent associa uma matéria a um aluno.
-func (r *StudentRepository) AddSubjectToStudent(studentID, subjectID string) error {
-	query := `INSERT INTO student_subjects (student_id, subject_id) VALUES ($1, $2) ON CONFLICT (student_id, subject_id) DO NOTHING`
-	_, err := r.db.Exec(query, studentID, subjectID)
+// PurgeDeletedStudents remove definitivamente os alunos excluídos antes de cutoff.
+// As associações desses alunos são apagadas em cascata. Retorna os IDs removidos.
+func (r *StudentRepository) PurgeDeletedStudents(ctx context.Context, cutoff time.Time) ([]string, error) {
+	ctx, done := observeQuery(ctx, "students", "PurgeDeletedStudents")
+	defer done()
+	purged := []string{}
+	err := withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		query := `SELECT id, enrollment, name, current_year, shift, graduating, status, COALESCE(program_id, ''), version FROM students WHERE deleted_at < $1 FOR UPDATE`
+		rows, err := tx.QueryContext(ctx, query, cutoff)
+		if err != nil {
+			return err
+		}
+		var students []models.Student
+		for rows.Next() {
+			student := models.Student{}
+			if err := rows.Scan(&student.ID, &student.Enrollment, &student.Name, &student.CurrentYear, &student.Shift, &student.Graduating, &student.Status, &student.ProgramID, &student.Version); err != nil {
+				rows.Close()
+				return err
+			}
+			students = append(students, student)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for i := range students {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM students WHERE id = $1`, students[i].ID); err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, tx, AuditActionPurge, AuditEntityStudents, students[i].ID, &students[i], nil); err != nil {
+				return err
+			}
+			purged = append(purged, students[i].ID)
+		}
+		return nil
+	})
 	if err != nil {
-		log.Printf("AddSubjectToStudent: Erro ao executar INSERT para associação aluno %s - matéria %s: %v", studentID, subjectID, err)
+		logging.FromContext(ctx).Error("PurgeDeletedStudents: erro ao expurgar alunos excluídos", "cutoff", cutoff.Format(time.RFC3339), "error", err)
+		return nil, err
+	}
+	logging.FromContext(ctx).Info("PurgeDeletedStudents: alunos expurgados", "count", len(purged))
+	return purged, nil
+}
+
+// AddSubjectToStudent associa uma matéria a um aluno ativo. O aluno é lido e travado na mesma transação
+// da inserção, para que uma mudança de situação simultânea não deixe um aluno inativo com matérias novas.
+// Retorna sql.ErrNoRows se o aluno não existir, ErrStudentNotActive (com a situação) se ele não estiver
+// ativo e ErrSubjectNotFound se a matéria não existir.
+func (r *StudentRepository) AddSubjectToStudent(ctx context.Context, studentID, subjectID string) error {
+	ctx, done := observeQuery(ctx, "students", "AddSubjectToStudent")
+	defer done()
+	err := withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		student, err := lockStudentTx(ctx, tx, studentID, false)
+		if err != nil {
+			return err
+		}
+		if student == nil {
+			return sql.ErrNoRows
+		}
+		if student.Status != models.StudentActive {
+			return fmt.Errorf("%w: situação %s", ErrStudentNotActive, student.Status)
+		}
+		added, err := addSubjectToStudentTx(ctx, tx, studentID, subjectID)
+		if err == nil && !added {
+			return fmt.Errorf("%w: %s", ErrSubjectNotFound, subjectID)
+		}
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrStudentNotActive) || errors.Is(err, ErrSubjectNotFound) {
 		return err
 	}
-	log.Printf("AddSubjectToStudent: Associação aluno %s - matéria %s criada/existente.", studentID, subjectID)
+	if err != nil {
+		logging.FromContext(ctx).Error("AddSubjectToStudent: erro ao associar matéria ao aluno", "student_id", studentID, "subject_id", subjectID, "error", err)
+		return err
+	}
+	logging.FromContext(ctx).Info("AddSubjectToStudent: associação criada ou já existente", "student_id", studentID, "subject_id", subjectID)
 	return nil
 }
 
+// addSubjectToStudentTx insere a associação (se a matéria existir e não estiver excluída) e registra a auditoria quando algo muda.
+// Retorna false se a matéria não existe. Associações já existentes não geram novo registro.
+// Como as matérias fazem parte da representação do aluno, a versão do aluno é incrementada.
+func addSubjectToStudentTx(ctx context.Context, tx *sql.Tx, studentID, subjectID string) (bool, error) {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM subjects WHERE id = $1 AND deleted_at IS NULL)`, subjectID).Scan(&exists); err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, nil
+	}
+
+	query := `INSERT INTO student_subjects (student_id, subject_id) VALUES ($1, $2) ON CONFLICT (student_id, subject_id) DO NOTHING`
+	result, err := tx.ExecContext(ctx, query, studentID, subjectID)
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return true, nil // Associação já existia
+	}
+	if err := bumpStudentVersionTx(ctx, tx, studentID); err != nil {
+		return true, err
+	}
+	association := map[string]string{"student_id": studentID, "subject_id": subjectID}
+	return true, recordAudit(ctx, tx, AuditActionAssociate, AuditEntityStudentSubjects, studentID, nil, association)
+}
+
 // RemoveSubjectFromStudent desassocia uma matéria de um aluno.
-func (r *StudentRepository) RemoveSubjectFromStudent(studentID, subjectID string) error {
-	query := `DELETE FROM student_subjects WHERE student_id = $1 AND subject_id = $2`
-	result, err := r.db.Exec(query, studentID, subjectID)
+func (r *StudentRepository) RemoveSubjectFromStudent(ctx context.Context, studentID, subjectID string) error {
+	ctx, done := observeQuery(ctx, "students", "RemoveSubjectFromStudent")
+	defer done()
+	err := withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		query := `DELETE FROM student_subjects WHERE student_id = $1 AND subject_id = $2`
+		result, err := tx.ExecContext(ctx, query, studentID, subjectID)
+		if err != nil {
+			logging.FromContext(ctx).Error("RemoveSubjectFromStudent: erro ao remover associação", "student_id", studentID, "subject_id", subjectID, "error", err)
+			return err
+		}
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			logging.FromContext(ctx).Debug("RemoveSubjectFromStudent: associação não encontrada", "student_id", studentID, "subject_id", subjectID)
+			return sql.ErrNoRows // Associação não encontrada para deletar
+		}
+		if err := bumpStudentVersionTx(ctx, tx, studentID); err != nil {
+			return err
+		}
+		association := map[string]string{"student_id": studentID, "subject_id": subjectID}
+		return recordAudit(ctx, tx, AuditActionDissociate, AuditEntityStudentSubjects, studentID, association, nil)
+	})
 	if err != nil {
-		log.Printf("RemoveSubjectFromStudent: Erro ao executar DELETE para associação aluno %s - matéria %s: %v", studentID, subjectID, err)
 		return err
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		log.Printf("RemoveSubjectFromStudent: Associação aluno %s - matéria %s não encontrada para deletar.", studentID, subjectID)
-		return sql.ErrNoRows // Associação não encontrada para deletar
-	}
-	log.Printf("RemoveSubjectFromStudent: Associação aluno %s - matéria %s deletada com sucesso.", studentID, subjectID)
+	logging.FromContext(ctx).Info("RemoveSubjectFromStudent: associação removida", "student_id", studentID, "subject_id", subjectID)
 	return nil
 }
 
+// ChangeStudentStatus muda a situação do aluno e grava a mudança no histórico, na mesma transação.
+// allowed recebe a situação atual (lida com a linha travada) e recusa a mudança retornando um erro.
+// expectedVersion diferente de zero precisa ser a versão atual (senão ErrVersionConflict).
+// Em caso de sucesso, transition recebe ID, situação anterior, ator e horário, e o aluno atualizado é devolvido.
+func (r *StudentRepository) ChangeStudentStatus(ctx context.Context, id string, expectedVersion int, allowed func(from string) error, transition *models.StudentStatusTransition) (*models.Student, error) {
+	ctx, done := observeQuery(ctx, "students", "ChangeStudentStatus")
+	defer done()
+	var after *models.Student
+	err := withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockStudentTx(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+		if err := checkVersion(expectedVersion, before.Version); err != nil {
+			return err
+		}
+		if err := allowed(before.Status); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE students SET status = $1, version = version + 1 WHERE id = $2`, transition.ToStatus, id); err != nil {
+			logging.FromContext(ctx).Error("ChangeStudentStatus: erro ao atualizar situação do aluno", "student_id", id, "error", err)
+			return err
+		}
+		transition.ID = uuid.New().String()
+		transition.StudentID = id
+		transition.FromStatus = before.Status
+		transition.Actor = requestctx.Actor(ctx)
+		query := `
+			INSERT INTO student_status_history (id, student_id, from_status, to_status, reason, effective_date, actor)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING recorded_at`
+		if err := tx.QueryRowContext(ctx, query, transition.ID, id, transition.FromStatus, transition.ToStatus, transition.Reason, transition.EffectiveDate, transition.Actor).Scan(&transition.RecordedAt); err != nil {
+			logging.FromContext(ctx).Error("ChangeStudentStatus: erro ao gravar histórico do aluno", "student_id", id, "error", err)
+			return err
+		}
+
+		updated := *before
+		updated.Status = transition.ToStatus
+		updated.Version = before.Version + 1
+		after = &updated
+		return recordAudit(ctx, tx, AuditActionUpdate, AuditEntityStudents, id, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	logging.FromContext(ctx).Info("ChangeStudentStatus: situação do aluno alterada", "student_id", id, "from", transition.FromStatus, "to", transition.ToStatus)
+	return after, nil
+}
+
+// SetStudentProgram vincula o aluno a um curso (programID vazio desvincula).
+// expectedVersion diferente de zero precisa ser a versão atual (senão ErrVersionConflict).
+func (r *StudentRepository) SetStudentProgram(ctx context.Context, id, programID string, expectedVersion int) (*models.Student, error) {
+	ctx, done := observeQuery(ctx, "students", "SetStudentProgram")
+	defer done()
+	var after *models.Student
+	err := withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockStudentTx(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+		if err := checkVersion(expectedVersion, before.Version); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE students SET program_id = NULLIF($1, ''), version = version + 1 WHERE id = $2`, programID, id); err != nil {
+			logging.FromContext(ctx).Error("SetStudentProgram: erro ao vincular aluno ao curso", "student_id", id, "program_id", programID, "error", err)
+			return err
+		}
+		updated := *before
+		updated.ProgramID = programID
+		updated.Version = before.Version + 1
+		after = &updated
+		return recordAudit(ctx, tx, AuditActionUpdate, AuditEntityStudents, id, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// GetStatusHistory devolve as mudanças de situação do aluno, da mais antiga à mais recente.
+func (r *StudentRepository) GetStatusHistory(ctx context.Context, studentID string) ([]models.StudentStatusTransition, error) {
+	ctx, done := observeQuery(ctx, "students", "GetStatusHistory")
+	defer done()
+	query := `
+		SELECT id, student_id, from_status, to_status, reason, to_char(effective_date, 'YYYY-MM-DD'), actor, recorded_at
+		FROM student_status_history
+		WHERE student_id = $1
+		ORDER BY recorded_at, id`
+	rows, err := r.conn().QueryContext(ctx, query, studentID)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetStatusHistory: erro ao consultar histórico do aluno", "student_id", studentID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.StudentStatusTransition{}
+	for rows.Next() {
+		var t models.StudentStatusTransition
+		if err := rows.Scan(&t.ID, &t.StudentID, &t.FromStatus, &t.ToStatus, &t.Reason, &t.EffectiveDate, &t.Actor, &t.RecordedAt); err != nil {
+			logging.FromContext(ctx).Error("GetStatusHistory: erro ao escanear histórico do aluno", "student_id", studentID, "error", err)
+			return nil, err
+		}
+		history = append(history, t)
+	}
+	return history, rows.Err()
+}
+
+// bumpStudentVersionTx incrementa a versão do aluno quando suas matérias mudam, invalidando ETags antigas.
+func bumpStudentVersionTx(ctx context.Context, tx *sql.Tx, studentID string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE students SET version = version + 1 WHERE id = $1`, studentID)
+	return err
+}
+
+// lockStudentTx lê (e trava até o fim da transação) a linha do aluno, para compor o "antes" da auditoria.
+// deleted escolhe entre alunos ativos (false) ou excluídos logicamente (true). Retorna nil se não existir.
+func lockStudentTx(ctx context.Context, tx *sql.Tx, id string, deleted bool) (*models.Student, error) {
+	student := &models.Student{}
+	query := `SELECT id, enrollment, name, current_year, shift, graduating, status, COALESCE(program_id, ''), version FROM students WHERE id = $1 AND (deleted_at IS NOT NULL) = $2 FOR UPDATE`
+	err := tx.QueryRowContext(ctx, query, id, deleted).Scan(&student.ID, &student.Enrollment, &student.Name, &student.CurrentYear, &student.Shift, &student.Graduating, &student.Status, &student.ProgramID, &student.Version)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return student, nil
+}
+
 // GetLastEnrollmentForYearAndShift busca a maior matrícula para o ano e turno especificados.
-func (r *StudentRepository) GetLastEnrollmentForYearAndShift(year int, studentShift string) (string, error) {
+// Alunos excluídos logicamente também contam, pois suas matrículas continuam reservadas.
+func (r *StudentRepository) GetLastEnrollmentForYearAndShift(ctx context.Context, year int, studentShift string) (string, error) {
+	ctx, done := observeQuery(ctx, "students", "GetLastEnrollmentForYearAndShift")
+	defer done()
 	var lastEnrollment sql.NullString // Usar sql.NullString para lidar com NULL do DB
 	query := `
 		SELECT enrollment FROM students
@@ -178,35 +597,37 @@ func (r *StudentRepository) GetLastEnrollmentForYearAndShift(year int, studentSh
 		ORDER BY enrollment DESC
 		LIMIT 1
 	`
-	err := r.db.QueryRow(query, fmt.Sprintf("%d", year), studentShift).Scan(&lastEnrollment)
+	err := r.conn().QueryRowContext(ctx, query, fmt.Sprintf("%d", year), studentShift).Scan(&lastEnrollment)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("GetLastEnrollmentForYearAndShift: Nenhuma matrícula encontrada para o ano %d e turno %s.", year, studentShift)
+			logging.FromContext(ctx).Debug("GetLastEnrollmentForYearAndShift: nenhuma matrícula encontrada", "year", year, "shift", studentShift)
 			return "", nil // Nenhuma matrícula encontrada para este ano e turno
 		}
-		log.Printf("GetLastEnrollmentForYearAndShift: Erro ao buscar última matrícula para o ano %d e turno %s: %v", year, studentShift, err)
+		logging.FromContext(ctx).Error("GetLastEnrollmentForYearAndShift: erro ao buscar última matrícula", "year", year, "shift", studentShift, "error", err)
 		return "", err
 	}
 
 	if lastEnrollment.Valid {
-		log.Printf("GetLastEnrollmentForYearAndShift: Última matrícula encontrada para %d%s: %s", year, studentShift, lastEnrollment.String)
+		logging.FromContext(ctx).Debug("GetLastEnrollmentForYearAndShift: última matrícula encontrada", "year", year, "shift", studentShift, "enrollment", lastEnrollment.String)
 		return lastEnrollment.String, nil
 	}
-	log.Printf("GetLastEnrollmentForYearAndShift: Matrícula nula/inválida para o ano %d e turno %s.", year, studentShift)
+	logging.FromContext(ctx).Debug("GetLastEnrollmentForYearAndShift: matrícula nula", "year", year, "shift", studentShift)
 	return "", nil // Caso a string seja nula (não deveria acontecer com LIMIT 1)
 }
 
 // GetSubjectsByStudentID busca todas as matérias associadas a um aluno.
-func (r *StudentRepository) GetSubjectsByStudentID(studentID string) ([]models.Subject, error) {
+func (r *StudentRepository) GetSubjectsByStudentID(ctx context.Context, studentID string) ([]models.Subject, error) {
+	ctx, done := observeQuery(ctx, "students", "GetSubjectsByStudentID")
+	defer done()
 	query := `
-    SELECT s.id, s.name, s.year, s.credits
+    SELECT s.id, s.name, s.year, s.credits, s.version
     FROM subjects s
     JOIN student_subjects ss ON s.id = ss.subject_id
-    WHERE ss.student_id = $1`
-	rows, err := r.db.Query(query, studentID)
+    WHERE ss.student_id = $1 AND s.deleted_at IS NULL`
+	rows, err := r.conn().QueryContext(ctx, query, studentID)
 	if err != nil {
-		log.Printf("GetSubjectsByStudentID: Erro ao executar query para aluno ID %s: %v", studentID, err)
+		logging.FromContext(ctx).Error("GetSubjectsByStudentID: erro ao consultar matérias do aluno", "student_id", studentID, "error", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -214,16 +635,16 @@ func (r *StudentRepository) GetSubjectsByStudentID(studentID string) ([]models.S
 	var subjects []models.Subject
 	for rows.Next() {
 		subject := models.Subject{}
-		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits); err != nil {
-			log.Printf("GetSubjectsByStudentID: Erro ao escanear matéria do aluno ID %s: %v", studentID, err)
+		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Version); err != nil {
+			logging.FromContext(ctx).Error("GetSubjectsByStudentID: erro ao escanear matéria do aluno", "student_id", studentID, "error", err)
 			return nil, err
 		}
 		subjects = append(subjects, subject)
 	}
 	if subjects == nil { // Isso só aconteceria se o `append` nunca fosse chamado, por exemplo.
-		log.Printf("GetSubjectsByStudentID: Nenhuma matéria encontrada para aluno ID %s (retornando slice vazio).", studentID)
+		logging.FromContext(ctx).Debug("GetSubjectsByStudentID: aluno sem matérias", "student_id", studentID)
 		return []models.Subject{}, nil
 	}
-	log.Printf("GetSubjectsByStudentID: %d matérias encontradas para aluno ID %s.", len(subjects), studentID)
+	logging.FromContext(ctx).Debug("GetSubjectsByStudentID: matérias encontradas", "student_id", studentID, "count", len(subjects))
 	return subjects, nil
 }
@@ -2,51 +2,81 @@
 package repositories
 
 import (
-	"college_api/config"
+	"college_api/logging"
 	"college_api/models"
+	"context"
 	"database/sql"
-	"log"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type SubjectRepository struct {
 	db *sql.DB
+	tx *sql.Tx // Transação externa, quando o repositório foi obtido via WithTx
+}
+
+func NewSubjectRepository(db *sql.DB) *SubjectRepository {
+	return &SubjectRepository{db: db}
 }
 
-func NewSubjectRepository() *SubjectRepository {
-	return &SubjectRepository{db: config.DB}
+// WithTx devolve uma cópia do repositório que executa todas as operações dentro de tx.
+// Quem abriu a transação é responsável pelo commit ou rollback.
+func (r *SubjectRepository) WithTx(tx *sql.Tx) *SubjectRepository {
+	return &SubjectRepository{db: r.db, tx: tx}
+}
+
+// BeginTx abre uma transação no pool, para operações em lote (ver WithTx).
+func (r *SubjectRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return r.db.BeginTx(ctx, nil)
+}
+
+// conn devolve a conexão usada pelas leituras: a transação vinculada ou o pool.
+func (r *SubjectRepository) conn() dbConn {
+	return connFor(r.db, r.tx)
 }
 
 // CreateSubject insere uma nova matéria no banco de dados.
-func (r *SubjectRepository) CreateSubject(subject *models.Subject) error {
-	query := `INSERT INTO subjects (id, name, year, credits) VALUES ($1, $2, $3, $4)` // << AQUI
-	_, err := r.db.Exec(query, subject.ID, subject.Name, subject.Year, subject.Credits)
-	if err != nil {
-		log.Printf("Erro ao criar matéria: %v", err)
-		return err
-	}
-	return nil
+func (r *SubjectRepository) CreateSubject(ctx context.Context, subject *models.Subject) error {
+	ctx, done := observeQuery(ctx, "subjects", "CreateSubject")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		query := `INSERT INTO subjects (id, name, year, credits) VALUES ($1, $2, $3, $4)` // << AQUI
+		_, err := tx.ExecContext(ctx, query, subject.ID, subject.Name, subject.Year, subject.Credits)
+		if err != nil {
+			logging.FromContext(ctx).Error("CreateSubject: erro ao inserir matéria", "subject_id", subject.ID, "error", err)
+			return err
+		}
+		subject.Version = 1 // Valor padrão da coluna
+		return recordAudit(ctx, tx, AuditActionCreate, AuditEntitySubjects, subject.ID, nil, subject)
+	})
 }
 
 // GetSubjectByID busca uma matéria pelo ID.
-func (r *SubjectRepository) GetSubjectByID(id string) (*models.Subject, error) {
+func (r *SubjectRepository) GetSubjectByID(ctx context.Context, id string) (*models.Subject, error) {
+	ctx, done := observeQuery(ctx, "subjects", "GetSubjectByID")
+	defer done()
 	subject := &models.Subject{}
-	query := `SELECT id, name, year, credits FROM subjects WHERE id = $1` // << AQUI
-	err := r.db.QueryRow(query, id).Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits)
+	query := `SELECT id, name, year, credits, version FROM subjects WHERE id = $1 AND deleted_at IS NULL` // << AQUI
+	err := r.conn().QueryRowContext(ctx, query, id).Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		log.Printf("Erro ao buscar matéria por ID: %v", err)
+		logging.FromContext(ctx).Error("GetSubjectByID: erro ao buscar matéria", "subject_id", id, "error", err)
 		return nil, err
 	}
 	return subject, nil
 }
 
 // GetAllSubjects busca todas as matérias.
-func (r *SubjectRepository) GetAllSubjects() ([]models.Subject, error) {
-	rows, err := r.db.Query(`SELECT id, name, year, credits FROM subjects`) // Não tem parâmetro, então não muda
+func (r *SubjectRepository) GetAllSubjects(ctx context.Context, filter models.SubjectFilter) ([]models.Subject, error) {
+	ctx, done := observeQuery(ctx, "subjects", "GetAllSubjects")
+	defer done()
+	where := subjectFilterWhere(filter)
+	rows, err := r.conn().QueryContext(ctx, `SELECT id, name, year, credits, version FROM subjects WHERE `+where.String(), where.args...)
 	if err != nil {
-		log.Printf("Erro ao buscar todas as matérias: %v", err)
+		logging.FromContext(ctx).Error("GetAllSubjects: erro ao consultar matérias", "error", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -54,8 +84,8 @@ func (r *SubjectRepository) GetAllSubjects() ([]models.Subject, error) {
 	var subjects []models.Subject
 	for rows.Next() {
 		subject := models.Subject{}
-		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits); err != nil {
-			log.Printf("Erro ao escanear matéria: %v", err)
+		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Version); err != nil {
+			logging.FromContext(ctx).Error("GetAllSubjects: erro ao escanear matéria", "error", err)
 			return nil, err
 		}
 		subjects = append(subjects, subject)
@@ -63,32 +93,250 @@ func (r *SubjectRepository) GetAllSubjects() ([]models.Subject, error) {
 	return subjects, nil
 }
 
-// UpdateSubject atualiza uma matéria existente.
-func (r *SubjectRepository) UpdateSubject(subject *models.Subject) error {
-	query := `UPDATE subjects SET name = $1, year = $2, credits = $3 WHERE id = $4` // << AQUI
-	result, err := r.db.Exec(query, subject.Name, subject.Year, subject.Credits, subject.ID)
+// StreamSubjects percorre as matérias que atendem ao filtro, ordenadas por ano e ID, chamando fn para cada uma.
+func (r *SubjectRepository) StreamSubjects(ctx context.Context, filter models.SubjectFilter, fn func(models.Subject) error) error {
+	ctx, done := observeStream(ctx, "subjects", "StreamSubjects")
+	defer done()
+	where := subjectFilterWhere(filter)
+	query := `SELECT id, name, year, credits, version FROM subjects WHERE ` + where.String() + ` ORDER BY year, id`
+	rows, err := r.conn().QueryContext(ctx, query, where.args...)
 	if err != nil {
-		log.Printf("Erro ao atualizar matéria: %v", err)
+		logging.FromContext(ctx).Error("StreamSubjects: erro ao consultar matérias", "error", err)
 		return err
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	defer rows.Close()
+
+	for rows.Next() {
+		subject := models.Subject{}
+		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Version); err != nil {
+			logging.FromContext(ctx).Error("StreamSubjects: erro ao escanear matéria", "error", err)
+			return err
+		}
+		if err := fn(subject); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// subjectFilterWhere traduz o filtro de matérias em condições sobre a tabela subjects.
+func subjectFilterWhere(filter models.SubjectFilter) *whereClause {
+	where := &whereClause{conds: []string{"deleted_at IS NULL"}}
+	if filter.Name != "" {
+		where.add("name ILIKE ?", likePattern(filter.Name))
+	}
+	if filter.Year != 0 {
+		where.add("year = ?", filter.Year)
+	}
+	return where
+}
+
+// UpdateSubject atualiza uma matéria existente.
+// Se subject.Version for diferente de zero, ela precisa ser a versão atual (senão ErrVersionConflict).
+func (r *SubjectRepository) UpdateSubject(ctx context.Context, subject *models.Subject) error {
+	ctx, done := observeQuery(ctx, "subjects", "UpdateSubject")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockSubjectTx(ctx, tx, subject.ID, false)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+		if err := checkVersion(subject.Version, before.Version); err != nil {
+			return err
+		}
+
+		query := `UPDATE subjects SET name = $1, year = $2, credits = $3, version = version + 1 WHERE id = $4` // << AQUI
+		if _, err := tx.ExecContext(ctx, query, subject.Name, subject.Year, subject.Credits, subject.ID); err != nil {
+			logging.FromContext(ctx).Error("UpdateSubject: erro ao atualizar matéria", "subject_id", subject.ID, "error", err)
+			return err
+		}
+		subject.Version = before.Version + 1
+		return recordAudit(ctx, tx, AuditActionUpdate, AuditEntitySubjects, subject.ID, before, subject)
+	})
+}
+
+// DeleteSubject exclui logicamente uma matéria pelo ID (preenche deleted_at).
+// As associações com alunos são mantidas, preservando o histórico acadêmico.
+// expectedVersion diferente de zero precisa ser a versão atual (senão ErrVersionConflict).
+func (r *SubjectRepository) DeleteSubject(ctx context.Context, id string, expectedVersion int) error {
+	ctx, done := observeQuery(ctx, "subjects", "DeleteSubject")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockSubjectTx(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+		if err := checkVersion(expectedVersion, before.Version); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE subjects SET deleted_at = NOW(), version = version + 1 WHERE id = $1`, id); err != nil { // << AQUI
+			logging.FromContext(ctx).Error("DeleteSubject: erro ao excluir matéria", "subject_id", id, "error", err)
+			return err
+		}
+		return recordAudit(ctx, tx, AuditActionDelete, AuditEntitySubjects, id, before, nil)
+	})
+}
+
+// RestoreSubject desfaz a exclusão lógica de uma matéria. Retorna sql.ErrNoRows se não houver matéria excluída com o ID.
+func (r *SubjectRepository) RestoreSubject(ctx context.Context, id string) error {
+	ctx, done := observeQuery(ctx, "subjects", "RestoreSubject")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockSubjectTx(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE subjects SET deleted_at = NULL, version = version + 1 WHERE id = $1`, id); err != nil {
+			logging.FromContext(ctx).Error("RestoreSubject: erro ao restaurar matéria", "subject_id", id, "error", err)
+			return err
+		}
+		before.Version++
+		return recordAudit(ctx, tx, AuditActionRestore, AuditEntitySubjects, id, nil, before)
+	})
+}
+
+// SubjectIDExists verifica se o ID já está em uso, inclusive por matérias excluídas logicamente.
+func (r *SubjectRepository) SubjectIDExists(ctx context.Context, id string) (bool, error) {
+	ctx, done := observeQuery(ctx, "subjects", "SubjectIDExists")
+	defer done()
+	var exists bool
+	err := r.conn().QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM subjects WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		logging.FromContext(ctx).Error("SubjectIDExists: erro ao verificar ID de matéria", "subject_id", id, "error", err)
+		return false, err
+	}
+	return exists, nil
+}
+
+// CountStudentsBySubject conta quantos alunos (inclusive excluídos logicamente) estão associados
+// a cada uma das matérias informadas. Matérias sem associação não aparecem no mapa.
+func (r *SubjectRepository) CountStudentsBySubject(ctx context.Context, ids []string) (map[string]int, error) {
+	ctx, done := observeQuery(ctx, "subjects", "CountStudentsBySubject")
+	defer done()
+	counts := map[string]int{}
+	if len(ids) == 0 {
+		return counts, nil
 	}
-	return nil
+	rows, err := r.conn().QueryContext(ctx, `SELECT subject_id, COUNT(*) FROM student_subjects WHERE subject_id = ANY($1) GROUP BY subject_id`, pq.Array(ids))
+	if err != nil {
+		logging.FromContext(ctx).Error("CountStudentsBySubject: erro ao contar associações", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		counts[id] = n
+	}
+	return counts, rows.Err()
 }
 
-// DeleteSubject deleta uma matéria pelo ID.
-func (r *SubjectRepository) DeleteSubject(id string) error {
-	query := `DELETE FROM subjects WHERE id = $1` // << AQUI
-	result, err := r.db.Exec(query, id)
+// ProgramsBySubject lista, para cada uma das matérias informadas, os cursos cuja grade a inclui
+// (em ordem de ID). Matérias fora de qualquer grade não aparecem no mapa.
+func (r *SubjectRepository) ProgramsBySubject(ctx context.Context, ids []string) (map[string][]string, error) {
+	ctx, done := observeQuery(ctx, "subjects", "ProgramsBySubject")
+	defer done()
+	programs := map[string][]string{}
+	if len(ids) == 0 {
+		return programs, nil
+	}
+	rows, err := r.conn().QueryContext(ctx, `SELECT subject_id, program_id FROM program_subjects WHERE subject_id = ANY($1) ORDER BY subject_id, program_id`, pq.Array(ids))
 	if err != nil {
-		log.Printf("Erro ao deletar matéria: %v", err)
-		return err
+		logging.FromContext(ctx).Error("ProgramsBySubject: erro ao buscar grades dos cursos", "error", err)
+		return nil, err
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	defer rows.Close()
+	for rows.Next() {
+		var subjectID, programID string
+		if err := rows.Scan(&subjectID, &programID); err != nil {
+			return nil, err
+		}
+		programs[subjectID] = append(programs[subjectID], programID)
+	}
+	return programs, rows.Err()
+}
+
+// PurgeDeletedSubjects remove definitivamente as matérias excluídas antes de cutoff.
+// Matérias que ainda têm alunos associados ou fazem parte da grade de um curso nunca são removidas:
+// seus IDs voltam em skipped.
+func (r *SubjectRepository) PurgeDeletedSubjects(ctx context.Context, cutoff time.Time) (purged []string, skipped []string, err error) {
+	ctx, done := observeQuery(ctx, "subjects", "PurgeDeletedSubjects")
+	defer done()
+	purged, skipped = []string{}, []string{}
+	err = withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		query := `
+			SELECT s.id, s.name, s.year, s.credits, s.version,
+			       EXISTS (SELECT 1 FROM student_subjects ss WHERE ss.subject_id = s.id)
+			           OR EXISTS (SELECT 1 FROM program_subjects ps WHERE ps.subject_id = s.id)
+			FROM subjects s
+			WHERE s.deleted_at < $1
+			FOR UPDATE`
+		rows, err := tx.QueryContext(ctx, query, cutoff)
+		if err != nil {
+			return err
+		}
+		var subjects []models.Subject
+		for rows.Next() {
+			subject := models.Subject{}
+			var associated bool
+			if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Version, &associated); err != nil {
+				rows.Close()
+				return err
+			}
+			if associated {
+				skipped = append(skipped, subject.ID)
+				continue
+			}
+			subjects = append(subjects, subject)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for i := range subjects {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM subjects WHERE id = $1`, subjects[i].ID); err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, tx, AuditActionPurge, AuditEntitySubjects, subjects[i].ID, &subjects[i], nil); err != nil {
+				return err
+			}
+			purged = append(purged, subjects[i].ID)
+		}
+		return nil
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("PurgeDeletedSubjects: erro ao expurgar matérias excluídas", "error", err)
+		return nil, nil, err
+	}
+	return purged, skipped, nil
+}
+
+// lockSubjectTx lê e trava a linha da matéria dentro da transação.
+// deleted escolhe entre matérias ativas (false) ou excluídas logicamente (true). Retorna nil se não existir.
+func lockSubjectTx(ctx context.Context, tx *sql.Tx, id string, deleted bool) (*models.Subject, error) {
+	subject := &models.Subject{}
+	query := `SELECT id, name, year, credits, version FROM subjects WHERE id = $1 AND (deleted_at IS NOT NULL) = $2 FOR UPDATE`
+	err := tx.QueryRowContext(ctx, query, id, deleted).Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Version)
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-	return nil
+	if err != nil {
+		return nil, err
+	}
+	return subject, nil
 }
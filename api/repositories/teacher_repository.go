@@ -2,103 +2,300 @@
 package repositories
 
 import (
-	"college_api/config"
+	"college_api/logging"
 	"college_api/models"
+	"context"
 	"database/sql" // Adicionar import para fmt
-	"log"
+	"errors"
+	"fmt"
+	"time"
 	// Não precisa importar uuid aqui se o serviço já gera o ID
 )
 
+// ErrDepartmentHead indica que o professor chefia um departamento e por isso não pode ser excluído.
+var ErrDepartmentHead = errors.New("o professor chefia um departamento")
+
 type TeacherRepository struct {
 	db *sql.DB
+	tx *sql.Tx // Transação externa, quando o repositório foi obtido via WithTx
+}
+
+func NewTeacherRepository(db *sql.DB) *TeacherRepository {
+	return &TeacherRepository{db: db}
+}
+
+// WithTx devolve uma cópia do repositório que executa todas as operações dentro de tx.
+// Quem abriu a transação é responsável pelo commit ou rollback.
+func (r *TeacherRepository) WithTx(tx *sql.Tx) *TeacherRepository {
+	return &TeacherRepository{db: r.db, tx: tx}
 }
 
-func NewTeacherRepository() *TeacherRepository {
-	return &TeacherRepository{db: config.DB}
+// conn devolve a conexão usada pelas leituras: a transação vinculada ou o pool.
+func (r *TeacherRepository) conn() dbConn {
+	return connFor(r.db, r.tx)
 }
 
 // CreateTeacher insere um novo professor no banco de dados.
 // O ID e Registry já devem vir preenchidos do Service.
-func (r *TeacherRepository) CreateTeacher(teacher *models.Teacher) error {
-	query := `INSERT INTO teachers (id, registry, name, department) VALUES ($1, $2, $3, $4)`
-	_, err := r.db.Exec(query, teacher.ID, teacher.Registry, teacher.Name, teacher.Department)
-	if err != nil {
-		log.Printf("Erro ao criar professor: %v", err)
-		return err
-	}
-	return nil
+func (r *TeacherRepository) CreateTeacher(ctx context.Context, teacher *models.Teacher) error {
+	ctx, done := observeQuery(ctx, "teachers", "CreateTeacher")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		query := `INSERT INTO teachers (id, registry, name, department_id) VALUES ($1, $2, $3, $4)`
+		_, err := tx.ExecContext(ctx, query, teacher.ID, teacher.Registry, teacher.Name, teacher.DepartmentID)
+		if err != nil {
+			logging.FromContext(ctx).Error("CreateTeacher: erro ao inserir professor", "teacher_id", teacher.ID, "error", err)
+			return err
+		}
+		teacher.Version = 1 // Valor padrão da coluna
+		return recordAudit(ctx, tx, AuditActionCreate, AuditEntityTeachers, teacher.ID, nil, teacher)
+	})
 }
 
 // GetTeacherByID busca um professor pelo ID.
-func (r *TeacherRepository) GetTeacherByID(id string) (*models.Teacher, error) {
-	teacher := &models.Teacher{}
-	query := `SELECT id, registry, name, department FROM teachers WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&teacher.ID, &teacher.Registry, &teacher.Name, &teacher.Department)
+func (r *TeacherRepository) GetTeacherByID(ctx context.Context, id string) (*models.Teacher, error) {
+	ctx, done := observeQuery(ctx, "teachers", "GetTeacherByID")
+	defer done()
+	teacher, err := scanTeacher(r.conn().QueryRowContext(ctx, selectTeacherSQL+` WHERE t.id = $1 AND t.deleted_at IS NULL`, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		log.Printf("Erro ao buscar professor por ID: %v", err)
+		logging.FromContext(ctx).Error("GetTeacherByID: erro ao buscar professor", "teacher_id", id, "error", err)
 		return nil, err
 	}
 	return teacher, nil
 }
 
-// GetAllTeachers busca todos os professores.
-func (r *TeacherRepository) GetAllTeachers() ([]models.Teacher, error) {
-	rows, err := r.db.Query(`SELECT id, registry, name, department FROM teachers`)
+// GetAllTeachers busca todos os professores que atendem ao filtro.
+func (r *TeacherRepository) GetAllTeachers(ctx context.Context, filter models.TeacherFilter) ([]models.Teacher, error) {
+	ctx, done := observeQuery(ctx, "teachers", "GetAllTeachers")
+	defer done()
+	where := teacherFilterWhere(filter)
+	rows, err := r.conn().QueryContext(ctx, selectTeacherSQL+` WHERE `+where.String(), where.args...)
 	if err != nil {
-		log.Printf("Erro ao buscar todos os professores: %v", err)
+		logging.FromContext(ctx).Error("GetAllTeachers: erro ao consultar professores", "error", err)
 		return nil, err
 	}
 	defer rows.Close()
 
 	var teachers []models.Teacher
 	for rows.Next() {
-		teacher := models.Teacher{}
-		if err := rows.Scan(&teacher.ID, &teacher.Registry, &teacher.Name, &teacher.Department); err != nil {
-			log.Printf("Erro ao escanear professor: %v", err)
+		teacher, err := scanTeacher(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("GetAllTeachers: erro ao escanear professor", "error", err)
 			return nil, err
 		}
-		teachers = append(teachers, teacher)
+		teachers = append(teachers, *teacher)
 	}
 	return teachers, nil
 }
 
-// UpdateTeacher atualiza um professor existente.
-func (r *TeacherRepository) UpdateTeacher(teacher *models.Teacher) error {
-	query := `UPDATE teachers SET registry = $1, name = $2, department = $3 WHERE id = $4`
-	result, err := r.db.Exec(query, teacher.Registry, teacher.Name, teacher.Department, teacher.ID)
+// StreamTeachers percorre os professores que atendem ao filtro, ordenados pelo registro, chamando fn para cada um.
+func (r *TeacherRepository) StreamTeachers(ctx context.Context, filter models.TeacherFilter, fn func(models.Teacher) error) error {
+	ctx, done := observeStream(ctx, "teachers", "StreamTeachers")
+	defer done()
+	where := teacherFilterWhere(filter)
+	query := selectTeacherSQL + ` WHERE ` + where.String() + ` ORDER BY t.registry`
+	rows, err := r.conn().QueryContext(ctx, query, where.args...)
 	if err != nil {
-		log.Printf("Erro ao atualizar professor: %v", err)
+		logging.FromContext(ctx).Error("StreamTeachers: erro ao consultar professores", "error", err)
 		return err
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	defer rows.Close()
+
+	for rows.Next() {
+		teacher, err := scanTeacher(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("StreamTeachers: erro ao escanear professor", "error", err)
+			return err
+		}
+		if err := fn(*teacher); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// teacherFilterWhere traduz o filtro de professores em condições sobre a tabela teachers (alias t).
+func teacherFilterWhere(filter models.TeacherFilter) *whereClause {
+	where := &whereClause{conds: []string{"t.deleted_at IS NULL"}}
+	if filter.Name != "" {
+		where.add("t.name ILIKE ?", likePattern(filter.Name))
+	}
+	if filter.Department != "" {
+		where.add("t.department_id IN (SELECT id FROM departments WHERE LOWER(?) IN (LOWER(code), LOWER(name)))", filter.Department)
 	}
-	return nil
+	if filter.DepartmentID != "" {
+		where.add("t.department_id = ?", filter.DepartmentID)
+	}
+	return where
+}
+
+// UpdateTeacher atualiza um professor existente.
+// Se teacher.Version for diferente de zero, ela precisa ser a versão atual (senão ErrVersionConflict).
+func (r *TeacherRepository) UpdateTeacher(ctx context.Context, teacher *models.Teacher) error {
+	ctx, done := observeQuery(ctx, "teachers", "UpdateTeacher")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockTeacherTx(ctx, tx, teacher.ID, false)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+		if err := checkVersion(teacher.Version, before.Version); err != nil {
+			return err
+		}
+
+		query := `UPDATE teachers SET registry = $1, name = $2, department_id = $3, version = version + 1 WHERE id = $4`
+		if _, err := tx.ExecContext(ctx, query, teacher.Registry, teacher.Name, teacher.DepartmentID, teacher.ID); err != nil {
+			logging.FromContext(ctx).Error("UpdateTeacher: erro ao atualizar professor", "teacher_id", teacher.ID, "error", err)
+			return err
+		}
+		teacher.Version = before.Version + 1
+		return recordAudit(ctx, tx, AuditActionUpdate, AuditEntityTeachers, teacher.ID, before, teacher)
+	})
+}
+
+// DeleteTeacher exclui logicamente um professor pelo ID (preenche deleted_at).
+// expectedVersion diferente de zero precisa ser a versão atual (senão ErrVersionConflict).
+// O chefe de um departamento ativo não é excluído (ErrDepartmentHead com o código do departamento):
+// a verificação bloqueia os departamentos na mesma transação, para que nenhum fique com um chefe excluído.
+func (r *TeacherRepository) DeleteTeacher(ctx context.Context, id string, expectedVersion int) error {
+	ctx, done := observeQuery(ctx, "teachers", "DeleteTeacher")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockTeacherTx(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+		if err := checkVersion(expectedVersion, before.Version); err != nil {
+			return err
+		}
+		var code string
+		err = tx.QueryRowContext(ctx, `SELECT code FROM departments WHERE head_teacher_id = $1 AND deleted_at IS NULL LIMIT 1 FOR UPDATE`, id).Scan(&code)
+		if err == nil {
+			return fmt.Errorf("%w: %s", ErrDepartmentHead, code)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			logging.FromContext(ctx).Error("DeleteTeacher: erro ao verificar chefia de departamento", "teacher_id", id, "error", err)
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE teachers SET deleted_at = NOW(), version = version + 1 WHERE id = $1`, id); err != nil {
+			logging.FromContext(ctx).Error("DeleteTeacher: erro ao excluir professor", "teacher_id", id, "error", err)
+			return err
+		}
+		return recordAudit(ctx, tx, AuditActionDelete, AuditEntityTeachers, id, before, nil)
+	})
+}
+
+// RestoreTeacher desfaz a exclusão lógica de um professor. Retorna sql.ErrNoRows se não houver professor excluído com o ID.
+func (r *TeacherRepository) RestoreTeacher(ctx context.Context, id string) error {
+	ctx, done := observeQuery(ctx, "teachers", "RestoreTeacher")
+	defer done()
+	return withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		before, err := lockTeacherTx(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return sql.ErrNoRows
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE teachers SET deleted_at = NULL, version = version + 1 WHERE id = $1`, id); err != nil {
+			logging.FromContext(ctx).Error("RestoreTeacher: erro ao restaurar professor", "teacher_id", id, "error", err)
+			return err
+		}
+		before.Version++
+		return recordAudit(ctx, tx, AuditActionRestore, AuditEntityTeachers, id, nil, before)
+	})
 }
 
-// DeleteTeacher deleta um professor pelo ID.
-func (r *TeacherRepository) DeleteTeacher(id string) error {
-	query := `DELETE FROM teachers WHERE id = $1`
-	result, err := r.db.Exec(query, id)
+// PurgeDeletedTeachers remove definitivamente os professores excluídos antes de cutoff. Retorna os IDs removidos.
+func (r *TeacherRepository) PurgeDeletedTeachers(ctx context.Context, cutoff time.Time) ([]string, error) {
+	ctx, done := observeQuery(ctx, "teachers", "PurgeDeletedTeachers")
+	defer done()
+	purged := []string{}
+	err := withTx(ctx, r.db, r.tx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectTeacherSQL+` WHERE t.deleted_at < $1 FOR UPDATE OF t`, cutoff)
+		if err != nil {
+			return err
+		}
+		var teachers []models.Teacher
+		for rows.Next() {
+			teacher, err := scanTeacher(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			teachers = append(teachers, *teacher)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for i := range teachers {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM teachers WHERE id = $1`, teachers[i].ID); err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, tx, AuditActionPurge, AuditEntityTeachers, teachers[i].ID, &teachers[i], nil); err != nil {
+				return err
+			}
+			purged = append(purged, teachers[i].ID)
+		}
+		return nil
+	})
 	if err != nil {
-		log.Printf("Erro ao deletar professor: %v", err)
-		return err
+		logging.FromContext(ctx).Error("PurgeDeletedTeachers: erro ao expurgar professores excluídos", "error", err)
+		return nil, err
+	}
+	return purged, nil
+}
+
+// selectTeacherSQL é a consulta base dos professores (alias t), com o nome do departamento.
+// Professores ainda não migrados mostram o departamento em texto livre da coluna antiga.
+const selectTeacherSQL = `
+	SELECT t.id, t.registry, t.name, COALESCE(t.department_id, ''), COALESCE(d.name, t.department, ''), t.version
+	FROM teachers t
+	LEFT JOIN departments d ON d.id = t.department_id`
+
+// scanTeacher lê um professor de *sql.Row ou *sql.Rows (colunas de selectTeacherSQL).
+func scanTeacher(row interface{ Scan(...interface{}) error }) (*models.Teacher, error) {
+	teacher := &models.Teacher{}
+	if err := row.Scan(&teacher.ID, &teacher.Registry, &teacher.Name, &teacher.DepartmentID, &teacher.Department, &teacher.Version); err != nil {
+		return nil, err
+	}
+	return teacher, nil
+}
+
+// lockTeacherTx lê e trava a linha do professor dentro da transação.
+// deleted escolhe entre professores ativos (false) ou excluídos logicamente (true). Retorna nil se não existir.
+func lockTeacherTx(ctx context.Context, tx *sql.Tx, id string, deleted bool) (*models.Teacher, error) {
+	query := selectTeacherSQL + ` WHERE t.id = $1 AND (t.deleted_at IS NOT NULL) = $2 FOR UPDATE OF t`
+	teacher, err := scanTeacher(tx.QueryRowContext(ctx, query, id, deleted))
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return teacher, nil
 }
 
 // GetLastRegistryForDepartment busca o maior número de registro para o departamento especificado.
+// Professores excluídos logicamente também contam, pois seus registros continuam reservados.
 // Retorna o registro como string e um erro, se houver.
 // Retorna "" e nil se não houver registros para o departamento.
-func (r *TeacherRepository) GetLastRegistryForDepartment(departmentCode string) (string, error) {
+func (r *TeacherRepository) GetLastRegistryForDepartment(ctx context.Context, departmentCode string) (string, error) {
+	ctx, done := observeQuery(ctx, "teachers", "GetLastRegistryForDepartment")
+	defer done()
 	var lastRegistry sql.NullString
 	query := `
 		SELECT registry FROM teachers
@@ -106,13 +303,13 @@ func (r *TeacherRepository) GetLastRegistryForDepartment(departmentCode string)
 		ORDER BY registry DESC
 		LIMIT 1
 	`
-	err := r.db.QueryRow(query, departmentCode).Scan(&lastRegistry)
+	err := r.conn().QueryRowContext(ctx, query, departmentCode).Scan(&lastRegistry)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", nil // Nenhuma matrícula encontrada para este ano e turno
 		}
-		log.Printf("Erro ao buscar último registro para o departamento %s: %v", departmentCode, err)
+		logging.FromContext(ctx).Error("GetLastRegistryForDepartment: erro ao buscar último registro", "department_code", departmentCode, "error", err)
 		return "", err
 	}
 
@@ -0,0 +1,66 @@
+// repositories/unit_of_work.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrRollback pode ser devolvido pela função de uma unidade de trabalho para desfazer tudo o que ela
+// gravou sem que isso seja uma falha (ex: simulação). Do e DoBatch devolvem nil nesse caso.
+var ErrRollback = errors.New("unidade de trabalho desfeita")
+
+// UnitOfWork executa operações de vários repositórios em uma única transação.
+type UnitOfWork struct {
+	db *sql.DB
+}
+
+// NewUnitOfWork cria uma nova instância de UnitOfWork.
+func NewUnitOfWork(db *sql.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// TxRepositories são os repositórios vinculados à transação de uma unidade de trabalho.
+type TxRepositories struct {
+	Students *StudentRepository
+	Subjects *SubjectRepository
+	Teachers *TeacherRepository
+}
+
+// Do abre uma transação, entrega a fn os repositórios vinculados a ela e faz commit se fn não
+// retornar erro. Qualquer erro (ou panic) desfaz tudo o que fn gravou, em todos os repositórios.
+// A transação inteira está sujeita ao limite de tempo das operações (ver SetQueryTimeout).
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos *TxRepositories) error) error {
+	return u.do(ctx, "Do", queryTimeout, fn)
+}
+
+// DoBatch é Do para lotes (ex: importação de alunos), cuja transação pode passar do limite de tempo
+// das operações: só cada operação dentro de fn tem o seu limite. O cancelamento pelo cliente continua valendo.
+func (u *UnitOfWork) DoBatch(ctx context.Context, fn func(repos *TxRepositories) error) error {
+	return u.do(ctx, "DoBatch", 0, fn)
+}
+
+// do implementa Do e DoBatch; timeout 0 dispensa o limite da transação.
+func (u *UnitOfWork) do(ctx context.Context, operation string, timeout time.Duration, fn func(repos *TxRepositories) error) error {
+	ctx, done := observe(ctx, "unit_of_work", operation, timeout)
+	defer done()
+	err := withTx(ctx, u.db, nil, func(tx *sql.Tx) error {
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p)
+			}
+		}()
+		return fn(&TxRepositories{
+			Students: &StudentRepository{db: u.db, tx: tx},
+			Subjects: &SubjectRepository{db: u.db, tx: tx},
+			Teachers: &TeacherRepository{db: u.db, tx: tx},
+		})
+	})
+	if errors.Is(err, ErrRollback) {
+		return nil
+	}
+	return err
+}
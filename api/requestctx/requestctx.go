@@ -0,0 +1,51 @@
+// api/requestctx/requestctx.go
+package requestctx
+
+import "context"
+
+// contextKey evita colisões com chaves de contexto definidas em outros pacotes.
+type contextKey int
+
+const (
+	principalKey contextKey = iota
+	requestIDKey
+)
+
+// AnonymousActor é o ator registrado quando a requisição não tem principal autenticado.
+const AnonymousActor = "anonymous"
+
+// Principal identifica quem está fazendo a requisição (usuário autenticado ou chave de API).
+type Principal struct {
+	ID   string // Identificador estável do usuário ou da chave
+	Kind string // "user", "apikey" ou "cli" (ferramenta collegectl)
+}
+
+// WithPrincipal retorna um contexto que carrega o principal autenticado.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// PrincipalFrom devolve o principal do contexto, se houver.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey).(Principal)
+	return p, ok && p.ID != ""
+}
+
+// Actor devolve o identificador de quem executa a operação, para fins de auditoria.
+func Actor(ctx context.Context) string {
+	if p, ok := PrincipalFrom(ctx); ok {
+		return p.Kind + ":" + p.ID
+	}
+	return AnonymousActor
+}
+
+// WithRequestID retorna um contexto que carrega o ID da requisição.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID devolve o ID da requisição do contexto, ou "" se não houver.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
@@ -0,0 +1,77 @@
+// seed/data.go
+package seed
+
+// Nomes e sobrenomes comuns no Brasil, usados para gerar alunos e professores.
+var firstNames = []string{
+	"Ana", "Beatriz", "Camila", "Carolina", "Clara", "Fernanda", "Gabriela", "Isabela", "Júlia", "Larissa",
+	"Laura", "Letícia", "Luana", "Manuela", "Mariana", "Natália", "Patrícia", "Rafaela", "Sofia", "Vitória",
+	"Alexandre", "André", "Bruno", "Caio", "Carlos", "Daniel", "Diego", "Eduardo", "Felipe", "Gabriel",
+	"Guilherme", "Gustavo", "Henrique", "João", "Leonardo", "Lucas", "Matheus", "Pedro", "Rafael", "Thiago",
+}
+
+var surnames = []string{
+	"Silva", "Santos", "Oliveira", "Souza", "Rodrigues", "Ferreira", "Alves", "Pereira", "Lima", "Gomes",
+	"Costa", "Ribeiro", "Martins", "Carvalho", "Almeida", "Lopes", "Soares", "Fernandes", "Vieira", "Barbosa",
+	"Rocha", "Dias", "Nascimento", "Andrade", "Moreira", "Nunes", "Marques", "Machado", "Mendes", "Freitas",
+	"Cardoso", "Ramos", "Gonçalves", "Santana", "Teixeira", "Araújo", "Pinto", "Correia", "Cavalcanti", "Monteiro",
+}
+
+// department é um departamento com o prefixo das suas matérias e a grade por ano (1 a 4).
+type department struct {
+	Name     string
+	Prefix   string
+	Subjects [4][]string
+}
+
+var departments = []department{
+	{
+		Name:   "Computação",
+		Prefix: "BSI",
+		Subjects: [4][]string{
+			{"Algoritmos e Programação", "Fundamentos de Sistemas de Informação", "Lógica Matemática"},
+			{"Estruturas de Dados", "Programação Orientada a Objetos", "Arquitetura de Computadores"},
+			{"Banco de Dados", "Engenharia de Software", "Redes de Computadores"},
+			{"Sistemas Distribuídos", "Segurança da Informação", "Trabalho de Conclusão de Curso"},
+		},
+	},
+	{
+		Name:   "Matemática",
+		Prefix: "MAT",
+		Subjects: [4][]string{
+			{"Cálculo I", "Geometria Analítica"},
+			{"Cálculo II", "Álgebra Linear"},
+			{"Probabilidade e Estatística", "Cálculo Numérico"},
+			{"Pesquisa Operacional"},
+		},
+	},
+	{
+		Name:   "Física",
+		Prefix: "FIS",
+		Subjects: [4][]string{
+			{"Física Geral I"},
+			{"Física Geral II"},
+			{"Eletromagnetismo"},
+			{"Física Moderna"},
+		},
+	},
+	{
+		Name:   "Administração",
+		Prefix: "ADM",
+		Subjects: [4][]string{
+			{"Teoria Geral da Administração"},
+			{"Contabilidade Geral"},
+			{"Gestão de Projetos"},
+			{"Empreendedorismo", "Ética e Legislação Profissional"},
+		},
+	},
+	{
+		Name:   "Letras",
+		Prefix: "LET",
+		Subjects: [4][]string{
+			{"Leitura e Produção de Textos"},
+			{"Inglês Instrumental"},
+			{},
+			{"Metodologia Científica"},
+		},
+	},
+}
@@ -0,0 +1,154 @@
+// seed/seed.go
+// Pacote seed popula um banco vazio com dados de demonstração realistas e reproduzíveis.
+// Tudo passa pelos serviços, então matrículas, registros e auditoria seguem as regras reais.
+package seed
+
+import (
+	"college_api/models"
+	"college_api/services"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// DefaultSeed é a semente usada quando nenhuma é informada: a mesma semente gera sempre os mesmos dados.
+const DefaultSeed = 2025
+
+// ErrNotEmpty indica que o banco já tem dados e o seeder foi recusado.
+var ErrNotEmpty = errors.New("o banco já tem alunos, matérias, professores ou departamentos; o seeder só roda em um banco vazio")
+
+// Options controla a quantidade de dados gerados.
+type Options struct {
+	Seed                  int64 // Semente do gerador pseudoaleatório
+	Students              int   // Quantidade de alunos
+	TeachersPerDepartment int   // Professores por departamento
+}
+
+// Report resume o que foi criado.
+type Report struct {
+	Seed         int64 `json:"seed"`
+	Departments  int   `json:"departments"`
+	Subjects     int   `json:"subjects"`
+	Teachers     int   `json:"teachers"`
+	Students     int   `json:"students"`
+	Associations int   `json:"associations"`
+}
+
+// Services agrupa os serviços usados pelo seeder.
+type Services struct {
+	Students    *services.StudentService
+	Subjects    *services.SubjectService
+	Teachers    *services.TeacherService
+	Departments *services.DepartmentService
+}
+
+// Run gera os departamentos, a grade curricular (anos 1 a 4), os professores de cada departamento
+// (o primeiro vira o chefe) e os alunos, associando cada aluno a matérias do seu ano e dos anos anteriores.
+func Run(ctx context.Context, svc Services, opts Options) (*Report, error) {
+	if err := ensureEmpty(ctx, svc); err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+	report := &Report{Seed: opts.Seed}
+
+	// Matérias por ano, para montar as associações dos alunos
+	var byYear [4][]string
+	for _, dept := range departments {
+		for year, names := range dept.Subjects {
+			for i, name := range names {
+				subject := models.Subject{
+					ID:      fmt.Sprintf("%s%d%02d", dept.Prefix, year+1, i+1),
+					Name:    name,
+					Year:    year + 1,
+					Credits: 2 + 2*rng.Intn(3), // 2, 4 ou 6 créditos
+				}
+				if err := svc.Subjects.CreateSubject(ctx, &subject); err != nil {
+					return report, fmt.Errorf("erro ao criar matéria %s: %w", subject.ID, err)
+				}
+				byYear[year] = append(byYear[year], subject.ID)
+				report.Subjects++
+			}
+		}
+	}
+
+	for _, dept := range departments {
+		department := models.Department{Code: dept.Prefix, Name: dept.Name}
+		if err := svc.Departments.CreateDepartment(ctx, &department); err != nil {
+			return report, fmt.Errorf("erro ao criar departamento %s: %w", dept.Prefix, err)
+		}
+		report.Departments++
+
+		for i := 0; i < opts.TeachersPerDepartment; i++ {
+			teacher := models.Teacher{Name: "Prof. " + personName(rng), DepartmentID: department.ID}
+			if err := svc.Teachers.CreateTeacher(ctx, &teacher); err != nil {
+				return report, fmt.Errorf("erro ao criar professor: %w", err)
+			}
+			report.Teachers++
+			if i == 0 {
+				department.HeadTeacherID = teacher.ID
+				if err := svc.Departments.UpdateDepartment(ctx, &department); err != nil {
+					return report, fmt.Errorf("erro ao definir chefe do departamento %s: %w", dept.Prefix, err)
+				}
+			}
+		}
+	}
+
+	shifts := []string{"M", "T", "N"}
+	for i := 0; i < opts.Students; i++ {
+		student := models.Student{
+			Name:        personName(rng),
+			Shift:       shifts[rng.Intn(len(shifts))],
+			CurrentYear: 1 + rng.Intn(4),
+		}
+		// Todas as matérias do ano atual e cerca de metade das dos anos anteriores
+		for year := 0; year < student.CurrentYear; year++ {
+			for _, id := range byYear[year] {
+				if year == student.CurrentYear-1 || rng.Intn(2) == 0 {
+					student.Subjects = append(student.Subjects, models.Subject{ID: id})
+				}
+			}
+		}
+		if err := svc.Students.CreateStudent(ctx, &student); err != nil {
+			return report, fmt.Errorf("erro ao criar aluno %s: %w", student.Name, err)
+		}
+		report.Students++
+		report.Associations += len(student.Subjects)
+	}
+	return report, nil
+}
+
+// personName gera um nome com um prenome e dois sobrenomes distintos.
+func personName(rng *rand.Rand) string {
+	first := firstNames[rng.Intn(len(firstNames))]
+	middle := surnames[rng.Intn(len(surnames))]
+	last := surnames[rng.Intn(len(surnames))]
+	for last == middle {
+		last = surnames[rng.Intn(len(surnames))]
+	}
+	return first + " " + middle + " " + last
+}
+
+// ensureEmpty recusa o seeder se já houver dados ativos, para não misturar dados de demonstração com reais.
+func ensureEmpty(ctx context.Context, svc Services) error {
+	students, err := svc.Students.GetAllStudents(ctx, models.StudentFilter{Status: "all"})
+	if err != nil {
+		return err
+	}
+	subjects, err := svc.Subjects.GetAllSubjects(ctx, models.SubjectFilter{})
+	if err != nil {
+		return err
+	}
+	teachers, err := svc.Teachers.GetAllTeachers(ctx, models.TeacherFilter{})
+	if err != nil {
+		return err
+	}
+	departments, err := svc.Departments.GetAllDepartments(ctx)
+	if err != nil {
+		return err
+	}
+	if len(students) > 0 || len(subjects) > 0 || len(teachers) > 0 || len(departments) > 0 {
+		return ErrNotEmpty
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// services/audit_service.go
+package services
+
+import (
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/tracing"
+	"context"
+	"fmt"
+)
+
+// Limites de registros retornados por consulta de auditoria.
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// auditableEntities lista as entidades que podem ser consultadas na auditoria.
+var auditableEntities = map[string]bool{
+	repositories.AuditEntityStudents:        true,
+	repositories.AuditEntitySubjects:        true,
+	repositories.AuditEntityTeachers:        true,
+	repositories.AuditEntityStudentSubjects: true,
+	repositories.AuditEntityPrograms:        true,
+	repositories.AuditEntityDepartments:     true,
+}
+
+// AuditService define as operações de consulta da trilha de auditoria.
+type AuditService struct {
+	repo *repositories.AuditRepository
+}
+
+// NewAuditService cria uma nova instância de AuditService.
+func NewAuditService(repo *repositories.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// ListEntries busca o histórico de alterações de uma entidade (e opcionalmente de um registro específico).
+func (s *AuditService) ListEntries(ctx context.Context, entity, entityID string, limit int) ([]models.AuditEntry, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.ListEntries")
+	defer span.End()
+	if !auditableEntities[entity] {
+		return nil, fmt.Errorf("%w: entidade de auditoria inválida: %q", ErrValidation, entity)
+	}
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+	entries, err := s.repo.ListAuditEntries(ctx, entity, entityID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar registros de auditoria: %w", err)
+	}
+	return entries, nil
+}
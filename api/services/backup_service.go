@@ -0,0 +1,301 @@
+// services/backup_service.go
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"college_api/logging"
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// BackupVersion é a versão do layout do backup (tabelas e colunas de repositories.BackupTables).
+// Ao mudar o layout, incremente-a e acrescente em backupUpgrades a conversão da versão anterior,
+// para que os backups já gerados continuem restauráveis.
+const BackupVersion = 1
+
+// backupUpgrade converte um registro do layout v para o layout v+1 (ex: renomear uma coluna ou
+// preencher uma coluna nova). Um registro cuja Table fique vazia é descartado.
+type backupUpgrade func(record *models.BackupRecord) error
+
+// backupUpgrades[v-1] converte registros da versão v para a v+1. A restauração aplica em sequência
+// as conversões da versão do arquivo até BackupVersion.
+var backupUpgrades = []backupUpgrade{}
+
+// maxBackupLine limita o tamanho de uma linha do arquivo de backup.
+const maxBackupLine = 16 << 20
+
+// ErrDatabaseNotEmpty indica que a restauração foi recusada porque o banco de destino já tem dados.
+var ErrDatabaseNotEmpty = errors.New("o banco de destino não está vazio")
+
+// BackupService gera e restaura backups (JSON lines) de todas as tabelas da universidade.
+// O formato do arquivo não depende do banco: um backup do PostgreSQL pode ser restaurado no SQLite
+// e vice-versa (ver repositories.BackupRepository).
+type BackupService struct {
+	repo *repositories.BackupRepository
+}
+
+// NewBackupService cria uma nova instância de BackupService.
+func NewBackupService(repo *repositories.BackupRepository) *BackupService {
+	return &BackupService{repo: repo}
+}
+
+// Backup grava em w um cabeçalho com a versão do formato, uma linha por registro de cada tabela
+// e um rodapé com as contagens. Todas as tabelas são lidas do mesmo instante (snapshot).
+func (s *BackupService) Backup(ctx context.Context, w io.Writer) (*models.BackupReport, error) {
+	ctx, span := tracing.Start(ctx, "BackupService.Backup")
+	defer span.End()
+	tx, err := s.repo.BeginSnapshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar leitura do backup: %w", err)
+	}
+	defer tx.Rollback() // Somente leitura: não há o que confirmar
+
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+	header := models.BackupHeader{Format: models.BackupFormat, Version: BackupVersion, CreatedAt: time.Now().UTC()}
+	for _, table := range repositories.BackupTables {
+		header.Tables = append(header.Tables, models.BackupTableEntry{Name: table.Name, Columns: table.Columns})
+	}
+	if err := encoder.Encode(header); err != nil {
+		return nil, err
+	}
+
+	report := &models.BackupReport{Version: BackupVersion, Counts: map[string]int{}}
+	for _, table := range repositories.BackupTables {
+		report.Counts[table.Name] = 0
+		err := s.repo.DumpTable(ctx, tx, table, func(row map[string]interface{}) error {
+			report.Counts[table.Name]++
+			return encoder.Encode(models.BackupRecord{Table: table.Name, Row: row})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("erro ao exportar tabela %s: %w", table.Name, err)
+		}
+	}
+	if err := encoder.Encode(models.BackupTrailer{Counts: report.Counts}); err != nil {
+		return nil, err
+	}
+	if err := out.Flush(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Restore carrega um backup em um banco vazio (com o esquema já criado), em uma única transação.
+// Antes de cada inserção verifica se o registro tem chave, se não é duplicado e se os registros que
+// ele referencia (ex: aluno e matéria de uma associação) já foram carregados. Backups de versões
+// anteriores são convertidos para o layout atual registro a registro; versões mais novas que
+// BackupVersion são recusadas. O arquivo precisa terminar com o rodapé e as contagens precisam
+// conferir; qualquer erro desfaz tudo.
+func (s *BackupService) Restore(ctx context.Context, r io.Reader) (*models.BackupReport, error) {
+	ctx, span := tracing.Start(ctx, "BackupService.Restore")
+	defer span.End()
+	lines := bufio.NewScanner(r)
+	lines.Buffer(make([]byte, 64*1024), maxBackupLine)
+	lineNumber := 1
+	if !lines.Scan() {
+		if err := lines.Err(); err != nil {
+			return nil, fmt.Errorf("erro ao ler backup: %w", err)
+		}
+		return nil, fmt.Errorf("%w: arquivo de backup vazio", ErrValidation)
+	}
+	var header models.BackupHeader
+	if err := json.Unmarshal(lines.Bytes(), &header); err != nil || header.Format != models.BackupFormat {
+		return nil, fmt.Errorf("%w: o arquivo não é um backup da universidade", ErrValidation)
+	}
+	if header.Version < 1 || header.Version > BackupVersion {
+		return nil, fmt.Errorf("%w: versão de backup %d não suportada (esta versão da API restaura as versões 1 a %d; atualize a API para restaurar backups mais novos)", ErrValidation, header.Version, BackupVersion)
+	}
+	tables, err := backupTablesFromHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	declared := map[string]bool{}
+	for _, entry := range header.Tables {
+		declared[entry.Name] = true
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação de restauração: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			if err := tx.Rollback(); err != nil {
+				logging.FromContext(ctx).Error("Restore: erro ao fazer rollback", "error", err)
+			}
+		}
+	}()
+	empty, err := s.repo.IsEmpty(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar banco de destino: %w", err)
+	}
+	if !empty {
+		return nil, ErrDatabaseNotEmpty
+	}
+
+	report := &models.BackupReport{Version: header.Version, Counts: map[string]int{}}
+	keys := map[string]map[string]bool{} // Tabela -> chaves já carregadas
+	var trailer *models.BackupTrailer
+	for lines.Scan() {
+		lineNumber++
+		if trailer != nil {
+			return nil, fmt.Errorf("%w: linha %d: dados depois do rodapé do backup", ErrValidation, lineNumber)
+		}
+		var line struct {
+			models.BackupRecord
+			Counts map[string]int `json:"counts"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(lines.Bytes()))
+		decoder.UseNumber()
+		if err := decoder.Decode(&line); err != nil {
+			return nil, fmt.Errorf("%w: linha %d: JSON inválido: %v", ErrValidation, lineNumber, err)
+		}
+		if line.Counts != nil {
+			trailer = &models.BackupTrailer{Counts: line.Counts}
+			continue
+		}
+
+		if !declared[line.Table] {
+			return nil, fmt.Errorf("%w: linha %d: tabela %q não declarada no cabeçalho", ErrValidation, lineNumber, line.Table)
+		}
+		report.Counts[line.Table]++ // O rodapé conta os registros do arquivo, antes da conversão
+		record := line.BackupRecord
+		for version := header.Version; version < BackupVersion; version++ {
+			if record.Table == "" {
+				break
+			}
+			if err := backupUpgrades[version-1](&record); err != nil {
+				return nil, fmt.Errorf("%w: linha %d (%s): conversão da versão %d: %v", ErrValidation, lineNumber, line.Table, version, err)
+			}
+		}
+		if record.Table == "" {
+			continue // Registro descartado pela conversão
+		}
+		table, ok := tables[record.Table]
+		if !ok {
+			return nil, fmt.Errorf("%w: linha %d: tabela desconhecida: %s", ErrValidation, lineNumber, record.Table)
+		}
+		row, key, err := checkBackupRow(table, record.Row, keys)
+		if err != nil {
+			return nil, fmt.Errorf("%w: linha %d (%s): %v", ErrValidation, lineNumber, table.Name, err)
+		}
+		if err := s.repo.InsertRow(ctx, tx, table, row); err != nil {
+			return nil, fmt.Errorf("linha %d (%s): erro ao inserir: %w", lineNumber, table.Name, err)
+		}
+		if keys[table.Name] == nil {
+			keys[table.Name] = map[string]bool{}
+		}
+		keys[table.Name][key] = true
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler backup (linha %d): %w", lineNumber+1, err)
+	}
+	if trailer == nil {
+		return nil, fmt.Errorf("%w: rodapé ausente, o arquivo de backup está incompleto", ErrValidation)
+	}
+	for name := range declared {
+		if trailer.Counts[name] != report.Counts[name] {
+			return nil, fmt.Errorf("%w: o rodapé indica %d registros em %s, mas o arquivo tem %d", ErrValidation, trailer.Counts[name], name, report.Counts[name])
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar restauração: %w", err)
+	}
+	committed = true
+	logging.FromContext(ctx).Info("Restore: backup restaurado", "version", header.Version, "counts", report.Counts)
+	return report, nil
+}
+
+// backupTablesFromHeader devolve as tabelas do esquema atual por nome. Em um backup da versão atual
+// também confere as tabelas e colunas declaradas no arquivo, recusando as desconhecidas; em versões
+// anteriores o cabeçalho descreve o layout antigo e os registros são conferidos depois da conversão.
+func backupTablesFromHeader(header models.BackupHeader) (map[string]repositories.BackupTable, error) {
+	known := map[string]repositories.BackupTable{}
+	for _, table := range repositories.BackupTables {
+		known[table.Name] = table
+	}
+	if header.Version != BackupVersion {
+		return known, nil
+	}
+	tables := map[string]repositories.BackupTable{}
+	for _, entry := range header.Tables {
+		table, ok := known[entry.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: tabela desconhecida no backup: %s", ErrValidation, entry.Name)
+		}
+		columns := map[string]bool{}
+		for _, column := range table.Columns {
+			columns[column] = true
+		}
+		for _, column := range entry.Columns {
+			if !columns[column] {
+				return nil, fmt.Errorf("%w: coluna desconhecida no backup: %s.%s", ErrValidation, entry.Name, column)
+			}
+		}
+		tables[entry.Name] = table
+	}
+	return tables, nil
+}
+
+// checkBackupRow valida uma linha do backup e converte os números para tipos nativos.
+// Devolve a linha convertida e sua chave primária (colunas unidas por \x00).
+func checkBackupRow(table repositories.BackupTable, raw map[string]interface{}, keys map[string]map[string]bool) (map[string]interface{}, string, error) {
+	row := make(map[string]interface{}, len(raw))
+	for column, value := range raw {
+		if !containsString(table.Columns, column) {
+			return nil, "", fmt.Errorf("coluna desconhecida: %s", column)
+		}
+		if n, ok := value.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				value = i
+			} else if f, err := n.Float64(); err == nil {
+				value = f
+			}
+		}
+		row[column] = value
+	}
+
+	parts := make([]string, len(table.Key))
+	for i, column := range table.Key {
+		value := row[column]
+		if value == nil || value == "" {
+			return nil, "", fmt.Errorf("chave %s ausente", column)
+		}
+		parts[i] = fmt.Sprint(value) // Chaves compostas podem ter colunas numéricas (ex: ano)
+	}
+	key := strings.Join(parts, "\x00")
+	if keys[table.Name][key] {
+		return nil, "", fmt.Errorf("registro duplicado: %s", strings.Join(parts, "/"))
+	}
+	for column, target := range table.References {
+		if row[column] == nil {
+			continue // Referência opcional (ex: aluno sem curso)
+		}
+		id, _ := row[column].(string)
+		if !keys[target][id] {
+			return nil, "", fmt.Errorf("%s %q não existe em %s", column, id, target)
+		}
+	}
+	return row, key, nil
+}
+
+// containsString informa se s está em list.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
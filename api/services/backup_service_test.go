@@ -0,0 +1,249 @@
+// api/services/backup_service_test.go
+package services
+
+import (
+	"bytes"
+	"college_api/models"
+	"college_api/repositories"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// backupLayout é o layout de repositories.BackupTables na versão backupLayoutVersion. Se o teste
+// falhar porque uma tabela ou coluna mudou, incremente BackupVersion e atualize as duas constantes.
+const backupLayoutVersion = 1
+
+const backupLayout = `subjects: id, name, year, credits, version, deleted_at
+departments: id, code, name, head_teacher_id, version, deleted_at
+teachers: id, registry, name, department_id, department, version, deleted_at
+programs: id, name, version
+program_subjects: program_id, subject_id, kind
+program_year_requirements: program_id, year, min_credits
+students: id, enrollment, name, current_year, shift, graduating, status, program_id, version, deleted_at
+student_status_history: id, student_id, from_status, to_status, reason, effective_date, actor, recorded_at
+student_subjects: student_id, subject_id`
+
+func TestBackupVersionMatchesLayout(t *testing.T) {
+	var lines []string
+	for _, table := range repositories.BackupTables {
+		lines = append(lines, table.Name+": "+strings.Join(table.Columns, ", "))
+	}
+	if got := strings.Join(lines, "\n"); got != backupLayout || BackupVersion != backupLayoutVersion {
+		t.Fatalf("o layout do backup mudou sem incrementar BackupVersion (%d); layout atual:\n%s", BackupVersion, got)
+	}
+}
+
+func TestBackupUpgradesCoverEveryVersion(t *testing.T) {
+	if len(backupUpgrades) != BackupVersion-1 {
+		t.Fatalf("backupUpgrades tem %d conversões, esperava %d (uma para cada versão anterior a %d)", len(backupUpgrades), BackupVersion-1, BackupVersion)
+	}
+}
+
+func TestRestoreRejectsUnsupportedBackupVersion(t *testing.T) {
+	for _, version := range []int{0, BackupVersion + 1} {
+		header, _ := json.Marshal(models.BackupHeader{Format: models.BackupFormat, Version: version})
+		// A versão é conferida antes de qualquer acesso ao banco
+		_, err := NewBackupService(nil).Restore(context.Background(), strings.NewReader(string(header)+"\n"))
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("versão %d: esperava ErrValidation, veio %v", version, err)
+		}
+	}
+}
+
+func TestBackupRestoreRoundTripInMemory(t *testing.T) {
+	// Em ordem de chave, a mesma em que o backup grava os registros
+	source := openMemDB(t, map[string][]map[string]driver.Value{
+		"subjects": {
+			{"id": "FIS101", "name": "Física I", "year": int64(1), "credits": int64(4), "version": int64(2), "deleted_at": "2026-01-02T03:04:05Z"},
+			{"id": "MAT101", "name": "Cálculo I", "year": int64(1), "credits": int64(4), "version": int64(1), "deleted_at": nil},
+		},
+		"departments": {
+			{"id": "d1", "code": "MAT", "name": "Matemática", "head_teacher_id": "t1", "version": int64(1), "deleted_at": nil},
+		},
+		"teachers": {
+			{"id": "t1", "registry": "P001", "name": "Ana", "department_id": "d1", "department": "Matemática", "version": int64(1), "deleted_at": nil},
+		},
+		"students": {
+			{"id": "s1", "enrollment": "2026001", "name": "Bia", "current_year": int64(1), "shift": "morning", "graduating": false, "status": "active", "program_id": nil, "version": int64(1), "deleted_at": nil},
+		},
+		"student_subjects": {
+			{"student_id": "s1", "subject_id": "MAT101"},
+		},
+	})
+	var buf bytes.Buffer
+	if _, err := NewBackupService(repositories.NewBackupRepository(source.db)).Backup(context.Background(), &buf); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+
+	target := openMemDB(t, nil)
+	report, err := NewBackupService(repositories.NewBackupRepository(target.db)).Restore(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if report.Counts["subjects"] != 2 || report.Counts["student_subjects"] != 1 {
+		t.Errorf("contagens inesperadas: %v", report.Counts)
+	}
+	for _, table := range repositories.BackupTables {
+		if got, want := target.rows(table.Name), source.rows(table.Name); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s restaurada difere da original:\n%v\n%v", table.Name, got, want)
+		}
+	}
+
+	// Um banco que já tem dados não é sobrescrito
+	buf.Reset()
+	NewBackupService(repositories.NewBackupRepository(source.db)).Backup(context.Background(), &buf)
+	if _, err := NewBackupService(repositories.NewBackupRepository(target.db)).Restore(context.Background(), &buf); !errors.Is(err, ErrDatabaseNotEmpty) {
+		t.Errorf("esperava ErrDatabaseNotEmpty, veio %v", err)
+	}
+}
+
+// memDB é um banco em memória (driver database/sql) que entende só o SQL do backup: SELECT de
+// colunas com ORDER BY, SELECT EXISTS e INSERT com parâmetros ?. Serve para provar que backup e
+// restauração não dependem do PostgreSQL.
+type memDB struct {
+	db     *sql.DB
+	mu     sync.Mutex
+	tables map[string][]map[string]driver.Value
+}
+
+func openMemDB(t *testing.T, tables map[string][]map[string]driver.Value) *memDB {
+	t.Helper()
+	if tables == nil {
+		tables = map[string][]map[string]driver.Value{}
+	}
+	m := &memDB{tables: tables}
+	m.db = sql.OpenDB(m)
+	t.Cleanup(func() { m.db.Close() })
+	return m
+}
+
+func (m *memDB) rows(table string) []map[string]driver.Value {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]map[string]driver.Value(nil), m.tables[table]...)
+}
+
+func (m *memDB) Connect(context.Context) (driver.Conn, error) { return &memConn{m: m}, nil }
+func (m *memDB) Driver() driver.Driver                        { return memDriver{} }
+
+type memDriver struct{}
+
+func (memDriver) Open(string) (driver.Conn, error) { return nil, errors.New("memDB: use sql.OpenDB") }
+
+type memConn struct {
+	m        *memDB
+	snapshot map[string][]map[string]driver.Value // Estado no início da transação, para o rollback
+}
+
+func (c *memConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("memDB: Prepare não suportado")
+}
+func (c *memConn) Close() error { return nil }
+func (c *memConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *memConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.m.mu.Lock()
+	defer c.m.mu.Unlock()
+	c.snapshot = map[string][]map[string]driver.Value{}
+	for name, rows := range c.m.tables {
+		c.snapshot[name] = append([]map[string]driver.Value(nil), rows...)
+	}
+	return c, nil
+}
+
+func (c *memConn) Commit() error { c.snapshot = nil; return nil }
+
+func (c *memConn) Rollback() error {
+	c.m.mu.Lock()
+	defer c.m.mu.Unlock()
+	if c.snapshot != nil {
+		c.m.tables, c.snapshot = c.snapshot, nil
+	}
+	return nil
+}
+
+var (
+	memSelect = regexp.MustCompile(`^SELECT (.+) FROM (\w+) ORDER BY (.+)$`)
+	memExists = regexp.MustCompile(`^SELECT EXISTS \(SELECT 1 FROM (\w+)\)$`)
+	memInsert = regexp.MustCompile(`^INSERT INTO (\w+) \((.+)\) VALUES \((.+)\)$`)
+)
+
+func (c *memConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.m.mu.Lock()
+	defer c.m.mu.Unlock()
+	if match := memExists.FindStringSubmatch(query); match != nil {
+		return &memRows{columns: []string{"exists"}, values: [][]driver.Value{{len(c.m.tables[match[1]]) > 0}}}, nil
+	}
+	match := memSelect.FindStringSubmatch(query)
+	if match == nil {
+		return nil, fmt.Errorf("memDB: consulta não suportada: %s", query)
+	}
+	columns, keys := strings.Split(match[1], ", "), strings.Split(match[3], ", ")
+	rows := append([]map[string]driver.Value(nil), c.m.tables[match[2]]...)
+	sort.Slice(rows, func(i, j int) bool {
+		for _, key := range keys {
+			if a, b := fmt.Sprint(rows[i][key]), fmt.Sprint(rows[j][key]); a != b {
+				return a < b
+			}
+		}
+		return false
+	})
+	result := &memRows{columns: columns}
+	for _, row := range rows {
+		values := make([]driver.Value, len(columns))
+		for i, column := range columns {
+			values[i] = row[column]
+		}
+		result.values = append(result.values, values)
+	}
+	return result, nil
+}
+
+func (c *memConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	match := memInsert.FindStringSubmatch(query)
+	if match == nil {
+		return nil, fmt.Errorf("memDB: comando não suportado: %s", query)
+	}
+	columns := strings.Split(match[2], ", ")
+	if strings.Count(match[3], "?") != len(columns) || len(args) != len(columns) {
+		return nil, fmt.Errorf("memDB: parâmetros não conferem: %s", query)
+	}
+	row := map[string]driver.Value{}
+	for i, column := range columns {
+		row[column] = args[i].Value
+	}
+	c.m.mu.Lock()
+	defer c.m.mu.Unlock()
+	c.m.tables[match[1]] = append(c.m.tables[match[1]], row)
+	return driver.RowsAffected(1), nil
+}
+
+type memRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *memRows) Columns() []string { return r.columns }
+func (r *memRows) Close() error      { return nil }
+
+func (r *memRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
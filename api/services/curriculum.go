@@ -0,0 +1,236 @@
+// services/curriculum.go
+package services
+
+import (
+	"bytes"
+	"college_api/cache"
+	"college_api/logging"
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/tracing"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurriculumSyncOptions controla a sincronização da grade curricular.
+type CurriculumSyncOptions struct {
+	Prune  bool // Exclui (logicamente) as matérias do catálogo que não estão na grade
+	DryRun bool // Apenas calcula o diff, sem gravar nada
+}
+
+// ParseCurriculum lê uma grade curricular em JSON (format "json") ou YAML (qualquer outro valor).
+// Campos desconhecidos são rejeitados, para que erros de digitação não passem despercebidos.
+func ParseCurriculum(data []byte, format string) (*models.Curriculum, error) {
+	var curriculum models.Curriculum
+	if strings.EqualFold(format, "json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&curriculum); err != nil {
+			return nil, fmt.Errorf("%w: grade curricular inválida: %v", ErrValidation, err)
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&curriculum); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%w: grade curricular inválida: %v", ErrValidation, err)
+		}
+	}
+	if len(curriculum.Subjects) == 0 {
+		return nil, fmt.Errorf("%w: a grade curricular não tem matérias", ErrValidation)
+	}
+	return &curriculum, nil
+}
+
+// SyncCurriculum sincroniza o catálogo de matérias com a grade curricular: cria as matérias novas,
+// restaura as excluídas logicamente, atualiza as que mudaram e, com Prune, exclui as que não estão na grade.
+// O diff é calculado e aplicado em uma única transação. Se Prune encontrar matérias ainda associadas
+// a alunos ou incluídas na grade de algum curso, elas são listadas em Blocked e nada é gravado.
+func (s *SubjectService) SyncCurriculum(ctx context.Context, curriculum *models.Curriculum, opts CurriculumSyncOptions) (*models.CurriculumSyncReport, error) {
+	ctx, span := tracing.Start(ctx, "SubjectService.SyncCurriculum")
+	defer span.End()
+	wanted, err := validateCurriculum(curriculum)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação de sincronização: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			if err := tx.Rollback(); err != nil {
+				logging.FromContext(ctx).Error("SyncCurriculum: erro ao fazer rollback", "error", err)
+			}
+		}
+	}()
+	repo := s.repo.WithTx(tx)
+
+	report, err := diffCurriculum(ctx, repo, wanted, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun || len(report.Blocked) > 0 {
+		return report, nil
+	}
+
+	for i := range report.Restored {
+		subject := &report.Restored[i]
+		if err := repo.RestoreSubject(ctx, subject.ID); err != nil {
+			return nil, fmt.Errorf("erro ao restaurar matéria %s: %w", subject.ID, err)
+		}
+		if err := repo.UpdateSubject(ctx, subject); err != nil {
+			return nil, fmt.Errorf("erro ao atualizar matéria restaurada %s: %w", subject.ID, err)
+		}
+	}
+	for i := range report.Added {
+		if err := repo.CreateSubject(ctx, &report.Added[i]); err != nil {
+			return nil, fmt.Errorf("erro ao criar matéria %s: %w", report.Added[i].ID, err)
+		}
+	}
+	for _, change := range report.Changed {
+		subject := wanted[change.ID]
+		if err := repo.UpdateSubject(ctx, &subject); err != nil {
+			return nil, fmt.Errorf("erro ao atualizar matéria %s: %w", change.ID, err)
+		}
+	}
+	if opts.Prune {
+		for _, subject := range report.Removed {
+			if err := repo.DeleteSubject(ctx, subject.ID, 0); err != nil {
+				return nil, fmt.Errorf("erro ao excluir matéria %s: %w", subject.ID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar sincronização: %w", err)
+	}
+	committed = true
+	cache.Invalidate(ctx, s.cache)
+	report.Applied = true
+	logging.FromContext(ctx).Info("SyncCurriculum: grade sincronizada",
+		"added", len(report.Added), "restored", len(report.Restored), "changed", len(report.Changed), "removed", len(report.Removed))
+	return report, nil
+}
+
+// validateCurriculum valida cada matéria da grade e devolve um mapa ID -> matéria.
+// Todos os problemas são reunidos em uma única mensagem.
+func validateCurriculum(curriculum *models.Curriculum) (map[string]models.Subject, error) {
+	wanted := map[string]models.Subject{}
+	var problems []string
+	for i, item := range curriculum.Subjects {
+		subject := models.Subject{ID: strings.TrimSpace(item.ID), Name: strings.TrimSpace(item.Name), Year: item.Year, Credits: item.Credits}
+		if subject.ID == "" {
+			problems = append(problems, fmt.Sprintf("matéria %d: id é obrigatório", i+1))
+			continue
+		}
+		if _, dup := wanted[subject.ID]; dup {
+			problems = append(problems, fmt.Sprintf("matéria %s: id repetido na grade", subject.ID))
+			continue
+		}
+		if err := validateSubject(&subject); err != nil {
+			problems = append(problems, fmt.Sprintf("matéria %s: %s", subject.ID, strings.TrimPrefix(err.Error(), ErrValidation.Error()+": ")))
+		}
+		wanted[subject.ID] = subject
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, strings.Join(problems, "; "))
+	}
+	return wanted, nil
+}
+
+// diffCurriculum compara a grade com o catálogo atual (lido por repo, já dentro da transação).
+func diffCurriculum(ctx context.Context, repo *repositories.SubjectRepository, wanted map[string]models.Subject, opts CurriculumSyncOptions) (*models.CurriculumSyncReport, error) {
+	current, err := repo.GetAllSubjects(ctx, models.SubjectFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar matérias: %w", err)
+	}
+	report := &models.CurriculumSyncReport{
+		DryRun:   opts.DryRun,
+		Prune:    opts.Prune,
+		Added:    []models.Subject{},
+		Restored: []models.Subject{},
+		Changed:  []models.SubjectChange{},
+		Removed:  []models.Subject{},
+	}
+
+	existing := map[string]models.Subject{}
+	for _, subject := range current {
+		existing[subject.ID] = subject
+		if _, ok := wanted[subject.ID]; !ok {
+			report.Removed = append(report.Removed, subject)
+		}
+	}
+
+	for _, id := range sortedKeys(wanted) {
+		subject := wanted[id]
+		old, ok := existing[id]
+		if !ok {
+			// O ID pode pertencer a uma matéria excluída logicamente: nesse caso ela é restaurada
+			inUse, err := repo.SubjectIDExists(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao verificar matéria %s: %w", id, err)
+			}
+			if inUse {
+				report.Restored = append(report.Restored, subject)
+			} else {
+				report.Added = append(report.Added, subject)
+			}
+			continue
+		}
+		changes := map[string]models.FieldChange{}
+		if old.Name != subject.Name {
+			changes["name"] = models.FieldChange{From: old.Name, To: subject.Name}
+		}
+		if old.Year != subject.Year {
+			changes["year"] = models.FieldChange{From: old.Year, To: subject.Year}
+		}
+		if old.Credits != subject.Credits {
+			changes["credits"] = models.FieldChange{From: old.Credits, To: subject.Credits}
+		}
+		if len(changes) == 0 {
+			report.Unchanged++
+			continue
+		}
+		report.Changed = append(report.Changed, models.SubjectChange{ID: id, Changes: changes})
+	}
+	sort.Slice(report.Removed, func(i, j int) bool { return report.Removed[i].ID < report.Removed[j].ID })
+
+	if opts.Prune && len(report.Removed) > 0 {
+		ids := make([]string, len(report.Removed))
+		for i, subject := range report.Removed {
+			ids[i] = subject.ID
+		}
+		counts, err := repo.CountStudentsBySubject(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao verificar alunos associados: %w", err)
+		}
+		programs, err := repo.ProgramsBySubject(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao verificar grades dos cursos: %w", err)
+		}
+		for _, subject := range report.Removed {
+			if counts[subject.ID] > 0 || len(programs[subject.ID]) > 0 {
+				report.Blocked = append(report.Blocked, models.SubjectReference{ID: subject.ID, Name: subject.Name, Students: counts[subject.ID], Programs: programs[subject.ID]})
+			}
+		}
+	}
+	return report, nil
+}
+
+// sortedKeys devolve as chaves do mapa em ordem, para que o diff seja estável.
+func sortedKeys(m map[string]models.Subject) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,162 @@
+// services/department_service.go
+package services
+
+import (
+	"college_api/cache"
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// departmentCodePattern é o formato do código do departamento, usado como prefixo dos registros dos professores.
+var departmentCodePattern = regexp.MustCompile(`^[A-Z0-9]{2,8}$`)
+
+// DepartmentService define as operações de negócio para departamentos.
+type DepartmentService struct {
+	repo         *repositories.DepartmentRepository
+	teacherRepo  *repositories.TeacherRepository
+	teacherCache cache.Cache // Cache de professores: eles exibem o nome do departamento
+}
+
+// NewDepartmentService cria uma nova instância de DepartmentService. teacherCache é o cache de leitura
+// de TeacherService (nil: desabilitado), limpo quando um departamento muda de nome.
+func NewDepartmentService(repo *repositories.DepartmentRepository, tr *repositories.TeacherRepository, teacherCache cache.Cache) *DepartmentService {
+	return &DepartmentService{repo: repo, teacherRepo: tr, teacherCache: teacherCache}
+}
+
+// CreateDepartment cria um departamento. O código é normalizado para maiúsculas e não pode repetir
+// o de outro departamento, nem de um excluído (os registros já emitidos continuam usando-o).
+func (s *DepartmentService) CreateDepartment(ctx context.Context, department *models.Department) error {
+	ctx, span := tracing.Start(ctx, "DepartmentService.CreateDepartment")
+	defer span.End()
+	department.Code = strings.ToUpper(strings.TrimSpace(department.Code))
+	if !departmentCodePattern.MatchString(department.Code) {
+		return fmt.Errorf("%w: código do departamento inválido: %q (use de 2 a 8 letras ou dígitos)", ErrValidation, department.Code)
+	}
+	department.ID = uuid.New().String()
+	if err := s.validateDepartment(ctx, department); err != nil {
+		return err
+	}
+	exists, err := s.repo.CodeExists(ctx, department.Code)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar código do departamento: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("%w: já existe um departamento com o código %s", ErrConflict, department.Code)
+	}
+	return s.repo.CreateDepartment(ctx, department)
+}
+
+// GetDepartmentByID busca um departamento pelo ID.
+func (s *DepartmentService) GetDepartmentByID(ctx context.Context, id string) (*models.Department, error) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.GetDepartmentByID")
+	defer span.End()
+	department, err := s.repo.GetDepartmentByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar departamento: %w", err)
+	}
+	if department == nil {
+		return nil, fmt.Errorf("%w: departamento %s", ErrNotFound, id)
+	}
+	return department, nil
+}
+
+// GetAllDepartments busca todos os departamentos ativos.
+func (s *DepartmentService) GetAllDepartments(ctx context.Context) ([]models.Department, error) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.GetAllDepartments")
+	defer span.End()
+	return s.repo.GetAllDepartments(ctx)
+}
+
+// UpdateDepartment atualiza o nome e o chefe do departamento. O código é imutável: se vier
+// preenchido, precisa ser o atual. department.Version é a versão que o cliente leu (0 dispensa a verificação).
+func (s *DepartmentService) UpdateDepartment(ctx context.Context, department *models.Department) error {
+	ctx, span := tracing.Start(ctx, "DepartmentService.UpdateDepartment")
+	defer span.End()
+	existing, err := s.GetDepartmentByID(ctx, department.ID)
+	if err != nil {
+		return err
+	}
+	code := strings.ToUpper(strings.TrimSpace(department.Code))
+	if code != "" && code != existing.Code {
+		return fmt.Errorf("%w: o código do departamento não pode ser alterado (atual: %s)", ErrValidation, existing.Code)
+	}
+	if err := s.validateDepartment(ctx, department); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateDepartment(ctx, department); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: departamento %s", ErrNotFound, department.ID)
+		}
+		return err
+	}
+	if department.Name != existing.Name {
+		cache.Invalidate(ctx, s.teacherCache)
+	}
+	return nil
+}
+
+// DeleteDepartment exclui logicamente um departamento sem professores ativos (senão ErrConflict).
+// expectedVersion 0 dispensa a verificação de versão.
+func (s *DepartmentService) DeleteDepartment(ctx context.Context, id string, expectedVersion int) error {
+	ctx, span := tracing.Start(ctx, "DepartmentService.DeleteDepartment")
+	defer span.End()
+	count, err := s.repo.CountTeachersInDepartment(ctx, id)
+	if err != nil {
+		return fmt.Errorf("erro ao contar professores do departamento: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: o departamento tem %d professores ativos", ErrConflict, count)
+	}
+	if err := s.repo.DeleteDepartment(ctx, id, expectedVersion); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: departamento %s", ErrNotFound, id)
+		}
+		return err
+	}
+	return nil
+}
+
+// RestoreDepartment restaura um departamento excluído logicamente.
+func (s *DepartmentService) RestoreDepartment(ctx context.Context, id string) (*models.Department, error) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.RestoreDepartment")
+	defer span.End()
+	if err := s.repo.RestoreDepartment(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: nenhum departamento excluído com ID %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("erro ao restaurar departamento: %w", err)
+	}
+	return s.GetDepartmentByID(ctx, id)
+}
+
+// validateDepartment confere o nome e, se informado, o chefe: um professor ativo do próprio departamento.
+func (s *DepartmentService) validateDepartment(ctx context.Context, department *models.Department) error {
+	department.Name = strings.TrimSpace(department.Name)
+	if department.Name == "" {
+		return fmt.Errorf("%w: nome do departamento é obrigatório", ErrValidation)
+	}
+	department.HeadTeacherID = strings.TrimSpace(department.HeadTeacherID)
+	if department.HeadTeacherID == "" {
+		return nil
+	}
+	head, err := s.teacherRepo.GetTeacherByID(ctx, department.HeadTeacherID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar chefe do departamento: %w", err)
+	}
+	if head == nil {
+		return fmt.Errorf("%w: professor %s não encontrado para chefiar o departamento", ErrValidation, department.HeadTeacherID)
+	}
+	if head.DepartmentID != department.ID {
+		return fmt.Errorf("%w: o chefe do departamento precisa ser um professor do próprio departamento", ErrValidation)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+// services/errors.go
+package services
+
+import (
+	"college_api/repositories"
+	"errors"
+)
+
+// Erros sentinela dos serviços. Os handlers usam errors.Is para escolher o status HTTP.
+var (
+	// ErrValidation indica que a entrada foi rejeitada pelas regras de negócio (400).
+	ErrValidation = errors.New("dados inválidos")
+	// ErrNotFound indica que o registro procurado não existe (404).
+	ErrNotFound = errors.New("registro não encontrado")
+	// ErrConflict indica que a operação não pode ser feita no estado atual do registro (409).
+	ErrConflict = errors.New("conflito com o estado atual")
+	// ErrVersionConflict indica que a versão informada (If-Match) não é a atual (412).
+	ErrVersionConflict = repositories.ErrVersionConflict
+)
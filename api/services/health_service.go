@@ -0,0 +1,74 @@
+// services/health_service.go
+package services
+
+import (
+	"college_api/buildinfo"
+	"college_api/config"
+	"college_api/logging"
+	"college_api/models"
+	"college_api/repositories"
+	"context"
+	"fmt"
+	"time"
+)
+
+// readinessTimeout limita o tempo das verificações de prontidão, para que um banco travado
+// responda 503 em vez de segurar a sonda da plataforma.
+const readinessTimeout = 2 * time.Second
+
+// HealthService define as verificações de saúde e a identificação da versão da API.
+type HealthService struct {
+	repo        *repositories.HealthRepository
+	reconnector *config.Reconnector
+}
+
+// NewHealthService cria uma nova instância de HealthService. reconnector conclui a inicialização
+// do banco, se ela tiver falhado, quando a plataforma consultar a prontidão.
+func NewHealthService(repo *repositories.HealthRepository, reconnector *config.Reconnector) *HealthService {
+	return &HealthService{repo: repo, reconnector: reconnector}
+}
+
+// Readiness verifica se o banco responde e se as migrações desta versão do código foram aplicadas.
+func (s *HealthService) Readiness(ctx context.Context) *models.Readiness {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	report := &models.Readiness{Status: models.HealthOK, Checks: map[string]string{}}
+	fail := func(check, reason string) {
+		report.Status = models.HealthUnavailable
+		report.Checks[check] = reason
+		logging.FromContext(ctx).Warn("Readiness: verificação falhou", "check", check, "reason", reason)
+	}
+
+	// Dá ao banco a chance de concluir a inicialização; as verificações abaixo mostram o resultado.
+	_ = s.reconnector.Ensure(ctx)
+
+	if err := s.repo.Ping(ctx); err != nil {
+		fail("database", err.Error())
+		fail("migrations", "não verificado: banco indisponível")
+		return report
+	}
+	report.Checks["database"] = models.HealthOK
+
+	version, err := s.repo.SchemaVersion(ctx)
+	switch {
+	case err != nil:
+		fail("migrations", err.Error())
+	case version != config.SchemaVersion:
+		fail("migrations", fmt.Sprintf("esquema na versão %d, esperada %d", version, config.SchemaVersion))
+	default:
+		report.Checks["migrations"] = models.HealthOK
+	}
+	return report
+}
+
+// Version identifica o código em execução e a versão do esquema que ele espera.
+func (s *HealthService) Version() models.VersionInfo {
+	info := buildinfo.Get()
+	return models.VersionInfo{
+		Commit:        info.Commit,
+		BuildTime:     info.BuildTime,
+		GoVersion:     info.GoVersion,
+		SchemaVersion: config.SchemaVersion,
+	}
+}
@@ -0,0 +1,48 @@
+// services/patch.go
+package services
+
+import (
+	"bytes"
+	"college_api/mergepatch"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// applyPatch aplica um JSON Merge Patch ao registro current e decodifica o resultado em merged.
+// O patch precisa ser um objeto JSON; campos desconhecidos e alterações em campos imutáveis
+// (identificados pela tag JSON) são rejeitados com ErrValidation.
+func applyPatch(current interface{}, patch []byte, merged interface{}, immutable ...string) error {
+	if trimmed := bytes.TrimSpace(patch); len(trimmed) == 0 || trimmed[0] != '{' {
+		return fmt.Errorf("%w: o merge patch deve ser um objeto JSON", ErrValidation)
+	}
+
+	original, err := json.Marshal(current)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar registro atual: %w", err)
+	}
+	result, err := mergepatch.Apply(original, patch)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(original, &before); err != nil {
+		return fmt.Errorf("erro ao interpretar registro atual: %w", err)
+	}
+	if err := json.Unmarshal(result, &after); err != nil {
+		return fmt.Errorf("erro ao interpretar registro mesclado: %w", err)
+	}
+	for _, field := range immutable {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			return fmt.Errorf("%w: o campo %s não pode ser alterado", ErrValidation, field)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(result))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(merged); err != nil {
+		return fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	return nil
+}
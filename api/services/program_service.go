@@ -0,0 +1,274 @@
+// services/program_service.go
+package services
+
+import (
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ProgramService define as operações de negócio para cursos e a auditoria de formatura.
+type ProgramService struct {
+	programRepo *repositories.ProgramRepository
+	studentRepo *repositories.StudentRepository
+	subjectRepo *repositories.SubjectRepository
+}
+
+// NewProgramService cria uma nova instância de ProgramService.
+func NewProgramService(pr *repositories.ProgramRepository, sr *repositories.StudentRepository, subR *repositories.SubjectRepository) *ProgramService {
+	return &ProgramService{programRepo: pr, studentRepo: sr, subjectRepo: subR}
+}
+
+// CreateProgram cria um curso depois de validar sua grade.
+func (s *ProgramService) CreateProgram(ctx context.Context, program *models.Program) error {
+	ctx, span := tracing.Start(ctx, "ProgramService.CreateProgram")
+	defer span.End()
+	program.ID = strings.TrimSpace(program.ID)
+	if program.ID == "" {
+		return fmt.Errorf("%w: código do curso é obrigatório", ErrValidation)
+	}
+	if err := s.validateProgram(ctx, program); err != nil {
+		return err
+	}
+	existing, err := s.programRepo.GetProgramByID(ctx, program.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar curso existente: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("%w: curso com o código %s já existe", ErrConflict, program.ID)
+	}
+	if err := s.programRepo.CreateProgram(ctx, program); err != nil {
+		return err
+	}
+	return s.reload(ctx, program)
+}
+
+// GetProgramByID busca um curso pelo código.
+func (s *ProgramService) GetProgramByID(ctx context.Context, id string) (*models.Program, error) {
+	ctx, span := tracing.Start(ctx, "ProgramService.GetProgramByID")
+	defer span.End()
+	program, err := s.programRepo.GetProgramByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar curso: %w", err)
+	}
+	if program == nil {
+		return nil, fmt.Errorf("%w: curso %s", ErrNotFound, id)
+	}
+	return program, nil
+}
+
+// GetAllPrograms busca todos os cursos.
+func (s *ProgramService) GetAllPrograms(ctx context.Context) ([]models.Program, error) {
+	ctx, span := tracing.Start(ctx, "ProgramService.GetAllPrograms")
+	defer span.End()
+	return s.programRepo.GetAllPrograms(ctx)
+}
+
+// UpdateProgram substitui o nome, a grade e os mínimos por ano do curso.
+// program.Version é a versão que o cliente leu (0 dispensa a verificação).
+func (s *ProgramService) UpdateProgram(ctx context.Context, program *models.Program) error {
+	ctx, span := tracing.Start(ctx, "ProgramService.UpdateProgram")
+	defer span.End()
+	if err := s.validateProgram(ctx, program); err != nil {
+		return err
+	}
+	if err := s.programRepo.UpdateProgram(ctx, program); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: curso %s", ErrNotFound, program.ID)
+		}
+		return err
+	}
+	return s.reload(ctx, program)
+}
+
+// DeleteProgram remove um curso sem alunos vinculados (senão ErrConflict).
+// expectedVersion 0 dispensa a verificação de versão.
+func (s *ProgramService) DeleteProgram(ctx context.Context, id string, expectedVersion int) error {
+	ctx, span := tracing.Start(ctx, "ProgramService.DeleteProgram")
+	defer span.End()
+	count, err := s.programRepo.CountStudentsInProgram(ctx, id)
+	if err != nil {
+		return fmt.Errorf("erro ao contar alunos do curso: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: o curso %s tem %d alunos vinculados", ErrConflict, id, count)
+	}
+	if err := s.programRepo.DeleteProgram(ctx, id, expectedVersion); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: curso %s", ErrNotFound, id)
+		}
+		return err
+	}
+	return nil
+}
+
+// reload substitui program pelo estado gravado (com nome, ano e créditos das matérias).
+func (s *ProgramService) reload(ctx context.Context, program *models.Program) error {
+	saved, err := s.programRepo.GetProgramByID(ctx, program.ID)
+	if err != nil || saved == nil {
+		return err
+	}
+	*program = *saved
+	return nil
+}
+
+// validateProgram confere nome, tipos e existência das matérias (sem repetição) e os mínimos por ano.
+func (s *ProgramService) validateProgram(ctx context.Context, program *models.Program) error {
+	program.Name = strings.TrimSpace(program.Name)
+	if program.Name == "" {
+		return fmt.Errorf("%w: nome do curso é obrigatório", ErrValidation)
+	}
+	seen := map[string]bool{}
+	for i := range program.Subjects {
+		subject := &program.Subjects[i]
+		subject.SubjectID = strings.TrimSpace(subject.SubjectID)
+		subject.Kind = strings.ToLower(strings.TrimSpace(subject.Kind))
+		if subject.Kind != models.ProgramSubjectRequired && subject.Kind != models.ProgramSubjectElective {
+			return fmt.Errorf("%w: tipo inválido para a matéria %s: %q (use required ou elective)", ErrValidation, subject.SubjectID, subject.Kind)
+		}
+		if seen[subject.SubjectID] {
+			return fmt.Errorf("%w: matéria %s repetida na grade", ErrValidation, subject.SubjectID)
+		}
+		seen[subject.SubjectID] = true
+		existing, err := s.subjectRepo.GetSubjectByID(ctx, subject.SubjectID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar matéria %s: %w", subject.SubjectID, err)
+		}
+		if existing == nil {
+			return fmt.Errorf("%w: matéria %s não encontrada", ErrValidation, subject.SubjectID)
+		}
+	}
+	years := map[int]bool{}
+	for _, requirement := range program.YearRequirements {
+		if requirement.Year < 1 || requirement.MinCredits < 0 {
+			return fmt.Errorf("%w: créditos mínimos inválidos para o ano %d: %d", ErrValidation, requirement.Year, requirement.MinCredits)
+		}
+		if years[requirement.Year] {
+			return fmt.Errorf("%w: ano %d repetido nos créditos mínimos", ErrValidation, requirement.Year)
+		}
+		years[requirement.Year] = true
+	}
+	if program.Subjects == nil {
+		program.Subjects = []models.ProgramSubject{}
+	}
+	if program.YearRequirements == nil {
+		program.YearRequirements = []models.ProgramYearRequirement{}
+	}
+	return nil
+}
+
+// AssignStudentProgram vincula o aluno a um curso existente (programID vazio desvincula).
+// expectedVersion 0 dispensa a verificação de versão.
+func (s *ProgramService) AssignStudentProgram(ctx context.Context, studentID, programID string, expectedVersion int) (*models.Student, error) {
+	ctx, span := tracing.Start(ctx, "ProgramService.AssignStudentProgram")
+	defer span.End()
+	programID = strings.TrimSpace(programID)
+	if programID != "" {
+		program, err := s.programRepo.GetProgramByID(ctx, programID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar curso: %w", err)
+		}
+		if program == nil {
+			return nil, fmt.Errorf("%w: curso %s não encontrado", ErrValidation, programID)
+		}
+	}
+	if _, err := s.studentRepo.SetStudentProgram(ctx, studentID, programID, expectedVersion); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: aluno com ID %s", ErrNotFound, studentID)
+		}
+		return nil, err
+	}
+	return s.studentRepo.GetStudentByID(ctx, studentID)
+}
+
+// DegreeAudit compara as matérias associadas ao aluno (consideradas cursadas) com a grade do seu curso:
+// obrigatórias cumpridas e pendentes, optativas cursadas e disponíveis, e créditos por ano contra o mínimo.
+// Só matérias da grade contam créditos; as demais aparecem em OutsideProgram.
+// Alunos sem curso recebem ErrConflict.
+func (s *ProgramService) DegreeAudit(ctx context.Context, studentID string) (*models.DegreeAudit, error) {
+	ctx, span := tracing.Start(ctx, "ProgramService.DegreeAudit")
+	defer span.End()
+	student, err := s.studentRepo.GetStudentByID(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar aluno: %w", err)
+	}
+	if student == nil {
+		return nil, fmt.Errorf("%w: aluno com ID %s", ErrNotFound, studentID)
+	}
+	if student.ProgramID == "" {
+		return nil, fmt.Errorf("%w: o aluno não está vinculado a um curso", ErrConflict)
+	}
+	program, err := s.programRepo.GetProgramByID(ctx, student.ProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar curso: %w", err)
+	}
+	if program == nil {
+		return nil, fmt.Errorf("%w: curso %s", ErrNotFound, student.ProgramID)
+	}
+	return buildDegreeAudit(student, program), nil
+}
+
+// buildDegreeAudit monta a auditoria de formatura a partir do aluno (com matérias) e do curso.
+func buildDegreeAudit(student *models.Student, program *models.Program) *models.DegreeAudit {
+	audit := &models.DegreeAudit{
+		StudentID:          student.ID,
+		Enrollment:         student.Enrollment,
+		StudentName:        student.Name,
+		ProgramID:          program.ID,
+		ProgramName:        program.Name,
+		RequiredCompleted:  []models.DegreeAuditSubject{},
+		RequiredRemaining:  []models.DegreeAuditSubject{},
+		ElectivesCompleted: []models.DegreeAuditSubject{},
+		ElectivesAvailable: []models.DegreeAuditSubject{},
+		Years:              []models.DegreeAuditYear{},
+		OutsideProgram:     []models.DegreeAuditSubject{},
+	}
+
+	taken := map[string]bool{}
+	for _, subject := range student.Subjects {
+		taken[subject.ID] = true
+	}
+	inProgram := map[string]bool{}
+	earnedByYear := map[int]int{}
+	for _, subject := range program.Subjects {
+		inProgram[subject.SubjectID] = true
+		item := models.DegreeAuditSubject{SubjectID: subject.SubjectID, Name: subject.Name, Year: subject.Year, Credits: subject.Credits}
+		switch {
+		case taken[subject.SubjectID]:
+			audit.EarnedCredits += subject.Credits
+			earnedByYear[subject.Year] += subject.Credits
+			if subject.Kind == models.ProgramSubjectRequired {
+				audit.RequiredCompleted = append(audit.RequiredCompleted, item)
+			} else {
+				audit.ElectivesCompleted = append(audit.ElectivesCompleted, item)
+			}
+		case subject.Kind == models.ProgramSubjectRequired:
+			audit.RequiredRemaining = append(audit.RequiredRemaining, item)
+		default:
+			audit.ElectivesAvailable = append(audit.ElectivesAvailable, item)
+		}
+	}
+	for _, subject := range student.Subjects {
+		if !inProgram[subject.ID] {
+			audit.OutsideProgram = append(audit.OutsideProgram, models.DegreeAuditSubject{SubjectID: subject.ID, Name: subject.Name, Year: subject.Year, Credits: subject.Credits})
+		}
+	}
+	sort.Slice(audit.OutsideProgram, func(i, j int) bool { return audit.OutsideProgram[i].SubjectID < audit.OutsideProgram[j].SubjectID })
+
+	audit.Complete = len(audit.RequiredRemaining) == 0
+	for _, requirement := range program.YearRequirements {
+		year := models.DegreeAuditYear{Year: requirement.Year, MinCredits: requirement.MinCredits, EarnedCredits: earnedByYear[requirement.Year]}
+		if year.EarnedCredits < year.MinCredits {
+			year.RemainingCredits = year.MinCredits - year.EarnedCredits
+			audit.Complete = false
+		}
+		audit.Years = append(audit.Years, year)
+	}
+	return audit
+}
@@ -0,0 +1,50 @@
+// services/purge_service.go
+package services
+
+import (
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/tracing"
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultPurgeRetention é por quanto tempo registros excluídos logicamente ficam disponíveis para restauração.
+const DefaultPurgeRetention = 90 * 24 * time.Hour
+
+// PurgeService remove definitivamente registros excluídos logicamente há mais tempo que a retenção.
+type PurgeService struct {
+	studentRepo *repositories.StudentRepository
+	subjectRepo *repositories.SubjectRepository
+	teacherRepo *repositories.TeacherRepository
+}
+
+// NewPurgeService cria uma nova instância de PurgeService.
+func NewPurgeService(sr *repositories.StudentRepository, subR *repositories.SubjectRepository, tr *repositories.TeacherRepository) *PurgeService {
+	return &PurgeService{studentRepo: sr, subjectRepo: subR, teacherRepo: tr}
+}
+
+// Purge remove os registros excluídos antes de agora menos retention.
+// Alunos são expurgados primeiro para que suas associações deixem de segurar as matérias;
+// matérias ainda associadas a alunos são mantidas e listadas no relatório.
+func (s *PurgeService) Purge(ctx context.Context, retention time.Duration) (*models.PurgeReport, error) {
+	ctx, span := tracing.Start(ctx, "PurgeService.Purge")
+	defer span.End()
+	if retention < 0 {
+		return nil, fmt.Errorf("%w: retenção não pode ser negativa", ErrValidation)
+	}
+	report := &models.PurgeReport{Cutoff: time.Now().Add(-retention).UTC()}
+
+	var err error
+	if report.Students, err = s.studentRepo.PurgeDeletedStudents(ctx, report.Cutoff); err != nil {
+		return nil, fmt.Errorf("erro ao expurgar alunos: %w", err)
+	}
+	if report.Subjects, report.SkippedSubjects, err = s.subjectRepo.PurgeDeletedSubjects(ctx, report.Cutoff); err != nil {
+		return nil, fmt.Errorf("erro ao expurgar matérias: %w", err)
+	}
+	if report.Teachers, err = s.teacherRepo.PurgeDeletedTeachers(ctx, report.Cutoff); err != nil {
+		return nil, fmt.Errorf("erro ao expurgar professores: %w", err)
+	}
+	return report, nil
+}
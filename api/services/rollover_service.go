@@ -0,0 +1,265 @@
+// services/rollover_service.go
+package services
+
+import (
+	"college_api/logging"
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/tracing"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultRolloverUndoWindow é por quanto tempo uma virada de ano letivo pode ser desfeita.
+const DefaultRolloverUndoWindow = 72 * time.Hour
+
+// DefaultRolloverCriteria é o critério usado quando a requisição não informa outro:
+// 75% dos créditos do ano atual e curso de quatro anos.
+var DefaultRolloverCriteria = models.RolloverCriteria{MinCreditShare: 0.75, FinalYear: 4}
+
+// RolloverService executa a virada de ano letivo: promove os alunos que atingiram o critério
+// e marca como formandos os que concluíram o último ano.
+type RolloverService struct {
+	studentRepo  *repositories.StudentRepository
+	rolloverRepo *repositories.RolloverRepository
+	undoWindow   time.Duration
+}
+
+// NewRolloverService cria uma nova instância de RolloverService.
+// undoWindow é por quanto tempo cada virada pode ser desfeita.
+func NewRolloverService(sr *repositories.StudentRepository, rr *repositories.RolloverRepository, undoWindow time.Duration) *RolloverService {
+	return &RolloverService{studentRepo: sr, rolloverRepo: rr, undoWindow: undoWindow}
+}
+
+// Run avalia todos os alunos e, sem dryRun, grava a virada em uma única transação.
+// Cada aluno alterado passa por UpdateStudent (versão e auditoria), e o estado anterior fica
+// registrado para Undo. Com dryRun, tudo é calculado e desfeito, devolvendo apenas a prévia.
+func (s *RolloverService) Run(ctx context.Context, criteria models.RolloverCriteria, dryRun bool) (*models.RolloverReport, error) {
+	ctx, span := tracing.Start(ctx, "RolloverService.Run")
+	defer span.End()
+	if err := validateRolloverCriteria(criteria); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.studentRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação da virada: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			if err := tx.Rollback(); err != nil {
+				logging.FromContext(ctx).Error("Run: erro ao fazer rollback da virada", "error", err)
+			}
+		}
+	}()
+	studentRepo := s.studentRepo.WithTx(tx)
+	rolloverRepo := s.rolloverRepo.WithTx(tx)
+
+	candidates, err := rolloverRepo.ListCandidates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar alunos: %w", err)
+	}
+
+	report := &models.RolloverReport{DryRun: dryRun, Criteria: criteria, Students: make([]models.RolloverStudent, 0, len(candidates))}
+	for _, c := range candidates {
+		result := evaluateRolloverCandidate(c, criteria)
+		switch result.Outcome {
+		case models.RolloverPromoted:
+			report.Promoted++
+		case models.RolloverGraduating:
+			report.Graduating++
+		case models.RolloverRetained:
+			report.Retained++
+		case models.RolloverSkipped:
+			report.Skipped++
+		}
+		report.Students = append(report.Students, result)
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	runID, executedAt, err := rolloverRepo.CreateRun(ctx, criteria, s.undoWindow)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao registrar virada: %w", err)
+	}
+	for i, result := range report.Students {
+		if result.Outcome != models.RolloverPromoted && result.Outcome != models.RolloverGraduating {
+			continue
+		}
+		c := candidates[i]
+		student, err := studentRepo.GetStudentByID(ctx, c.StudentID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar aluno %s: %w", c.StudentID, err)
+		}
+		student.CurrentYear = result.ToYear
+		student.Graduating = result.Outcome == models.RolloverGraduating
+		student.Version = c.Version
+		if err := studentRepo.UpdateStudent(ctx, student); err != nil {
+			return nil, fmt.Errorf("erro ao atualizar aluno %s: %w", c.StudentID, err)
+		}
+		change := models.RolloverChange{
+			StudentID:      c.StudentID,
+			FromYear:       c.CurrentYear,
+			ToYear:         student.CurrentYear,
+			FromGraduating: c.Graduating,
+			ToGraduating:   student.Graduating,
+			VersionAfter:   student.Version,
+		}
+		if err := rolloverRepo.AddChange(ctx, runID, change); err != nil {
+			return nil, fmt.Errorf("erro ao registrar alteração do aluno %s: %w", c.StudentID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar virada: %w", err)
+	}
+	committed = true
+	deadline := executedAt.Add(s.undoWindow)
+	report.ID = runID
+	report.ExecutedAt = &executedAt
+	report.UndoDeadline = &deadline
+	logging.FromContext(ctx).Info("Run: virada de ano letivo concluída", "run_id", runID, "promoted", report.Promoted, "graduating", report.Graduating, "retained", report.Retained)
+	return report, nil
+}
+
+// evaluateRolloverCandidate decide o resultado de um aluno segundo o critério.
+func evaluateRolloverCandidate(c models.RolloverCandidate, criteria models.RolloverCriteria) models.RolloverStudent {
+	result := models.RolloverStudent{
+		StudentID:     c.StudentID,
+		Enrollment:    c.Enrollment,
+		Name:          c.Name,
+		FromYear:      c.CurrentYear,
+		ToYear:        c.CurrentYear,
+		EarnedCredits: c.EarnedCredits,
+		TotalCredits:  c.TotalCredits,
+	}
+	if c.TotalCredits > 0 {
+		result.CreditShare = float64(c.EarnedCredits) / float64(c.TotalCredits)
+	}
+
+	switch {
+	case c.Graduating:
+		result.Outcome = models.RolloverSkipped
+		result.Reason = "aluno já marcado como formando"
+	case c.TotalCredits == 0:
+		result.Outcome = models.RolloverRetained
+		result.Reason = fmt.Sprintf("nenhuma matéria cadastrada para o ano %d", c.CurrentYear)
+	case result.CreditShare < criteria.MinCreditShare:
+		result.Outcome = models.RolloverRetained
+		result.Reason = fmt.Sprintf("%.0f%% dos créditos do ano, mínimo de %.0f%%", result.CreditShare*100, criteria.MinCreditShare*100)
+	case c.CurrentYear >= criteria.FinalYear:
+		result.Outcome = models.RolloverGraduating
+	default:
+		result.Outcome = models.RolloverPromoted
+		result.ToYear = c.CurrentYear + 1
+	}
+	return result
+}
+
+// validateRolloverCriteria garante que a fração mínima esteja em (0, 1] e que o último ano seja positivo.
+func validateRolloverCriteria(criteria models.RolloverCriteria) error {
+	if criteria.MinCreditShare <= 0 || criteria.MinCreditShare > 1 {
+		return fmt.Errorf("%w: min_credit_share deve estar entre 0 (exclusive) e 1", ErrValidation)
+	}
+	if criteria.FinalYear < 1 {
+		return fmt.Errorf("%w: final_year deve ser maior que zero", ErrValidation)
+	}
+	return nil
+}
+
+// ListRuns devolve as viradas mais recentes.
+func (s *RolloverService) ListRuns(ctx context.Context, limit int) ([]models.RolloverRun, error) {
+	ctx, span := tracing.Start(ctx, "RolloverService.ListRuns")
+	defer span.End()
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.rolloverRepo.ListRuns(ctx, limit)
+}
+
+// Undo desfaz uma virada, devolvendo cada aluno alterado ao ano e à marca de formando anteriores.
+// Só a virada ativa mais recente pode ser desfeita, dentro da janela, e somente se nenhum dos
+// alunos tiver sido alterado depois dela (senão ErrConflict, listando os alunos).
+func (s *RolloverService) Undo(ctx context.Context, id int64) (*models.RolloverRun, error) {
+	ctx, span := tracing.Start(ctx, "RolloverService.Undo")
+	defer span.End()
+	tx, err := s.rolloverRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			if err := tx.Rollback(); err != nil {
+				logging.FromContext(ctx).Error("Undo: erro ao fazer rollback", "error", err)
+			}
+		}
+	}()
+	studentRepo := s.studentRepo.WithTx(tx)
+	rolloverRepo := s.rolloverRepo.WithTx(tx)
+
+	run, err := rolloverRepo.LockRun(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar virada: %w", err)
+	}
+	if run == nil {
+		return nil, fmt.Errorf("%w: virada %d", ErrNotFound, id)
+	}
+	if run.UndoneAt != nil {
+		return nil, fmt.Errorf("%w: a virada %d já foi desfeita", ErrConflict, id)
+	}
+	if time.Now().After(run.UndoDeadline) {
+		return nil, fmt.Errorf("%w: o prazo para desfazer a virada %d terminou em %s", ErrConflict, id, run.UndoDeadline.UTC().Format(time.RFC3339))
+	}
+	later, err := rolloverRepo.HasLaterActiveRun(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar viradas posteriores: %w", err)
+	}
+	if later {
+		return nil, fmt.Errorf("%w: existe uma virada posterior à %d; desfaça-a primeiro", ErrConflict, id)
+	}
+
+	changes, err := rolloverRepo.ListChanges(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar alterações da virada: %w", err)
+	}
+	var changed []string
+	students := make([]*models.Student, len(changes))
+	for i, c := range changes {
+		student, err := studentRepo.GetStudentByID(ctx, c.StudentID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar aluno %s: %w", c.StudentID, err)
+		}
+		if student == nil || student.Version != c.VersionAfter {
+			changed = append(changed, c.StudentID)
+			continue
+		}
+		students[i] = student
+	}
+	if len(changed) > 0 {
+		return nil, fmt.Errorf("%w: alunos alterados ou excluídos depois da virada: %s", ErrConflict, strings.Join(changed, ", "))
+	}
+
+	for i, c := range changes {
+		student := students[i]
+		student.CurrentYear = c.FromYear
+		student.Graduating = c.FromGraduating
+		if err := studentRepo.UpdateStudent(ctx, student); err != nil {
+			return nil, fmt.Errorf("erro ao restaurar aluno %s: %w", c.StudentID, err)
+		}
+	}
+	if err := rolloverRepo.MarkUndone(ctx, id); err != nil {
+		return nil, fmt.Errorf("erro ao marcar virada como desfeita: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar desfazer da virada: %w", err)
+	}
+	committed = true
+
+	logging.FromContext(ctx).Info("Undo: virada de ano letivo desfeita", "run_id", id, "restored", len(changes))
+	return s.rolloverRepo.GetRun(ctx, id)
+}
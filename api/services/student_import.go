@@ -0,0 +1,237 @@
+// services/student_import.go
+package services
+
+import (
+	"bytes"
+	"college_api/logging"
+	"college_api/metrics"
+	"college_api/models"
+	"college_api/repositories"
+	"college_api/tracing"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxImportRows limita quantas linhas de dados um arquivo de importação pode ter.
+const MaxImportRows = 5000
+
+// importColumns associa os nomes de coluna aceitos (em inglês ou português) ao campo correspondente.
+var importColumns = map[string]string{
+	"name":         "name",
+	"nome":         "name",
+	"shift":        "shift",
+	"turno":        "shift",
+	"current_year": "current_year",
+	"ano":          "current_year",
+	"ano_atual":    "current_year",
+	"subjects":     "subjects",
+	"subject_ids":  "subjects",
+	"materias":     "subjects",
+	"matérias":     "subjects",
+}
+
+// studentImportLine é uma linha do CSV já interpretada.
+type studentImportLine struct {
+	line    int
+	student models.Student
+	errors  []string
+}
+
+// ImportStudentsCSV importa alunos de um CSV com as colunas name, shift, current_year e,
+// opcionalmente, subjects (IDs separados por ";", "|", "," ou espaço).
+// Cada linha passa pelas mesmas validações e pela mesma geração de matrícula de CreateStudent
+// (validateNewStudent), todas dentro de uma única unidade de trabalho: se qualquer linha tiver erro,
+// nada é gravado. Se uma criação simultânea ficar com uma das matrículas geradas, o lote inteiro é
+// refeito com a sequência relida, como em CreateStudent; esgotadas as tentativas, o erro é ErrConflict.
+// Com dryRun, tudo é executado e desfeito no final, devolvendo as matrículas que seriam geradas.
+func (s *StudentService) ImportStudentsCSV(ctx context.Context, r io.Reader, dryRun bool) (*models.StudentImportReport, error) {
+	ctx, span := tracing.Start(ctx, "StudentService.ImportStudentsCSV")
+	defer span.End()
+	parsed, err := parseStudentCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var report *models.StudentImportReport
+	for attempt := 1; ; attempt++ {
+		report, err = s.importStudents(ctx, parsed, dryRun)
+		if errors.Is(err, repositories.ErrEnrollmentTaken) {
+			if attempt < maxEnrollmentAttempts {
+				metrics.EnrollmentRetries.Inc()
+				logging.FromContext(ctx).Warn("ImportStudentsCSV: matrícula já utilizada, refazendo a importação", "attempt", attempt)
+				continue
+			}
+			return nil, fmt.Errorf("%w: %v; tente importar novamente", ErrConflict, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	if !report.Committed {
+		for i := range report.Rows {
+			report.Rows[i].StudentID = "" // Nada foi gravado: o ID não existe
+			if !dryRun {
+				report.Rows[i].Enrollment = ""
+			}
+		}
+		return report, nil
+	}
+
+	for i := range report.Rows {
+		report.Rows[i].Status = models.ImportRowCreated
+	}
+	metrics.StudentsCreated.Add(float64(report.Valid))
+	logging.FromContext(ctx).Info("ImportStudentsCSV: alunos importados", "count", report.Valid)
+	return report, nil
+}
+
+// importStudents faz uma tentativa de importação das linhas interpretadas, em uma unidade de trabalho.
+// As linhas não são alteradas, para que a tentativa possa ser repetida.
+func (s *StudentService) importStudents(ctx context.Context, parsed []studentImportLine, dryRun bool) (*models.StudentImportReport, error) {
+	report := &models.StudentImportReport{DryRun: dryRun, TotalRows: len(parsed), Rows: make([]models.StudentImportRow, 0, len(parsed))}
+	err := s.uow.DoBatch(ctx, func(repos *repositories.TxRepositories) error {
+		for _, line := range parsed {
+			student := line.student
+			rowErrors := append([]string(nil), line.errors...)
+
+			// Mesmas regras de CreateStudent; linhas com erro de formato também são conferidas, para
+			// que o relatório traga todos os problemas de uma vez
+			if err := validateNewStudent(ctx, repos, &student); err != nil {
+				var invalid *NewStudentError
+				if !errors.As(err, &invalid) {
+					return fmt.Errorf("linha %d: %w", line.line, err)
+				}
+				rowErrors = append(rowErrors, invalid.Messages()...)
+			}
+
+			row := models.StudentImportRow{Line: line.line, Name: student.Name, Errors: rowErrors}
+			if len(rowErrors) > 0 {
+				row.Status = models.ImportRowError
+				report.Invalid++
+				report.Rows = append(report.Rows, row)
+				continue
+			}
+
+			// Erros de banco abortam a transação no PostgreSQL, então interrompem a tentativa inteira
+			if err := repos.Students.CreateStudent(ctx, &student); err != nil {
+				return fmt.Errorf("linha %d: erro ao gravar aluno: %w", line.line, err)
+			}
+			row.Status = models.ImportRowValid
+			row.StudentID = student.ID
+			row.Enrollment = student.Enrollment
+			report.Valid++
+			report.Rows = append(report.Rows, row)
+		}
+		if dryRun || report.Invalid > 0 {
+			return repositories.ErrRollback
+		}
+		report.Committed = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// parseStudentCSV lê o arquivo e converte cada linha em um aluno, anotando erros de formato por linha.
+// O separador (vírgula ou ponto e vírgula, comum no Excel em português) é detectado pelo cabeçalho.
+func parseStudentCSV(r io.Reader) ([]studentImportLine, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM UTF-8 do Excel
+
+	headerLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		headerLine = data[:i]
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(headerLine, []byte(";")) > bytes.Count(headerLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: arquivo vazio", ErrValidation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: cabeçalho inválido: %v", ErrValidation, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		field, ok := importColumns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("%w: coluna desconhecida no cabeçalho: %q", ErrValidation, name)
+		}
+		columns[field] = i
+	}
+	for _, required := range []string{"name", "shift", "current_year"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: coluna obrigatória ausente no cabeçalho: %s", ErrValidation, required)
+		}
+	}
+
+	var lines []studentImportLine
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				lines = append(lines, studentImportLine{line: parseErr.StartLine, errors: []string{parseErr.Err.Error()}})
+				continue
+			}
+			return nil, fmt.Errorf("erro ao ler arquivo: %w", err)
+		}
+		lineNumber, _ := reader.FieldPos(0)
+		if len(lines) >= MaxImportRows {
+			return nil, fmt.Errorf("%w: o arquivo excede o limite de %d linhas", ErrValidation, MaxImportRows)
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		line := studentImportLine{line: lineNumber}
+		line.student.Name = field("name")
+		line.student.Shift = field("shift")
+		if year := field("current_year"); year != "" {
+			n, err := strconv.Atoi(year)
+			if err != nil || n <= 0 {
+				line.errors = append(line.errors, fmt.Sprintf("ano atual inválido: %q", year))
+			}
+			line.student.CurrentYear = n
+		}
+		seen := map[string]bool{}
+		for _, id := range strings.FieldsFunc(field("subjects"), func(r rune) bool {
+			return r == ';' || r == '|' || r == ',' || r == ' '
+		}) {
+			if !seen[id] {
+				seen[id] = true
+				line.student.Subjects = append(line.student.Subjects, models.Subject{ID: id})
+			}
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: o arquivo não tem linhas de dados", ErrValidation)
+	}
+	return lines, nil
+}
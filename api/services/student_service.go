@@ -2,11 +2,16 @@
 package services
 
 import (
+	"college_api/cache"
+	"college_api/logging"
+	"college_api/metrics"
 	"college_api/models"
 	"college_api/repositories"
+	"college_api/tracing"
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -14,28 +19,123 @@ import (
 
 // StudentService define as operações de negócio para alunos.
 type StudentService struct {
-	studentRepo *repositories.StudentRepository
-	subjectRepo *repositories.SubjectRepository
+	studentRepo  *repositories.StudentRepository
+	subjectRepo  *repositories.SubjectRepository
+	uow          *repositories.UnitOfWork
+	subjectCache cache.Cache // Cache de leitura de SubjectService (nil: desabilitado)
 }
 
-// NewStudentService cria uma nova instância de StudentService.
-func NewStudentService(sr *repositories.StudentRepository, subR *repositories.SubjectRepository) *StudentService {
-	return &StudentService{studentRepo: sr, subjectRepo: subR}
+// NewStudentService cria uma nova instância de StudentService. subjectCache é o cache de leitura de
+// SubjectService (nil desabilita), usado ao associar matérias a alunos.
+func NewStudentService(sr *repositories.StudentRepository, subR *repositories.SubjectRepository, uow *repositories.UnitOfWork, subjectCache cache.Cache) *StudentService {
+	return &StudentService{studentRepo: sr, subjectRepo: subR, uow: uow, subjectCache: subjectCache}
 }
 
-// CreateStudent cria um novo aluno com matrícula gerada automaticamente.
-func (s *StudentService) CreateStudent(student *models.Student) error {
+// NewStudentError lista todos os problemas encontrados na criação de um aluno (nome, turno e
+// matérias inexistentes), para que o cliente corrija tudo de uma vez.
+// errors.Is(err, ErrValidation) é verdadeiro para ele.
+type NewStudentError struct {
+	Problems   []string // Campos inválidos (nome, turno)
+	SubjectIDs []string // Matérias inexistentes
+}
+
+// Messages devolve um texto por problema, sem o prefixo de ErrValidation.
+func (e *NewStudentError) Messages() []string {
+	messages := append([]string(nil), e.Problems...)
+	if len(e.SubjectIDs) > 0 {
+		messages = append(messages, "matérias não encontradas: "+strings.Join(e.SubjectIDs, ", "))
+	}
+	return messages
+}
+
+func (e *NewStudentError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrValidation, strings.Join(e.Messages(), "; "))
+}
+
+func (e *NewStudentError) Unwrap() error {
+	return ErrValidation
+}
+
+// maxEnrollmentAttempts limita as tentativas de alocar a matrícula quando criações simultâneas
+// geram o mesmo número.
+const maxEnrollmentAttempts = 5
+
+// CreateStudent cria um novo aluno com matrícula gerada automaticamente, junto com suas matérias,
+// em uma única transação: se o nome, o turno ou alguma matéria forem inválidos, nada é gravado e o
+// erro é um *NewStudentError com todos os problemas. Se outra criação simultânea ficar com a mesma
+// matrícula, a sequência é relida e a criação tentada de novo.
+func (s *StudentService) CreateStudent(ctx context.Context, student *models.Student) error {
+	ctx, span := tracing.Start(ctx, "StudentService.CreateStudent")
+	defer span.End()
+	for attempt := 1; ; attempt++ {
+		err := s.uow.Do(ctx, func(repos *repositories.TxRepositories) error {
+			if err := validateNewStudent(ctx, repos, student); err != nil {
+				return err
+			}
+			return repos.Students.CreateStudent(ctx, student)
+		})
+		if errors.Is(err, repositories.ErrEnrollmentTaken) && attempt < maxEnrollmentAttempts {
+			metrics.EnrollmentRetries.Inc()
+			logging.FromContext(ctx).Warn("CreateStudent: matrícula já utilizada, tentando novamente", "enrollment", student.Enrollment, "attempt", attempt)
+			continue
+		}
+		if err != nil {
+			student.ID = "" // Nada foi gravado
+			if errors.Is(err, repositories.ErrSubjectNotFound) {
+				// Matéria excluída entre a verificação e a associação
+				return fmt.Errorf("%w: %v", ErrValidation, err)
+			}
+			return err
+		}
+		metrics.StudentsCreated.Inc()
+		return nil
+	}
+}
+
+// validateNewStudent aplica as regras de criação de um aluno com os repositórios da transação: nome
+// obrigatório, turno válido e matérias existentes. Todos os campos são conferidos antes de devolver o
+// *NewStudentError; só um aluno válido passa por prepareNewStudent.
+// É usada por CreateStudent e pela importação, para que os dois caminhos de criação não divirjam.
+func validateNewStudent(ctx context.Context, repos *repositories.TxRepositories, student *models.Student) error {
+	invalid := &NewStudentError{}
+
+	// 0. Validar o nome
+	student.Name = strings.TrimSpace(student.Name)
+	if student.Name == "" {
+		invalid.Problems = append(invalid.Problems, "nome do aluno é obrigatório")
+	}
+
 	// 1. Validar o turno (Shift)
 	student.Shift = strings.ToUpper(student.Shift)
-	if student.Shift != "M" && student.Shift != "T" && student.Shift != "N" {
-		return fmt.Errorf("turno inválido: %s. Deve ser 'M' (Manhã), 'T' (Tarde) ou 'N' (Noite)", student.Shift)
+	if err := validateShift(student.Shift); err != nil {
+		invalid.Problems = append(invalid.Problems, strings.TrimPrefix(err.Error(), ErrValidation.Error()+": "))
+	}
+
+	// 2. Validar as matérias
+	for _, subject := range student.Subjects {
+		existing, err := repos.Subjects.GetSubjectByID(ctx, subject.ID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar matéria %s: %w", subject.ID, err)
+		}
+		if existing == nil {
+			invalid.SubjectIDs = append(invalid.SubjectIDs, subject.ID)
+		}
 	}
+	if len(invalid.Problems) > 0 || len(invalid.SubjectIDs) > 0 {
+		return invalid
+	}
+	return prepareNewStudent(ctx, repos.Students, student)
+}
 
+// prepareNewStudent gera a matrícula de um aluno novo já validado por validateNewStudent.
+// studentRepo pode estar vinculado a uma transação (WithTx), para que a sequência enxergue
+// os alunos inseridos antes na mesma transação.
+func prepareNewStudent(ctx context.Context, studentRepo *repositories.StudentRepository, student *models.Student) error {
 	// 2. Obter o ano atual
 	currentYear := time.Now().Year()
 
 	// 3. Buscar a última matrícula para o ano e turno atuais
-	lastEnrollment, err := s.studentRepo.GetLastEnrollmentForYearAndShift(currentYear, student.Shift)
+	lastEnrollment, err := studentRepo.GetLastEnrollmentForYearAndShift(ctx, currentYear, student.Shift)
 	if err != nil {
 		return fmt.Errorf("erro ao buscar última matrícula: %v", err)
 	}
@@ -49,7 +149,7 @@ func (s *StudentService) CreateStudent(student *models.Student) error {
 			if err == nil {
 				newSequence = lastSequence + 1
 			} else {
-				log.Printf("Aviso: Não foi possível converter sequência '%s' para int. Reiniciando sequência para 1. Erro: %v", seqStr, err)
+				logging.FromContext(ctx).Warn("prepareNewStudent: sequência de matrícula inválida, reiniciando em 1", "sequence", seqStr, "error", err)
 			}
 		}
 	}
@@ -61,74 +161,210 @@ func (s *StudentService) CreateStudent(student *models.Student) error {
 	if student.CurrentYear == 0 {
 		student.CurrentYear = 1
 	}
-
-	return s.studentRepo.CreateStudent(student)
+	return nil
 }
 
 // GetStudentByID busca um aluno pelo ID.
-func (s *StudentService) GetStudentByID(id string) (*models.Student, error) {
-	return s.studentRepo.GetStudentByID(id)
+func (s *StudentService) GetStudentByID(ctx context.Context, id string) (*models.Student, error) {
+	ctx, span := tracing.Start(ctx, "StudentService.GetStudentByID")
+	defer span.End()
+	return s.studentRepo.GetStudentByID(ctx, id)
+}
+
+// GetAllStudents busca todos os alunos que atendem ao filtro.
+func (s *StudentService) GetAllStudents(ctx context.Context, filter models.StudentFilter) ([]models.Student, error) {
+	ctx, span := tracing.Start(ctx, "StudentService.GetAllStudents")
+	defer span.End()
+	if err := normalizeStudentFilter(&filter); err != nil {
+		return nil, err
+	}
+	return s.studentRepo.GetAllStudents(ctx, filter)
+}
+
+// StreamStudents percorre os alunos que atendem ao filtro (com suas matérias), chamando fn para cada um.
+// Usado pelas exportações, que não devem carregar a lista inteira em memória.
+func (s *StudentService) StreamStudents(ctx context.Context, filter models.StudentFilter, fn func(models.Student) error) error {
+	ctx, span := tracing.Start(ctx, "StudentService.StreamStudents")
+	defer span.End()
+	if err := normalizeStudentFilter(&filter); err != nil {
+		return err
+	}
+	return s.studentRepo.StreamStudents(ctx, filter, fn)
 }
 
-// GetAllStudents busca todos os alunos.
-func (s *StudentService) GetAllStudents() ([]models.Student, error) {
-	return s.studentRepo.GetAllStudents()
+// normalizeStudentFilter valida o filtro de alunos, padronizando o turno em maiúsculas.
+// Sem situação informada, apenas alunos ativos são listados; "all" inclui todas as situações.
+func normalizeStudentFilter(filter *models.StudentFilter) error {
+	filter.Name = strings.TrimSpace(filter.Name)
+	if filter.Shift != "" {
+		filter.Shift = strings.ToUpper(filter.Shift)
+		if err := validateShift(filter.Shift); err != nil {
+			return err
+		}
+	}
+	if filter.CurrentYear < 0 {
+		return fmt.Errorf("%w: ano atual inválido: %d", ErrValidation, filter.CurrentYear)
+	}
+	switch filter.Status = strings.ToLower(strings.TrimSpace(filter.Status)); filter.Status {
+	case "":
+		filter.Status = models.StudentActive // Alunos inativos só aparecem quando pedidos
+	case "all":
+		filter.Status = ""
+	default:
+		if err := validateStudentStatus(filter.Status); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // UpdateStudent atualiza um aluno existente.
-func (s *StudentService) UpdateStudent(student *models.Student) error {
+// student.Version é a versão que o cliente leu (0 dispensa a verificação); em caso de sucesso,
+// student recebe o estado gravado, incluindo a nova versão.
+func (s *StudentService) UpdateStudent(ctx context.Context, student *models.Student) error {
+	ctx, span := tracing.Start(ctx, "StudentService.UpdateStudent")
+	defer span.End()
 	if student.ID == "" {
-		return errors.New("ID do aluno é obrigatório para atualização")
+		return fmt.Errorf("%w: ID do aluno é obrigatório para atualização", ErrValidation)
 	}
 	if student.Name == "" || student.CurrentYear == 0 || student.Shift == "" { // CORRIGIDO: Valida o Shift aqui também
-		return errors.New("nome, ano atual e turno do aluno são obrigatórios para atualização")
+		return fmt.Errorf("%w: nome, ano atual e turno do aluno são obrigatórios para atualização", ErrValidation)
 	}
 
-	existingStudent, err := s.studentRepo.GetStudentByID(student.ID)
+	existingStudent, err := s.studentRepo.GetStudentByID(ctx, student.ID)
 	if err != nil {
 		return fmt.Errorf("erro ao buscar aluno existente para atualização: %w", err)
 	}
 	if existingStudent == nil {
-		return fmt.Errorf("aluno com ID %s não encontrado para atualização", student.ID)
+		return fmt.Errorf("%w: aluno com ID %s", ErrNotFound, student.ID)
 	}
 
 	// ATUALIZADO: Copia os campos atualizáveis do 'student' (DTO de entrada) para 'existingStudent'
 	existingStudent.Name = student.Name
 	existingStudent.CurrentYear = student.CurrentYear
 	existingStudent.Shift = strings.ToUpper(student.Shift) // CORRIGIDO: Copia o turno e garante maiúscula
+	existingStudent.Version = student.Version
+	if err := validateShift(existingStudent.Shift); err != nil {
+		return err
+	}
 	// A matrícula (Enrollment) é gerada na criação e não deve ser alterada aqui.
 	// Ela já é parte do 'existingStudent' buscado do DB.
 
-	return s.studentRepo.UpdateStudent(existingStudent)
+	if err := s.studentRepo.UpdateStudent(ctx, existingStudent); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { // Excluído por outra requisição desde a busca
+			return fmt.Errorf("%w: aluno com ID %s", ErrNotFound, student.ID)
+		}
+		return err
+	}
+	*student = *existingStudent
+	return nil
+}
+
+// PatchStudent aplica um JSON Merge Patch (RFC 7396) ao aluno e devolve o estado gravado.
+// ID, matrícula, versão, matérias, situação e curso (que têm rotas próprias) e a marca de formando
+// (definida pela virada de ano letivo) não podem ser alterados por aqui.
+// expectedVersion 0 dispensa a verificação de versão.
+func (s *StudentService) PatchStudent(ctx context.Context, id string, patch []byte, expectedVersion int) (*models.Student, error) {
+	ctx, span := tracing.Start(ctx, "StudentService.PatchStudent")
+	defer span.End()
+	existingStudent, err := s.studentRepo.GetStudentByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar aluno existente para atualização: %w", err)
+	}
+	if existingStudent == nil {
+		return nil, fmt.Errorf("%w: aluno com ID %s", ErrNotFound, id)
+	}
+
+	var merged models.Student
+	if err := applyPatch(existingStudent, patch, &merged, "id", "enrollment", "subjects", "graduating", "status", "program_id", "version"); err != nil {
+		return nil, err
+	}
+	merged.Shift = strings.ToUpper(merged.Shift)
+	if merged.Name == "" || merged.CurrentYear <= 0 {
+		return nil, fmt.Errorf("%w: nome e ano atual (maior que zero) do aluno são obrigatórios", ErrValidation)
+	}
+	if err := validateShift(merged.Shift); err != nil {
+		return nil, err
+	}
+
+	merged.Version = expectedVersion
+	if err := s.studentRepo.UpdateStudent(ctx, &merged); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: aluno com ID %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+	return &merged, nil
 }
 
-// DeleteStudent deleta um aluno pelo ID.
-func (s *StudentService) DeleteStudent(id string) error {
-	return s.studentRepo.DeleteStudent(id)
+// validateShift garante que o turno seja 'M', 'T' ou 'N' (já em maiúscula).
+func validateShift(shift string) error {
+	if shift != "M" && shift != "T" && shift != "N" {
+		return fmt.Errorf("%w: turno inválido: %s. Deve ser 'M' (Manhã), 'T' (Tarde) ou 'N' (Noite)", ErrValidation, shift)
+	}
+	return nil
 }
 
-// AddSubjectToStudent associa uma matéria a um aluno.
-func (s *StudentService) AddSubjectToStudent(studentID, subjectID string) error {
-	student, err := s.studentRepo.GetStudentByID(studentID)
-	if err != nil {
-		return fmt.Errorf("erro ao buscar aluno: %w", err)
+// DeleteStudent deleta um aluno pelo ID. expectedVersion 0 dispensa a verificação de versão.
+func (s *StudentService) DeleteStudent(ctx context.Context, id string, expectedVersion int) error {
+	ctx, span := tracing.Start(ctx, "StudentService.DeleteStudent")
+	defer span.End()
+	if err := s.studentRepo.DeleteStudent(ctx, id, expectedVersion); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { // Inexistente ou já excluído
+			return fmt.Errorf("%w: aluno com ID %s", ErrNotFound, id)
+		}
+		return err
 	}
-	if student == nil {
-		return fmt.Errorf("aluno com ID %s não encontrado", studentID)
+	return nil
+}
+
+// RestoreStudent restaura um aluno excluído logicamente.
+func (s *StudentService) RestoreStudent(ctx context.Context, id string) (*models.Student, error) {
+	ctx, span := tracing.Start(ctx, "StudentService.RestoreStudent")
+	defer span.End()
+	if err := s.studentRepo.RestoreStudent(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: nenhum aluno excluído com ID %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("erro ao restaurar aluno: %w", err)
 	}
+	return s.studentRepo.GetStudentByID(ctx, id)
+}
 
-	subject, err := s.subjectRepo.GetSubjectByID(subjectID)
+// AddSubjectToStudent associa uma matéria a um aluno. Só alunos ativos podem ser associados (senão ErrConflict);
+// a situação é conferida pelo repositório na mesma transação da associação.
+func (s *StudentService) AddSubjectToStudent(ctx context.Context, studentID, subjectID string) error {
+	ctx, span := tracing.Start(ctx, "StudentService.AddSubjectToStudent")
+	defer span.End()
+	subject, err := cachedSubject(ctx, s.subjectCache, s.subjectRepo, subjectID)
 	if err != nil {
 		return fmt.Errorf("erro ao buscar matéria: %w", err)
 	}
 	if subject == nil {
-		return fmt.Errorf("matéria com ID %s não encontrada", subjectID)
+		return fmt.Errorf("%w: matéria com ID %s", ErrNotFound, subjectID)
 	}
 
-	return s.studentRepo.AddSubjectToStudent(studentID, subjectID)
+	err = s.studentRepo.AddSubjectToStudent(ctx, studentID, subjectID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%w: aluno com ID %s", ErrNotFound, studentID)
+	case errors.Is(err, repositories.ErrStudentNotActive):
+		return fmt.Errorf("%w: %v e não pode ser associado a matérias", ErrConflict, err)
+	case errors.Is(err, repositories.ErrSubjectNotFound): // Excluída depois da leitura (ou do cache)
+		return fmt.Errorf("%w: matéria com ID %s", ErrNotFound, subjectID)
+	}
+	return err
 }
 
 // RemoveSubjectFromStudent desassocia uma matéria de um aluno.
-func (s *StudentService) RemoveSubjectFromStudent(studentID, subjectID string) error {
-	return s.studentRepo.RemoveSubjectFromStudent(studentID, subjectID)
+func (s *StudentService) RemoveSubjectFromStudent(ctx context.Context, studentID, subjectID string) error {
+	ctx, span := tracing.Start(ctx, "StudentService.RemoveSubjectFromStudent")
+	defer span.End()
+	if err := s.studentRepo.RemoveSubjectFromStudent(ctx, studentID, subjectID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: matéria %s não associada ao aluno %s", ErrNotFound, subjectID, studentID)
+		}
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,22 @@
+// api/services/student_service_test.go
+package services
+
+import (
+	"college_api/models"
+	"college_api/repositories"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestValidateNewStudentReportsEveryProblem(t *testing.T) {
+	// Sem matérias, nome e turno são conferidos sem acessar o banco
+	err := validateNewStudent(context.Background(), &repositories.TxRepositories{}, &models.Student{Name: "  ", Shift: "x"})
+	var invalid *NewStudentError
+	if !errors.As(err, &invalid) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("esperava *NewStudentError, veio %v", err)
+	}
+	if len(invalid.Problems) != 2 {
+		t.Errorf("esperava os problemas de nome e turno, veio %q", invalid.Problems)
+	}
+}
@@ -0,0 +1,113 @@
+// services/student_status.go
+package services
+
+import (
+	"college_api/models"
+	"college_api/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StudentStatusTransitions é a máquina de estados da situação do aluno: para cada situação,
+// as situações para as quais ele pode passar. Formados e transferidos não mudam mais.
+var StudentStatusTransitions = map[string][]string{
+	models.StudentActive:      {models.StudentSuspended, models.StudentLocked, models.StudentGraduated, models.StudentWithdrawn, models.StudentTransferred},
+	models.StudentSuspended:   {models.StudentActive, models.StudentWithdrawn},
+	models.StudentLocked:      {models.StudentActive, models.StudentWithdrawn, models.StudentTransferred},
+	models.StudentWithdrawn:   {models.StudentActive}, // Reingresso
+	models.StudentGraduated:   {},
+	models.StudentTransferred: {},
+}
+
+// maxStatusReasonLength limita o tamanho do motivo de uma mudança de situação.
+const maxStatusReasonLength = 500
+
+// ChangeStudentStatus muda a situação do aluno, registrando motivo e data de efetivação no histórico.
+// Transições fora de StudentStatusTransitions são recusadas com ErrConflict.
+// expectedVersion 0 dispensa a verificação de versão.
+func (s *StudentService) ChangeStudentStatus(ctx context.Context, id string, change models.StudentStatusChange, expectedVersion int) (*models.Student, *models.StudentStatusTransition, error) {
+	ctx, span := tracing.Start(ctx, "StudentService.ChangeStudentStatus")
+	defer span.End()
+	transition := &models.StudentStatusTransition{
+		ToStatus: strings.ToLower(strings.TrimSpace(change.Status)),
+		Reason:   strings.TrimSpace(change.Reason),
+	}
+	if err := validateStudentStatus(transition.ToStatus); err != nil {
+		return nil, nil, err
+	}
+	if transition.Reason == "" {
+		return nil, nil, fmt.Errorf("%w: o motivo da mudança de situação é obrigatório", ErrValidation)
+	}
+	if len(transition.Reason) > maxStatusReasonLength {
+		return nil, nil, fmt.Errorf("%w: o motivo pode ter no máximo %d caracteres", ErrValidation, maxStatusReasonLength)
+	}
+	effective, err := parseEffectiveDate(change.EffectiveDate)
+	if err != nil {
+		return nil, nil, err
+	}
+	transition.EffectiveDate = effective
+
+	student, err := s.studentRepo.ChangeStudentStatus(ctx, id, expectedVersion, func(from string) error {
+		if from == transition.ToStatus {
+			return fmt.Errorf("%w: o aluno já está com a situação %s", ErrConflict, from)
+		}
+		if !containsString(StudentStatusTransitions[from], transition.ToStatus) {
+			return fmt.Errorf("%w: transição de situação não permitida: %s → %s", ErrConflict, from, transition.ToStatus)
+		}
+		return nil
+	}, transition)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, fmt.Errorf("%w: aluno com ID %s", ErrNotFound, id)
+		}
+		return nil, nil, err
+	}
+	if student, err = s.studentRepo.GetStudentByID(ctx, id); err != nil { // Recarrega com as matérias
+		return nil, nil, err
+	}
+	return student, transition, nil
+}
+
+// GetStudentStatusHistory devolve o histórico de situações do aluno.
+func (s *StudentService) GetStudentStatusHistory(ctx context.Context, id string) ([]models.StudentStatusTransition, error) {
+	ctx, span := tracing.Start(ctx, "StudentService.GetStudentStatusHistory")
+	defer span.End()
+	student, err := s.studentRepo.GetStudentByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar aluno: %w", err)
+	}
+	if student == nil {
+		return nil, fmt.Errorf("%w: aluno com ID %s", ErrNotFound, id)
+	}
+	return s.studentRepo.GetStatusHistory(ctx, id)
+}
+
+// validateStudentStatus garante que status seja uma das situações conhecidas.
+func validateStudentStatus(status string) error {
+	if _, ok := StudentStatusTransitions[status]; !ok {
+		return fmt.Errorf("%w: situação inválida: %q. Use active, suspended, locked, graduated, withdrawn ou transferred", ErrValidation, status)
+	}
+	return nil
+}
+
+// parseEffectiveDate interpreta a data de efetivação (AAAA-MM-DD), que não pode estar no futuro:
+// a mudança de situação vale a partir do registro. Vazia, assume a data de hoje.
+func parseEffectiveDate(value string) (string, error) {
+	today := time.Now().Format("2006-01-02")
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return today, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return "", fmt.Errorf("%w: data de efetivação inválida: %q (use AAAA-MM-DD)", ErrValidation, value)
+	}
+	if formatted := date.Format("2006-01-02"); formatted > today {
+		return "", fmt.Errorf("%w: a data de efetivação não pode estar no futuro: %s", ErrValidation, formatted)
+	}
+	return date.Format("2006-01-02"), nil
+}
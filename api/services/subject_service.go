@@ -2,93 +2,246 @@
 package services
 
 import (
+	"college_api/cache"
 	"college_api/models"
 	"college_api/repositories"
+	"college_api/tracing"
+	"context"
 	"database/sql" // Para verificar sql.ErrNoRows
 	"errors"       // Para criar erros personalizados
 	"fmt"          // Para formatar mensagens de erro
+	"slices"
+	"strings"
 )
 
 // SubjectService define a interface para as operações de serviço de matérias.
 type SubjectService struct {
-	repo *repositories.SubjectRepository
+	repo  *repositories.SubjectRepository
+	cache cache.Cache // Cache de leitura das matérias (nil: desabilitado); limpo a cada escrita
 }
 
-// NewSubjectService cria uma nova instância de SubjectService.
-func NewSubjectService(repo *repositories.SubjectRepository) *SubjectService {
-	return &SubjectService{repo: repo}
+// NewSubjectService cria uma nova instância de SubjectService. c é o cache de leitura das matérias
+// (nil desabilita), que pode ser compartilhado com StudentService.
+func NewSubjectService(repo *repositories.SubjectRepository, c cache.Cache) *SubjectService {
+	return &SubjectService{repo: repo, cache: c}
+}
+
+// SubjectCacheName identifica o cache de matérias nas métricas.
+const SubjectCacheName = "subjects"
+
+// cachedSubject busca uma matéria pelo ID através do cache c (nil: direto no repositório).
+// Devolve nil se ela não existir e, se existir, uma cópia que o chamador pode alterar.
+func cachedSubject(ctx context.Context, c cache.Cache, repo *repositories.SubjectRepository, id string) (*models.Subject, error) {
+	subject, found, err := cache.ReadThrough(ctx, c, SubjectCacheName, "id:"+id, func(ctx context.Context) (models.Subject, bool, error) {
+		subject, err := repo.GetSubjectByID(ctx, id)
+		if err != nil || subject == nil {
+			return models.Subject{}, false, err
+		}
+		return *subject, true, nil
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return &subject, nil
 }
 
 // CreateSubject adiciona uma nova matéria após validações.
-func (s *SubjectService) CreateSubject(subject *models.Subject) error {
+func (s *SubjectService) CreateSubject(ctx context.Context, subject *models.Subject) error {
+	ctx, span := tracing.Start(ctx, "SubjectService.CreateSubject")
+	defer span.End()
 	// Exemplo de validação: ID, nome e ano são obrigatórios
-	if subject.ID == "" || subject.Name == "" || subject.Year == 0 {
+	if subject.ID == "" {
 		return errors.New("ID, nome e ano da matéria são obrigatórios")
 	}
+	if err := validateSubject(subject); err != nil {
+		return err
+	}
 
 	// Exemplo de validação: Matéria com o mesmo ID já existe
-	existingSubject, err := s.repo.GetSubjectByID(subject.ID)
+	existingSubject, err := s.repo.GetSubjectByID(ctx, subject.ID)
 	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("erro ao verificar matéria existente: %w", err)
 	}
 	if existingSubject != nil {
 		return errors.New("matéria com este ID já existe")
 	}
+	// O ID pode pertencer a uma matéria excluída logicamente, que deve ser restaurada em vez de recriada
+	inUse, err := s.repo.SubjectIDExists(ctx, subject.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar matéria existente: %w", err)
+	}
+	if inUse {
+		return fmt.Errorf("matéria com este ID foi excluída; restaure-a com POST /subjects/%s:restore", subject.ID)
+	}
 
-	return s.repo.CreateSubject(subject)
+	if err := s.repo.CreateSubject(ctx, subject); err != nil {
+		return err
+	}
+	cache.Invalidate(ctx, s.cache)
+	return nil
 }
 
 // GetSubjectByID busca uma matéria pelo ID.
-func (s *SubjectService) GetSubjectByID(id string) (*models.Subject, error) {
-	subject, err := s.repo.GetSubjectByID(id)
+func (s *SubjectService) GetSubjectByID(ctx context.Context, id string) (*models.Subject, error) {
+	ctx, span := tracing.Start(ctx, "SubjectService.GetSubjectByID")
+	defer span.End()
+	subject, err := cachedSubject(ctx, s.cache, s.repo, id)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar matéria: %w", err)
 	}
 	if subject == nil {
-		return nil, errors.New("matéria não encontrada")
+		return nil, fmt.Errorf("%w: matéria com ID %s", ErrNotFound, id)
 	}
 	return subject, nil
 }
 
-// GetAllSubjects busca todas as matérias.
-func (s *SubjectService) GetAllSubjects() ([]models.Subject, error) {
-	subjects, err := s.repo.GetAllSubjects()
+// GetAllSubjects busca todas as matérias que atendem ao filtro.
+func (s *SubjectService) GetAllSubjects(ctx context.Context, filter models.SubjectFilter) ([]models.Subject, error) {
+	ctx, span := tracing.Start(ctx, "SubjectService.GetAllSubjects")
+	defer span.End()
+	if err := normalizeSubjectFilter(&filter); err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("list:%s|%d", strings.ToLower(filter.Name), filter.Year)
+	subjects, _, err := cache.ReadThrough(ctx, s.cache, SubjectCacheName, key, func(ctx context.Context) ([]models.Subject, bool, error) {
+		subjects, err := s.repo.GetAllSubjects(ctx, filter)
+		return subjects, err == nil, err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar todas as matérias: %w", err)
 	}
-	return subjects, nil
+	return slices.Clone(subjects), nil
+}
+
+// StreamSubjects percorre as matérias que atendem ao filtro, chamando fn para cada uma.
+func (s *SubjectService) StreamSubjects(ctx context.Context, filter models.SubjectFilter, fn func(models.Subject) error) error {
+	ctx, span := tracing.Start(ctx, "SubjectService.StreamSubjects")
+	defer span.End()
+	if err := normalizeSubjectFilter(&filter); err != nil {
+		return err
+	}
+	return s.repo.StreamSubjects(ctx, filter, fn)
+}
+
+// normalizeSubjectFilter valida o filtro de matérias.
+func normalizeSubjectFilter(filter *models.SubjectFilter) error {
+	filter.Name = strings.TrimSpace(filter.Name)
+	if filter.Year < 0 {
+		return fmt.Errorf("%w: ano inválido: %d", ErrValidation, filter.Year)
+	}
+	return nil
 }
 
 // UpdateSubject atualiza uma matéria existente após validações.
-func (s *SubjectService) UpdateSubject(subject *models.Subject) error {
+// subject.Version é a versão que o cliente leu (0 dispensa a verificação).
+func (s *SubjectService) UpdateSubject(ctx context.Context, subject *models.Subject) error {
+	ctx, span := tracing.Start(ctx, "SubjectService.UpdateSubject")
+	defer span.End()
 	if subject.ID == "" {
-		return errors.New("ID da matéria é obrigatório para atualização")
+		return fmt.Errorf("%w: ID da matéria é obrigatório para atualização", ErrValidation)
+	}
+	// PUT substitui a matéria inteira: campos ausentes não podem zerar ano e créditos
+	if err := validateSubject(subject); err != nil {
+		return err
 	}
 	// Validação: a matéria deve existir para ser atualizada
-	existingSubject, err := s.repo.GetSubjectByID(subject.ID)
+	existingSubject, err := s.repo.GetSubjectByID(ctx, subject.ID)
 	if err != nil {
 		return fmt.Errorf("erro ao verificar matéria para atualização: %w", err)
 	}
 	if existingSubject == nil {
-		return errors.New("matéria não encontrada para atualização")
+		return fmt.Errorf("%w: matéria com ID %s", ErrNotFound, subject.ID)
+	}
+
+	if err := s.repo.UpdateSubject(ctx, subject); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { // Excluída por outra requisição desde a verificação
+			return fmt.Errorf("%w: matéria com ID %s", ErrNotFound, subject.ID)
+		}
+		return err
+	}
+	cache.Invalidate(ctx, s.cache)
+	return nil
+}
+
+// RestoreSubject restaura uma matéria excluída logicamente.
+func (s *SubjectService) RestoreSubject(ctx context.Context, id string) (*models.Subject, error) {
+	ctx, span := tracing.Start(ctx, "SubjectService.RestoreSubject")
+	defer span.End()
+	if err := s.repo.RestoreSubject(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: nenhuma matéria excluída com ID %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("erro ao restaurar matéria: %w", err)
+	}
+	cache.Invalidate(ctx, s.cache)
+	return s.GetSubjectByID(ctx, id)
+}
+
+// PatchSubject aplica um JSON Merge Patch (RFC 7396) à matéria e devolve o estado gravado.
+// ID e versão não podem ser alterados. expectedVersion 0 dispensa a verificação de versão.
+func (s *SubjectService) PatchSubject(ctx context.Context, id string, patch []byte, expectedVersion int) (*models.Subject, error) {
+	ctx, span := tracing.Start(ctx, "SubjectService.PatchSubject")
+	defer span.End()
+	existingSubject, err := s.repo.GetSubjectByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar matéria para atualização: %w", err)
+	}
+	if existingSubject == nil {
+		return nil, fmt.Errorf("%w: matéria com ID %s", ErrNotFound, id)
 	}
 
-	return s.repo.UpdateSubject(subject)
+	var merged models.Subject
+	if err := applyPatch(existingSubject, patch, &merged, "id", "version"); err != nil {
+		return nil, err
+	}
+	if err := validateSubject(&merged); err != nil {
+		return nil, err
+	}
+
+	merged.Version = expectedVersion
+	if err := s.repo.UpdateSubject(ctx, &merged); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: matéria com ID %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+	cache.Invalidate(ctx, s.cache)
+	return &merged, nil
+}
+
+// validateSubject aplica as regras comuns de criação e atualização de matérias.
+func validateSubject(subject *models.Subject) error {
+	if subject.Name == "" || subject.Year <= 0 {
+		return fmt.Errorf("%w: nome e ano (maior que zero) da matéria são obrigatórios", ErrValidation)
+	}
+	if subject.Credits <= 0 {
+		return fmt.Errorf("%w: créditos da matéria devem ser maiores que zero", ErrValidation)
+	}
+	return nil
 }
 
-// DeleteSubject deleta uma matéria pelo ID.
-func (s *SubjectService) DeleteSubject(id string) error {
+// DeleteSubject deleta uma matéria pelo ID. expectedVersion 0 dispensa a verificação de versão.
+func (s *SubjectService) DeleteSubject(ctx context.Context, id string, expectedVersion int) error {
+	ctx, span := tracing.Start(ctx, "SubjectService.DeleteSubject")
+	defer span.End()
 	if id == "" {
 		return errors.New("ID da matéria é obrigatório para exclusão")
 	}
 	// Validação: a matéria deve existir para ser deletada
-	existingSubject, err := s.repo.GetSubjectByID(id)
+	existingSubject, err := s.repo.GetSubjectByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("erro ao verificar matéria para exclusão: %w", err)
 	}
-	if existingSubject == nil {
-		return errors.New("matéria não encontrada para exclusão")
+	if existingSubject == nil { // Inexistente ou já excluída
+		return fmt.Errorf("%w: matéria com ID %s", ErrNotFound, id)
 	}
 
-	return s.repo.DeleteSubject(id)
+	if err := s.repo.DeleteSubject(ctx, id, expectedVersion); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { // Excluída por outra requisição desde a verificação
+			return fmt.Errorf("%w: matéria com ID %s", ErrNotFound, id)
+		}
+		return err
+	}
+	cache.Invalidate(ctx, s.cache)
+	return nil
 }
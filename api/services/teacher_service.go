@@ -2,11 +2,16 @@
 package services
 
 import (
+	"college_api/cache"
+	"college_api/logging"
 	"college_api/models"
 	"college_api/repositories"
+	"college_api/tracing"
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
-	"log"     // Importar para log.Printf
+	"slices"
 	"strconv" // Importar para strconv.Atoi
 	"strings" // Importar para strings.ToUpper, strings.ReplaceAll
 
@@ -15,35 +20,42 @@ import (
 
 // TeacherService define a interface para as operações de serviço de professores.
 type TeacherService struct {
-	repo *repositories.TeacherRepository
+	repo           *repositories.TeacherRepository
+	departmentRepo *repositories.DepartmentRepository
+	cache          cache.Cache // Cache de leitura dos professores (nil: desabilitado); limpo a cada escrita
 }
 
-// NewTeacherService cria uma nova instância de TeacherService.
-func NewTeacherService(repo *repositories.TeacherRepository) *TeacherService {
-	return &TeacherService{repo: repo}
+// NewTeacherService cria uma nova instância de TeacherService. c é o cache de leitura dos professores
+// (nil desabilita), que também é limpo por DepartmentService quando um departamento muda de nome.
+func NewTeacherService(repo *repositories.TeacherRepository, dr *repositories.DepartmentRepository, c cache.Cache) *TeacherService {
+	return &TeacherService{repo: repo, departmentRepo: dr, cache: c}
 }
 
+// TeacherCacheName identifica o cache de professores nas métricas.
+const TeacherCacheName = "teachers"
+
 // CreateTeacher adiciona um novo professor com registro gerado automaticamente.
-func (s *TeacherService) CreateTeacher(teacher *models.Teacher) error {
+// O departamento é informado por department_id ou, alternativamente, pelo código ou nome em department.
+func (s *TeacherService) CreateTeacher(ctx context.Context, teacher *models.Teacher) error {
+	ctx, span := tracing.Start(ctx, "TeacherService.CreateTeacher")
+	defer span.End()
 	// 1. Validação de campos essenciais do frontend
-	if teacher.Name == "" || teacher.Department == "" {
-		return errors.New("nome e departamento do professor são obrigatórios")
+	if teacher.Name == "" {
+		return fmt.Errorf("%w: nome e departamento do professor são obrigatórios", ErrValidation)
+	}
+	department, err := s.resolveDepartment(ctx, teacher)
+	if err != nil {
+		return err
 	}
 
 	// --- NOVO: Gerar o ID único do professor (interno) ---
 	teacher.ID = uuid.New().String() // Gera o UUID aqui
 
-	// --- NOVO: Lógica para gerar o Registro do professor ---
-	// 2. Gerar o código do departamento padronizado
-	departmentCode := strings.ToUpper(strings.ReplaceAll(teacher.Department, " ", ""))
-	if len(departmentCode) > 4 { // Limita o código para algo razoável
-		departmentCode = departmentCode[:4]
-	} else if len(departmentCode) == 0 {
-		return errors.New("departamento não pode ser vazio para gerar registro")
-	}
+	// 2. O prefixo do registro é o código estável do departamento
+	departmentCode := department.Code
 
 	// 3. Buscar o último registro para este departamento
-	lastRegistry, err := s.repo.GetLastRegistryForDepartment(departmentCode)
+	lastRegistry, err := s.repo.GetLastRegistryForDepartment(ctx, departmentCode)
 	if err != nil {
 		return fmt.Errorf("erro ao buscar último registro para o departamento: %w", err)
 	}
@@ -60,7 +72,7 @@ func (s *TeacherService) CreateTeacher(teacher *models.Teacher) error {
 			if err == nil {
 				newSequence = lastSequence + 1
 			} else {
-				log.Printf("Aviso: Não foi possível converter sequência '%s' para int. Reiniciando sequência para 1. Erro: %v", seqStr, err)
+				logging.FromContext(ctx).Warn("CreateTeacher: sequência de registro inválida, reiniciando em 1", "sequence", seqStr, "error", err)
 			}
 		}
 	}
@@ -69,70 +81,239 @@ func (s *TeacherService) CreateTeacher(teacher *models.Teacher) error {
 	teacher.Registry = fmt.Sprintf("%s-%03d", departmentCode, newSequence)
 
 	// O repositório agora salvará o professor com o ID e Registro gerados
-	return s.repo.CreateTeacher(teacher)
+	if err := s.repo.CreateTeacher(ctx, teacher); err != nil {
+		return err
+	}
+	cache.Invalidate(ctx, s.cache)
+	return nil
+}
+
+// resolveDepartment encontra o departamento do professor: pelo department_id, se informado,
+// ou pelo código ou nome em department. Departamentos nunca são criados implicitamente:
+// um valor desconhecido é erro de validação. Em caso de sucesso, preenche DepartmentID e Department.
+func (s *TeacherService) resolveDepartment(ctx context.Context, teacher *models.Teacher) (*models.Department, error) {
+	teacher.DepartmentID = strings.TrimSpace(teacher.DepartmentID)
+	teacher.Department = strings.TrimSpace(teacher.Department)
+	var department *models.Department
+	var err error
+	switch {
+	case teacher.DepartmentID != "":
+		department, err = s.departmentRepo.GetDepartmentByID(ctx, teacher.DepartmentID)
+	case teacher.Department != "":
+		department, err = s.departmentRepo.FindDepartment(ctx, teacher.Department)
+	default:
+		return nil, fmt.Errorf("%w: nome e departamento do professor são obrigatórios", ErrValidation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar departamento: %w", err)
+	}
+	if department == nil {
+		ref := teacher.DepartmentID
+		if ref == "" {
+			ref = teacher.Department
+		}
+		return nil, fmt.Errorf("%w: departamento %q não encontrado (cadastre-o em /departments)", ErrValidation, ref)
+	}
+	teacher.DepartmentID = department.ID
+	teacher.Department = department.Name
+	return department, nil
+}
+
+// checkDepartmentChange impede que o chefe de um departamento seja transferido para outro.
+func (s *TeacherService) checkDepartmentChange(ctx context.Context, existing *models.Teacher, newDepartmentID string) error {
+	if existing.DepartmentID == "" || existing.DepartmentID == newDepartmentID {
+		return nil
+	}
+	current, err := s.departmentRepo.GetDepartmentByID(ctx, existing.DepartmentID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar departamento atual do professor: %w", err)
+	}
+	if current != nil && current.HeadTeacherID == existing.ID {
+		return fmt.Errorf("%w: o professor chefia o departamento %s; troque o chefe antes de transferi-lo", ErrConflict, current.Code)
+	}
+	return nil
 }
 
 // GetTeacherByID busca um professor pelo ID.
-func (s *TeacherService) GetTeacherByID(id string) (*models.Teacher, error) {
-	teacher, err := s.repo.GetTeacherByID(id)
+func (s *TeacherService) GetTeacherByID(ctx context.Context, id string) (*models.Teacher, error) {
+	ctx, span := tracing.Start(ctx, "TeacherService.GetTeacherByID")
+	defer span.End()
+	teacher, found, err := cache.ReadThrough(ctx, s.cache, TeacherCacheName, "id:"+id, func(ctx context.Context) (models.Teacher, bool, error) {
+		teacher, err := s.repo.GetTeacherByID(ctx, id)
+		if err != nil || teacher == nil {
+			return models.Teacher{}, false, err
+		}
+		return *teacher, true, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar professor: %w", err)
 	}
-	if teacher == nil {
-		return nil, errors.New("professor não encontrado")
+	if !found {
+		return nil, fmt.Errorf("%w: professor com ID %s", ErrNotFound, id)
 	}
-	return teacher, nil
+	return &teacher, nil
 }
 
-// GetAllTeachers busca todos os professores.
-func (s *TeacherService) GetAllTeachers() ([]models.Teacher, error) {
-	teachers, err := s.repo.GetAllTeachers()
+// GetAllTeachers busca todos os professores que atendem ao filtro.
+func (s *TeacherService) GetAllTeachers(ctx context.Context, filter models.TeacherFilter) ([]models.Teacher, error) {
+	ctx, span := tracing.Start(ctx, "TeacherService.GetAllTeachers")
+	defer span.End()
+	normalizeTeacherFilter(&filter)
+	key := fmt.Sprintf("list:%s|%s|%s", strings.ToLower(filter.Name), strings.ToLower(filter.Department), filter.DepartmentID)
+	teachers, _, err := cache.ReadThrough(ctx, s.cache, TeacherCacheName, key, func(ctx context.Context) ([]models.Teacher, bool, error) {
+		teachers, err := s.repo.GetAllTeachers(ctx, filter)
+		return teachers, err == nil, err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar todos os professores: %w", err)
 	}
-	return teachers, nil
+	return slices.Clone(teachers), nil
+}
+
+// StreamTeachers percorre os professores que atendem ao filtro, chamando fn para cada um.
+func (s *TeacherService) StreamTeachers(ctx context.Context, filter models.TeacherFilter, fn func(models.Teacher) error) error {
+	ctx, span := tracing.Start(ctx, "TeacherService.StreamTeachers")
+	defer span.End()
+	normalizeTeacherFilter(&filter)
+	return s.repo.StreamTeachers(ctx, filter, fn)
+}
+
+// normalizeTeacherFilter remove espaços nas pontas dos campos do filtro de professores.
+func normalizeTeacherFilter(filter *models.TeacherFilter) {
+	filter.Name = strings.TrimSpace(filter.Name)
+	filter.Department = strings.TrimSpace(filter.Department)
 }
 
 // UpdateTeacher atualiza um professor existente após validações.
-func (s *TeacherService) UpdateTeacher(teacher *models.Teacher) error {
+// teacher.Version é a versão que o cliente leu (0 dispensa a verificação); em caso de sucesso,
+// teacher recebe o estado gravado, incluindo a nova versão.
+func (s *TeacherService) UpdateTeacher(ctx context.Context, teacher *models.Teacher) error {
+	ctx, span := tracing.Start(ctx, "TeacherService.UpdateTeacher")
+	defer span.End()
 	if teacher.ID == "" {
-		return errors.New("ID do professor é obrigatório para atualização")
+		return fmt.Errorf("%w: ID do professor é obrigatório para atualização", ErrValidation)
 	}
-	if teacher.Name == "" || teacher.Department == "" {
-		return errors.New("nome e departamento do professor são obrigatórios para atualização")
+	if teacher.Name == "" {
+		return fmt.Errorf("%w: nome e departamento do professor são obrigatórios para atualização", ErrValidation)
+	}
+	if _, err := s.resolveDepartment(ctx, teacher); err != nil {
+		return err
 	}
 
-	existingTeacher, err := s.repo.GetTeacherByID(teacher.ID)
+	existingTeacher, err := s.repo.GetTeacherByID(ctx, teacher.ID)
 	if err != nil {
 		return fmt.Errorf("erro ao verificar professor para atualização: %w", err)
 	}
 	if existingTeacher == nil {
-		return errors.New("professor não encontrado para atualização")
+		return fmt.Errorf("%w: professor com ID %s", ErrNotFound, teacher.ID)
+	}
+
+	if err := s.checkDepartmentChange(ctx, existingTeacher, teacher.DepartmentID); err != nil {
+		return err
 	}
 
 	// Atualiza apenas os campos permitidos (nome e departamento)
 	existingTeacher.Name = teacher.Name
+	existingTeacher.DepartmentID = teacher.DepartmentID
 	existingTeacher.Department = teacher.Department
+	existingTeacher.Version = teacher.Version
 	// O registro (Registry) não é atualizado por aqui, pois é gerado na criação.
 	// Se Registry precisar ser atualizado, seria um método de negócio específico.
 	// Garanta que o Registry original seja mantido (não substituído por vazio)
 	teacher.Registry = existingTeacher.Registry // Atribui o registro existente ao professor no DTO de entrada para que o repositório não o apague
 
-	return s.repo.UpdateTeacher(existingTeacher)
+	if err := s.repo.UpdateTeacher(ctx, existingTeacher); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { // Excluído por outra requisição desde a verificação
+			return fmt.Errorf("%w: professor com ID %s", ErrNotFound, teacher.ID)
+		}
+		return err
+	}
+	cache.Invalidate(ctx, s.cache)
+	*teacher = *existingTeacher
+	return nil
 }
 
-// DeleteTeacher deleta um professor pelo ID.
-func (s *TeacherService) DeleteTeacher(id string) error {
+// RestoreTeacher restaura um professor excluído logicamente.
+func (s *TeacherService) RestoreTeacher(ctx context.Context, id string) (*models.Teacher, error) {
+	ctx, span := tracing.Start(ctx, "TeacherService.RestoreTeacher")
+	defer span.End()
+	if err := s.repo.RestoreTeacher(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: nenhum professor excluído com ID %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("erro ao restaurar professor: %w", err)
+	}
+	cache.Invalidate(ctx, s.cache)
+	return s.GetTeacherByID(ctx, id)
+}
+
+// PatchTeacher aplica um JSON Merge Patch (RFC 7396) ao professor e devolve o estado gravado.
+// ID, registro e versão não podem ser alterados. expectedVersion 0 dispensa a verificação de versão.
+func (s *TeacherService) PatchTeacher(ctx context.Context, id string, patch []byte, expectedVersion int) (*models.Teacher, error) {
+	ctx, span := tracing.Start(ctx, "TeacherService.PatchTeacher")
+	defer span.End()
+	existingTeacher, err := s.repo.GetTeacherByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar professor para atualização: %w", err)
+	}
+	if existingTeacher == nil {
+		return nil, fmt.Errorf("%w: professor com ID %s", ErrNotFound, id)
+	}
+
+	var merged models.Teacher
+	if err := applyPatch(existingTeacher, patch, &merged, "id", "registry", "version"); err != nil {
+		return nil, err
+	}
+	if merged.Name == "" {
+		return nil, fmt.Errorf("%w: nome e departamento do professor são obrigatórios", ErrValidation)
+	}
+	// Um patch que só troca department (código ou nome) não deve ser ignorado em favor do department_id atual
+	if merged.Department != existingTeacher.Department && merged.DepartmentID == existingTeacher.DepartmentID {
+		merged.DepartmentID = ""
+	}
+	if _, err := s.resolveDepartment(ctx, &merged); err != nil {
+		return nil, err
+	}
+	if err := s.checkDepartmentChange(ctx, existingTeacher, merged.DepartmentID); err != nil {
+		return nil, err
+	}
+
+	merged.Version = expectedVersion
+	if err := s.repo.UpdateTeacher(ctx, &merged); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: professor com ID %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+	cache.Invalidate(ctx, s.cache)
+	return &merged, nil
+}
+
+// DeleteTeacher deleta um professor pelo ID. expectedVersion 0 dispensa a verificação de versão.
+// O chefe de um departamento não pode ser excluído (ErrConflict): troque o chefe antes.
+func (s *TeacherService) DeleteTeacher(ctx context.Context, id string, expectedVersion int) error {
+	ctx, span := tracing.Start(ctx, "TeacherService.DeleteTeacher")
+	defer span.End()
 	if id == "" {
 		return errors.New("ID do professor é obrigatório para exclusão")
 	}
-	existingTeacher, err := s.repo.GetTeacherByID(id)
+	existingTeacher, err := s.repo.GetTeacherByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("erro ao verificar professor para exclusão: %w", err)
 	}
-	if existingTeacher == nil {
-		return errors.New("professor não encontrado para exclusão")
+	if existingTeacher == nil { // Inexistente ou já excluído
+		return fmt.Errorf("%w: professor com ID %s", ErrNotFound, id)
 	}
 
-	return s.repo.DeleteTeacher(id)
+	if err := s.repo.DeleteTeacher(ctx, id, expectedVersion); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { // Excluído por outra requisição desde a verificação
+			return fmt.Errorf("%w: professor com ID %s", ErrNotFound, id)
+		}
+		if errors.Is(err, repositories.ErrDepartmentHead) {
+			return fmt.Errorf("%w: %v; troque o chefe antes de excluí-lo", ErrConflict, err)
+		}
+		return err
+	}
+	cache.Invalidate(ctx, s.cache)
+	return nil
 }
@@ -0,0 +1,105 @@
+// api/tracing/tracing.go
+// Pacote tracing configura o OpenTelemetry: spans por requisição HTTP, por método de serviço e por
+// consulta SQL, propagação W3C (traceparent/tracestate) e o exportador escolhido por variável de ambiente.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exportadores aceitos em OTEL_TRACES_EXPORTER.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// DefaultServiceName é o nome do serviço nos traces quando OTEL_SERVICE_NAME não é informado.
+const DefaultServiceName = "college-api"
+
+// instrumentationName identifica os spans criados pela aplicação.
+const instrumentationName = "college_api"
+
+// Setup configura o provedor de traces global conforme OTEL_TRACES_EXPORTER:
+// "none" (padrão: spans não são gravados, mas o contexto W3C continua sendo propagado),
+// "otlp" (OTLP/HTTP; endpoint, cabeçalhos e demais opções vêm das variáveis OTEL_EXPORTER_OTLP_*)
+// ou "stdout" (spans em JSON na saída padrão, para uso local). A amostragem segue OTEL_TRACES_SAMPLER.
+// Devolve a função que descarrega e encerra o exportador.
+func Setup(ctx context.Context) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch name := strings.ToLower(strings.TrimSpace(os.Getenv("OTEL_TRACES_EXPORTER"))); name {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("OTEL_TRACES_EXPORTER inválido: %q (use none, otlp ou stdout)", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar exportador de traces: %w", err)
+	}
+
+	// OTEL_SERVICE_NAME e OTEL_RESOURCE_ATTRIBUTES, se definidos, têm precedência sobre o nome padrão
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(DefaultServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao montar recurso dos traces: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start inicia um span interno filho do span do contexto (ex: "StudentService.CreateStudent").
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartServer inicia o span de servidor de uma requisição HTTP recebida.
+func StartServer(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...))
+}
+
+// StartQuery inicia o span de uma operação de banco. O nome é o do repositório e da operação
+// (ex: "students.GetStudentByID"); os valores dos parâmetros nunca são gravados.
+func StartQuery(ctx context.Context, repository, operation string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, repository+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName(repository),
+		))
+}
+
+// RecordError marca o span como falho com err, se houver erro.
+func RecordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
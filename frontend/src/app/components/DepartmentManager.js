@@ -0,0 +1,195 @@
+// frontend/src/app/components/DepartmentManager.js
+'use client';
+
+import { useState } from 'react';
+
+// Importa o serviço de departamento
+import { departmentService } from '../services/apiService';
+
+export default function DepartmentManager({ styles, departments, teachers, fetchDepartments, fetchTeachers }) {
+    // Estados para o formulário de cadastro de Departamentos
+    const [departmentCode, setDepartmentCode] = useState('');
+    const [departmentName, setDepartmentName] = useState('');
+    const [departmentMessage, setDepartmentMessage] = useState('');
+    const [departmentMessageType, setDepartmentMessageType] = useState('');
+
+    // Estados para a edição (o código é imutável: só nome e chefe mudam)
+    const [editingDepartment, setEditingDepartment] = useState(null);
+    const [editDepartmentName, setEditDepartmentName] = useState('');
+    const [editHeadTeacherId, setEditHeadTeacherId] = useState('');
+
+    async function handleDepartmentSubmit(e) {
+        e.preventDefault();
+        setDepartmentMessage('');
+        setDepartmentMessageType('');
+
+        try {
+            const newDepartment = await departmentService.create({
+                code: departmentCode,
+                name: departmentName,
+            });
+
+            setDepartmentMessage(`Departamento "${newDepartment.name}" (${newDepartment.code}) cadastrado com sucesso!`);
+            setDepartmentMessageType('success');
+
+            setDepartmentCode('');
+            setDepartmentName('');
+            fetchDepartments(); // Recarrega a lista (e as opções do cadastro de professores)
+        } catch (error) {
+            console.error('Erro ao cadastrar departamento:', error);
+            setDepartmentMessage(`Erro ao cadastrar departamento: ${error.message}`);
+            setDepartmentMessageType('error');
+        }
+    }
+
+    async function handleDeleteDepartment(department) {
+        if (window.confirm(`Tem certeza que deseja deletar o departamento ${department.name}?`)) {
+            try {
+                // A API recusa (409) departamentos que ainda têm professores ativos
+                await departmentService.delete(department.id, department.version);
+
+                setDepartmentMessage(`Departamento "${department.name}" deletado com sucesso!`);
+                setDepartmentMessageType('success');
+                fetchDepartments();
+            } catch (error) {
+                console.error('Erro ao deletar departamento:', error);
+                setDepartmentMessage(`Erro ao deletar departamento: ${error.message}`);
+                setDepartmentMessageType('error');
+            }
+        }
+    }
+
+    // Inicia a edição
+    function handleUpdateDepartment(department) {
+        setEditingDepartment(department);
+        setEditDepartmentName(department.name);
+        setEditHeadTeacherId(department.head_teacher_id || '');
+        setDepartmentMessage('');
+    }
+
+    // Cancela a edição
+    function handleCancelEditDepartment() {
+        setEditingDepartment(null);
+        setEditDepartmentName('');
+        setEditHeadTeacherId('');
+        setDepartmentMessage('');
+    }
+
+    // Envia a atualização
+    async function handleUpdateDepartmentSubmit(e) {
+        e.preventDefault();
+        setDepartmentMessage('');
+        setDepartmentMessageType('');
+
+        if (!editingDepartment) return;
+
+        try {
+            const updatedDepartment = await departmentService.update(editingDepartment.id, {
+                name: editDepartmentName,
+                head_teacher_id: editHeadTeacherId,
+            }, editingDepartment.version);
+
+            setDepartmentMessage(`Departamento "${updatedDepartment.name}" atualizado com sucesso!`);
+            setDepartmentMessageType('success');
+            setEditingDepartment(null);
+            fetchDepartments();
+            fetchTeachers(); // O nome do departamento aparece na lista de professores
+        } catch (error) {
+            console.error('Erro ao atualizar departamento:', error);
+            setDepartmentMessage(`Erro ao atualizar departamento: ${error.message}`);
+            setDepartmentMessageType('error');
+        }
+    }
+
+    // Só professores do próprio departamento podem chefiá-lo
+    const headCandidates = editingDepartment && Array.isArray(teachers)
+        ? teachers.filter((teacher) => teacher.department_id === editingDepartment.id)
+        : [];
+
+    function teacherName(id) {
+        const teacher = Array.isArray(teachers) ? teachers.find((t) => t.id === id) : null;
+        return teacher ? teacher.name : id;
+    }
+
+    const message = departmentMessage && (
+        <div style={{ ...styles.message, color: departmentMessageType === 'error' ? '#c0392b' : '#27ae60', backgroundColor: departmentMessageType === 'error' ? '#fde0dc' : '#d4edda', border: `1px solid ${departmentMessageType === 'error' ? '#e74c3c' : '#28a745'}` }}>
+            {departmentMessage}
+        </div>
+    );
+
+    return (
+        <>
+            <h2 style={styles.h2}>Cadastrar Novo Departamento</h2>
+            <form onSubmit={handleDepartmentSubmit} style={styles.form}>
+                <input
+                    type="text"
+                    placeholder="Código (ex: COMP)"
+                    value={departmentCode}
+                    onChange={(e) => setDepartmentCode(e.target.value)}
+                    required
+                    style={styles.input}
+                />
+                <input
+                    type="text"
+                    placeholder="Nome (ex: Ciência da Computação)"
+                    value={departmentName}
+                    onChange={(e) => setDepartmentName(e.target.value)}
+                    required
+                    style={styles.input}
+                />
+                <button type="submit" style={styles.button}>Cadastrar Departamento</button>
+                {!editingDepartment && message}
+            </form>
+
+            {editingDepartment && (
+                <div style={styles.editFormContainer}>
+                    <h2 style={styles.h2}>Editar Departamento: {editingDepartment.name} ({editingDepartment.code})</h2>
+                    <form onSubmit={handleUpdateDepartmentSubmit} style={styles.form}>
+                        <input
+                            type="text"
+                            placeholder="Nome do Departamento"
+                            value={editDepartmentName}
+                            onChange={(e) => setEditDepartmentName(e.target.value)}
+                            required
+                            style={styles.input}
+                        />
+                        <select
+                            value={editHeadTeacherId}
+                            onChange={(e) => setEditHeadTeacherId(e.target.value)}
+                            style={styles.input}
+                        >
+                            <option value="">Sem chefe</option>
+                            {headCandidates.map((teacher) => (
+                                <option key={teacher.id} value={teacher.id}>{teacher.name} ({teacher.registry})</option>
+                            ))}
+                        </select>
+                        <div style={styles.formButtons}>
+                            <button type="submit" style={{...styles.button, ...styles.updateButton}}>Salvar Alterações</button>
+                            <button type="button" onClick={handleCancelEditDepartment} style={{...styles.button, ...styles.cancelButton}}>Cancelar</button>
+                        </div>
+                        {message}
+                    </form>
+                </div>
+            )}
+
+            <h2 style={styles.h2}>Lista de Departamentos</h2>
+            <ul style={styles.ul}>
+                {Array.isArray(departments) && departments.length > 0 ? (
+                    departments.map((department) => (
+                        <li key={department.id} style={styles.li}>
+                            <strong>Código:</strong> {department.code}<br />
+                            <strong>Nome:</strong> {department.name}<br />
+                            <strong>Chefe:</strong> {department.head_teacher_id ? teacherName(department.head_teacher_id) : 'Nenhum'}
+                            <div style={styles.cardButtons}>
+                                <button onClick={() => handleUpdateDepartment(department)} style={{...styles.button, ...styles.updateButton}}>Atualizar</button>
+                                <button onClick={() => handleDeleteDepartment(department)} style={{...styles.button, ...styles.deleteButton}}>Deletar</button>
+                            </div>
+                        </li>
+                    ))
+                ) : (
+                    <li style={styles.li}>Nenhum departamento cadastrado ainda. Cadastre um antes dos professores.</li>
+                )}
+            </ul>
+        </>
+    );
+}
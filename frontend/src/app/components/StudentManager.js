@@ -65,11 +65,11 @@ export default function StudentManager({ students, subjects, styles, fetchStuden
         }
     }
 
-    async function handleDeleteStudent(id, name) {
+    async function handleDeleteStudent(id, name, version) {
         if (window.confirm(`Tem certeza que deseja deletar o aluno ${name}?`)) {
             try {
                 // Usa o studentService para deletar o aluno
-                await studentService.delete(id);
+                await studentService.delete(id, version);
 
                 setStudentMessage(`Aluno "${name}" deletado com sucesso!`);
                 setStudentMessageType('success');
@@ -116,7 +116,7 @@ export default function StudentManager({ students, subjects, styles, fetchStuden
                 current_year: parseInt(editCurrentYear, 10),
                 shift: editStudentShift,
                 subjects: editingStudent.subjects?.map(sub => ({id: sub.id})) || [],
-            });
+            }, editingStudent.version);
 
             console.log('Aluno atualizado:', updatedStudent);
             setStudentMessage(`Aluno "${updatedStudent.name}" atualizado com sucesso!`);
@@ -233,7 +233,7 @@ export default function StudentManager({ students, subjects, styles, fetchStuden
                             }
                             <div style={styles.cardButtons}>
                                 <button onClick={() => handleUpdateStudent(student)} style={{...styles.button, ...styles.updateButton}}>Atualizar</button>
-                                <button onClick={() => handleDeleteStudent(student.id, student.name)} style={{...styles.button, ...styles.deleteButton}}>Deletar</button>
+                                <button onClick={() => handleDeleteStudent(student.id, student.name, student.version)} style={{...styles.button, ...styles.deleteButton}}>Deletar</button>
                             </div>
                         </li>
                     ))
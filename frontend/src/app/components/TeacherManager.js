@@ -6,17 +6,17 @@ import { useState } from 'react'; // Removido useEffect pois o fetch inicial ser
 // Importa o serviço de professor
 import { teacherService } from '../services/apiService';
 
-export default function TeacherManager({ styles, teachers, fetchTeachers }) {
+export default function TeacherManager({ styles, teachers, departments, fetchTeachers }) {
     // Estados para o formulário de cadastro de Professores
     const [teacherName, setTeacherName] = useState('');
-    const [teacherDepartment, setTeacherDepartment] = useState('');
+    const [teacherDepartmentId, setTeacherDepartmentId] = useState('');
     const [teacherMessage, setTeacherMessage] = useState('');
     const [teacherMessageType, setTeacherMessageType] = useState('');
 
     // Estados para a funcionalidade de edição de Professores
     const [editingTeacher, setEditingTeacher] = useState(null);
     const [editTeacherName, setEditTeacherName] = useState('');
-    const [editTeacherDepartment, setEditTeacherDepartment] = useState('');
+    const [editTeacherDepartmentId, setEditTeacherDepartmentId] = useState('');
 
     // As funções fetchTeachers são passadas como prop do page.js,
     // então não precisamos de useEffect aqui para buscar a lista inicial.
@@ -30,7 +30,7 @@ export default function TeacherManager({ styles, teachers, fetchTeachers }) {
             // Usa o teacherService para criar o professor
             const newTeacher = await teacherService.create({
                 name: teacherName,
-                department: teacherDepartment,
+                department_id: teacherDepartmentId, // Departamento existente (a API não cria departamentos)
             });
 
             console.log('Professor criado:', newTeacher);
@@ -38,7 +38,7 @@ export default function TeacherManager({ styles, teachers, fetchTeachers }) {
             setTeacherMessageType('success');
 
             setTeacherName('');
-            setTeacherDepartment('');
+            setTeacherDepartmentId('');
             fetchTeachers(); // Chama a função passada como prop para recarregar a lista globalmente
         } catch (error) {
             console.error('Erro ao cadastrar professor:', error);
@@ -47,11 +47,11 @@ export default function TeacherManager({ styles, teachers, fetchTeachers }) {
         }
     }
 
-    async function handleDeleteTeacher(id, name) {
+    async function handleDeleteTeacher(id, name, version) {
         if (window.confirm(`Tem certeza que deseja deletar o professor ${name}?`)) {
             try {
                 // Usa o teacherService para deletar o professor
-                await teacherService.delete(id);
+                await teacherService.delete(id, version);
 
                 setTeacherMessage(`Professor "${name}" deletado com sucesso!`);
                 setTeacherMessageType('success');
@@ -68,7 +68,7 @@ export default function TeacherManager({ styles, teachers, fetchTeachers }) {
     function handleUpdateTeacher(teacher) {
         setEditingTeacher(teacher);
         setEditTeacherName(teacher.name);
-        setEditTeacherDepartment(teacher.department);
+        setEditTeacherDepartmentId(teacher.department_id);
         setTeacherMessage('');
     }
 
@@ -76,7 +76,7 @@ export default function TeacherManager({ styles, teachers, fetchTeachers }) {
     function handleCancelEditTeacher() {
         setEditingTeacher(null);
         setEditTeacherName('');
-        setEditTeacherDepartment('');
+        setEditTeacherDepartmentId('');
         setTeacherMessage('');
     }
 
@@ -93,8 +93,8 @@ export default function TeacherManager({ styles, teachers, fetchTeachers }) {
             const updatedTeacher = await teacherService.update(editingTeacher.id, {
                 registry: editingTeacher.registry, // Manter o registro original
                 name: editTeacherName,
-                department: editTeacherDepartment,
-            });
+                department_id: editTeacherDepartmentId,
+            }, editingTeacher.version);
 
             console.log('Professor atualizado:', updatedTeacher);
             setTeacherMessage(`Professor "${updatedTeacher.name}" atualizado com sucesso!`);
@@ -108,6 +108,11 @@ export default function TeacherManager({ styles, teachers, fetchTeachers }) {
         }
     }
 
+    // Departamentos cadastrados (seção de departamentos), usados nos formulários de cadastro e edição
+    const departmentOptions = Array.isArray(departments) ? departments.map((department) => (
+        <option key={department.id} value={department.id}>{department.name} ({department.code})</option>
+    )) : null;
+
     return (
         <>
             <h2 style={styles.h2}>Cadastrar Novo Professor</h2>
@@ -120,14 +125,15 @@ export default function TeacherManager({ styles, teachers, fetchTeachers }) {
                     required
                     style={styles.input}
                 />
-                <input
-                    type="text"
-                    placeholder="Departamento (ex: Computação)"
-                    value={teacherDepartment}
-                    onChange={(e) => setTeacherDepartment(e.target.value)}
+                <select
+                    value={teacherDepartmentId}
+                    onChange={(e) => setTeacherDepartmentId(e.target.value)}
                     required
                     style={styles.input}
-                />
+                >
+                    <option value="">Selecione o departamento</option>
+                    {departmentOptions}
+                </select>
                 <button type="submit" style={styles.button}>Cadastrar Professor</button>
                 {teacherMessage && (
                     <div style={{ ...styles.message, color: teacherMessageType === 'error' ? '#c0392b' : '#27ae60', backgroundColor: teacherMessageType === 'error' ? '#fde0dc' : '#d4edda', border: `1px solid ${teacherMessageType === 'error' ? '#e74c3c' : '#28a745'}` }}>
@@ -148,14 +154,15 @@ export default function TeacherManager({ styles, teachers, fetchTeachers }) {
                             required
                             style={styles.input}
                         />
-                        <input
-                            type="text"
-                            placeholder="Departamento"
-                            value={editTeacherDepartment}
-                            onChange={(e) => setEditTeacherDepartment(e.target.value)}
+                        <select
+                            value={editTeacherDepartmentId}
+                            onChange={(e) => setEditTeacherDepartmentId(e.target.value)}
                             required
                             style={styles.input}
-                        />
+                        >
+                            <option value="">Selecione o departamento</option>
+                            {departmentOptions}
+                        </select>
                         <div style={styles.formButtons}>
                             <button type="submit" style={{...styles.button, ...styles.updateButton}}>Salvar Alterações</button>
                             <button type="button" onClick={handleCancelEditTeacher} style={{...styles.button, ...styles.cancelButton}}>Cancelar</button>
@@ -180,7 +187,7 @@ export default function TeacherManager({ styles, teachers, fetchTeachers }) {
                             <strong>Departamento:</strong> {teacher.department}
                             <div style={styles.cardButtons}>
                                 <button onClick={() => handleUpdateTeacher(teacher)} style={{...styles.button, ...styles.updateButton}}>Atualizar</button>
-                                <button onClick={() => handleDeleteTeacher(teacher.id, teacher.name)} style={{...styles.button, ...styles.deleteButton}}>Deletar</button>
+                                <button onClick={() => handleDeleteTeacher(teacher.id, teacher.name, teacher.version)} style={{...styles.button, ...styles.deleteButton}}>Deletar</button>
                             </div>
                         </li>
                     ))
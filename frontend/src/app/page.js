@@ -5,9 +5,10 @@ import { useState, useEffect } from 'react';
 // Importa os componentes filhos
 import StudentManager from './components/StudentManager';
 import TeacherManager from './components/TeacherManager';
+import DepartmentManager from './components/DepartmentManager';
 import AssociateSubjectForm from './components/AssociateSubjectForm';
 // Importa os novos serviços de API
-import { studentService, teacherService, subjectService } from './services/apiService';
+import { studentService, teacherService, subjectService, departmentService } from './services/apiService';
 
 
 // A API_BASE_URL agora é gerenciada dentro de apiService.js
@@ -17,6 +18,7 @@ export default function Home() {
     const [students, setStudents] = useState([]);
     const [teachers, setTeachers] = useState([]);
     const [subjects, setSubjects] = useState([]);
+    const [departments, setDepartments] = useState([]);
 
     // Funções de busca globais que usam os novos serviços de API
     async function fetchStudentsGlobal() { // Renomeado para evitar conflito com StudentManager.js
@@ -49,11 +51,22 @@ export default function Home() {
         }
     }
 
+    async function fetchDepartmentsGlobal() {
+        try {
+            const data = await departmentService.getAll();
+            setDepartments(Array.isArray(data) ? data : []);
+        } catch (error) {
+            console.error('Erro global ao buscar departamentos:', error);
+            setDepartments([]);
+        }
+    }
+
     // Efeito para buscar todos os dados iniciais quando o componente Home é montado
     useEffect(() => {
         fetchStudentsGlobal();
         fetchTeachersGlobal();
         fetchSubjectsGlobal();
+        fetchDepartmentsGlobal();
     }, []); // Array de dependências vazio para rodar apenas uma vez na montagem
 
     return (
@@ -71,9 +84,20 @@ export default function Home() {
                 />
                 <hr style={styles.hr} />
 
+                {/* Departamentos vêm antes dos professores: todo professor é cadastrado em um departamento existente */}
+                <DepartmentManager
+                    departments={departments}
+                    teachers={teachers}
+                    styles={styles}
+                    fetchDepartments={fetchDepartmentsGlobal}
+                    fetchTeachers={fetchTeachersGlobal}
+                />
+                <hr style={styles.hr} />
+
                 {/* Renderiza o componente TeacherManager, passando dados e funções */}
                 <TeacherManager
                     teachers={teachers}
+                    departments={departments}
                     styles={styles}
                     fetchTeachers={fetchTeachersGlobal} // Passa a função global de recarregamento
                     teacherService={teacherService} // Passa o serviço de professor
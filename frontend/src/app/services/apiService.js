@@ -10,6 +10,12 @@ async function handleResponse(response) {
     return response.json();
 }
 
+// A API usa a versão do registro como ETag e exige If-Match em PUT/DELETE
+// para não sobrescrever alterações feitas por outra pessoa (responde 412 nesse caso).
+function ifMatch(version) {
+    return { 'If-Match': `"${version}"` };
+}
+
 // --- Funções de Serviço para Alunos ---
 export const studentService = {
     getAll: async () => {
@@ -24,17 +30,18 @@ export const studentService = {
         });
         return handleResponse(response);
     },
-    update: async (id, studentData) => {
+    update: async (id, studentData, version) => {
         const response = await fetch(`${API_BASE_URL}/students/${id}`, {
             method: 'PUT',
-            headers: { 'Content-Type': 'application/json' },
+            headers: { 'Content-Type': 'application/json', ...ifMatch(version) },
             body: JSON.stringify(studentData),
         });
         return handleResponse(response);
     },
-    delete: async (id) => {
+    delete: async (id, version) => {
         const response = await fetch(`${API_BASE_URL}/students/${id}`, {
             method: 'DELETE',
+            headers: ifMatch(version),
         });
         if (!response.ok) { // DELETE 204 No Content não tem body
             throw new Error(`HTTP error! status: ${response.status}`);
@@ -73,17 +80,54 @@ export const teacherService = {
         });
         return handleResponse(response);
     },
-    update: async (id, teacherData) => {
+    update: async (id, teacherData, version) => {
         const response = await fetch(`${API_BASE_URL}/teachers/${id}`, {
             method: 'PUT',
-            headers: { 'Content-Type': 'application/json' },
+            headers: { 'Content-Type': 'application/json', ...ifMatch(version) },
             body: JSON.stringify(teacherData),
         });
         return handleResponse(response);
     },
-    delete: async (id) => {
+    delete: async (id, version) => {
         const response = await fetch(`${API_BASE_URL}/teachers/${id}`, {
             method: 'DELETE',
+            headers: ifMatch(version),
+        });
+        if (!response.ok) { // DELETE 204 No Content não tem body
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+        return null;
+    }
+};
+
+// --- Funções de Serviço para Departamentos ---
+// Professores são cadastrados em um departamento existente (department_id); a API não cria
+// departamentos implicitamente.
+export const departmentService = {
+    getAll: async () => {
+        const response = await fetch(`${API_BASE_URL}/departments`);
+        return handleResponse(response);
+    },
+    create: async (departmentData) => {
+        const response = await fetch(`${API_BASE_URL}/departments`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(departmentData),
+        });
+        return handleResponse(response);
+    },
+    update: async (id, departmentData, version) => {
+        const response = await fetch(`${API_BASE_URL}/departments/${id}`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json', ...ifMatch(version) },
+            body: JSON.stringify(departmentData),
+        });
+        return handleResponse(response);
+    },
+    delete: async (id, version) => {
+        const response = await fetch(`${API_BASE_URL}/departments/${id}`, {
+            method: 'DELETE',
+            headers: ifMatch(version),
         });
         if (!response.ok) { // DELETE 204 No Content não tem body
             throw new Error(`HTTP error! status: ${response.status}`);